@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"go-api/db"
+	"go-api/mailer"
 	"go-api/repository"
 	"go-api/usecase"
 	"go-api/utils"
@@ -33,7 +34,9 @@ func main() {
 	defer dbConn.Close()
 
 	userRepo := repository.NewUserRepository(dbConn)
-	userUseCase := usecase.NewUserUseCase(userRepo)
+	userUseCase := usecase.NewUserUseCase(userRepo, repository.NewSessionRepository(dbConn),
+		repository.NewLoginLockoutRepository(dbConn), repository.NewTwoFactorRepository(dbConn),
+		repository.NewAcquisitionRepository(dbConn), mailer.NewLogMailer(), nil)
 
 	// Cria o scanner para input de dados
 	scanner := bufio.NewScanner(os.Stdin)
@@ -85,7 +88,7 @@ func main() {
 	}
 
 	// Cria um novo usuário com o useCase
-	_, err = userUseCase.Register(i.Name, i.Cpf, i.Phone, i.Email, hashedPassword, i.RoleId)
+	_, err = userUseCase.Register(i.Name, i.Cpf, i.Phone, i.Email, hashedPassword, i.RoleId, nil)
 	if err != nil {
 		log.Fatalf("Error registering user: %v", err)
 	}
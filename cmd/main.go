@@ -13,14 +13,22 @@ import (
 func init() {
 	initializers.LoadEnv()
 	initializers.InitDB()
+	initializers.InitMailer()
 }
 
 func main() {
 	defer initializers.DB.Close()
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(initializers.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.RequestIDMiddleware)
 	routes.Routes(r)
+	routes.StartNotificationScheduler()
+	routes.StartWebhookScheduler()
+	routes.StartOutboxDispatcher()
 
 	log.Fatal(r.Run(":" + initializers.Port))
 }
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AcquisitionController interface {
+	CreateSuggestion(c *gin.Context)
+	GetSuggestions(c *gin.Context)
+	GetLoggedUserSuggestions(c *gin.Context)
+	RejectSuggestion(c *gin.Context)
+	CreateOrder(c *gin.Context)
+	GetOrders(c *gin.Context)
+	GetOrderById(c *gin.Context)
+	CancelOrder(c *gin.Context)
+	ReceiveOrder(c *gin.Context)
+	GetBudget(c *gin.Context)
+}
+
+type acquisitionController struct {
+	useCase usecase.AcquisitionUseCase
+}
+
+func NewAcquisitionController(useCase usecase.AcquisitionUseCase) AcquisitionController {
+	return &acquisitionController{useCase: useCase}
+}
+
+// CreateSuggestion registra uma sugestão de compra feita pelo usuário logado.
+func (ac *acquisitionController) CreateSuggestion(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	var i struct {
+		Title      string `json:"title" binding:"required"`
+		AuthorName string `json:"author_name"`
+		Notes      string `json:"notes"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase suggestion input"})
+		return
+	}
+
+	suggestion, err := ac.useCase.CreateSuggestion(i.Title, i.AuthorName, i.Notes, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, suggestion)
+}
+
+func (ac *acquisitionController) GetSuggestions(c *gin.Context) {
+	status := c.Query("status")
+
+	suggestions, err := ac.useCase.GetSuggestions(model.PurchaseSuggestionStatus(status), nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (ac *acquisitionController) GetLoggedUserSuggestions(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	suggestions, err := ac.useCase.GetSuggestions("", &userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (ac *acquisitionController) RejectSuggestion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase suggestion Id"})
+		return
+	}
+
+	if err := ac.useCase.RejectSuggestion(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Purchase suggestion rejected"})
+}
+
+// CreateOrder cria um pedido de compra, opcionalmente a partir de uma sugestão de um usuário.
+func (ac *acquisitionController) CreateOrder(c *gin.Context) {
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var i struct {
+		BookId       int     `json:"book_id" binding:"required"`
+		Vendor       string  `json:"vendor" binding:"required"`
+		Quantity     int     `json:"quantity" binding:"required"`
+		UnitCost     float64 `json:"unit_cost"`
+		SuggestionId *int    `json:"suggestion_id"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order input"})
+		return
+	}
+
+	order, err := ac.useCase.CreateOrder(i.BookId, i.Vendor, i.Quantity, i.UnitCost, i.SuggestionId, adminId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set(middleware.AuditTargetIdKey, order.Id)
+	c.JSON(http.StatusCreated, order)
+}
+
+func (ac *acquisitionController) GetOrders(c *gin.Context) {
+	status := c.Query("status")
+
+	orders, err := ac.useCase.GetOrders(model.PurchaseOrderStatus(status))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+func (ac *acquisitionController) GetOrderById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order Id"})
+		return
+	}
+
+	order, err := ac.useCase.GetOrderById(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (ac *acquisitionController) CancelOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order Id"})
+		return
+	}
+
+	if err := ac.useCase.CancelOrder(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Purchase order cancelled"})
+}
+
+// ReceiveOrder registra a chegada de exemplares de um pedido, um código de exemplar para cada cópia recebida.
+func (ac *acquisitionController) ReceiveOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order Id"})
+		return
+	}
+
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var i struct {
+		Codes   []int  `json:"codes" binding:"required,min=1"`
+		Branch  string `json:"branch"`
+		Section string `json:"section"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order receiving input"})
+		return
+	}
+
+	bookStocks, err := ac.useCase.ReceiveOrder(id, i.Codes, i.Branch, i.Section, adminId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, bookStocks)
+}
+
+// GetBudget retorna os totais gastos com pedidos de compra por período ('month' ou 'year').
+func (ac *acquisitionController) GetBudget(c *gin.Context) {
+	period := c.DefaultQuery("period", "month")
+	from := c.Query("from")
+	to := c.Query("to")
+
+	budget, err := ac.useCase.GetBudget(period, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController interface {
+	GetEntries(c *gin.Context)
+	VerifyChain(c *gin.Context)
+}
+
+type auditController struct {
+	useCase usecase.AuditUseCase
+}
+
+func NewAuditController(useCase usecase.AuditUseCase) AuditController {
+	return &auditController{useCase: useCase}
+}
+
+// GetEntries lista a trilha de auditoria, filtrada por 'actor_id', 'action', 'target_type', 'target_id',
+// 'request_id' e pelo período 'from'/'to'.
+func (ac *auditController) GetEntries(c *gin.Context) {
+	filter := model.AuditFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetId:   c.Query("target_id"),
+		RequestId:  c.Query("request_id"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+	}
+	if c.Query("actor_id") != "" {
+		actorId, err := strconv.Atoi(c.Query("actor_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actor Id"})
+			return
+		}
+		filter.ActorId = actorId
+	}
+
+	entries, err := ac.useCase.GetEntries(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// VerifyChain confere a cadeia de hashes da trilha de auditoria.
+func (ac *auditController) VerifyChain(c *gin.Context) {
+	verification, err := ac.useCase.VerifyChain()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
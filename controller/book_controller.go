@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"go-api/middleware"
 	"go-api/model"
 	"go-api/usecase"
 	"net/http"
@@ -20,6 +21,10 @@ type BookController interface {
 	GetStock(c *gin.Context)
 	UpdateStockStatus(c *gin.Context)
 	RemoveStock(c *gin.Context)
+	GetStockHistory(c *gin.Context)
+	UpdateStockCondition(c *gin.Context)
+	UpdateStockLocation(c *gin.Context)
+	GetDamageReports(c *gin.Context)
 }
 
 type bookController struct {
@@ -50,6 +55,7 @@ func (bc *bookController) CreateBook(c *gin.Context) {
 		return
 	}
 
+	c.Set(middleware.AuditTargetIdKey, book.Id)
 	c.JSON(http.StatusCreated, book)
 }
 
@@ -139,7 +145,9 @@ func (bc *bookController) AddStock(c *gin.Context) {
 	}
 
 	var i struct {
-		Code int `json:"code" binding:"required"`
+		Code    int    `json:"code" binding:"required"`
+		Branch  string `json:"branch"`
+		Section string `json:"section"`
 	}
 
 	if err := c.ShouldBindJSON(&i); err != nil {
@@ -147,12 +155,19 @@ func (bc *bookController) AddStock(c *gin.Context) {
 		return
 	}
 
-	bookStock, err := bc.useCase.AddStock(i.Code, id)
+	actorId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user Id"})
+		return
+	}
+
+	bookStock, err := bc.useCase.AddStock(i.Code, id, i.Branch, i.Section, &actorId)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Set(middleware.AuditTargetIdKey, bookStock.Id)
 	c.JSON(http.StatusOK, gin.H{"message": "Book stock added", "book_stock_id": bookStock.Id})
 }
 
@@ -203,6 +218,7 @@ func (bc *bookController) UpdateStockStatus(c *gin.Context) {
 
 	var i struct {
 		Status model.BookStockStatus `json:"status" binding:"required"`
+		Reason string                `json:"reason"`
 	}
 
 	if err = c.ShouldBindJSON(&i); err != nil {
@@ -215,7 +231,15 @@ func (bc *bookController) UpdateStockStatus(c *gin.Context) {
 		return
 	}
 
-	err = bc.useCase.UpdateStockStatus(stockId, i.Status, &bookId)
+	var actorId int
+
+	actorId, err = strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user Id"})
+		return
+	}
+
+	err = bc.useCase.UpdateStockStatus(stockId, i.Status, &bookId, actorId, i.Reason)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -249,3 +273,93 @@ func (bc *bookController) RemoveStock(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Book stock removed"})
 }
+
+// GetStockHistory lista o histórico de mudanças de status de um exemplar.
+func (bc *bookController) GetStockHistory(c *gin.Context) {
+	stockId, err := strconv.Atoi(c.Param("stock-id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock Id"})
+		return
+	}
+
+	history, err := bc.useCase.GetStockHistory(stockId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// UpdateStockCondition atualiza o grau de conservação de um exemplar.
+func (bc *bookController) UpdateStockCondition(c *gin.Context) {
+	stockId, err := strconv.Atoi(c.Param("stock-id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock Id"})
+		return
+	}
+
+	var i struct {
+		Condition model.BookStockCondition `json:"condition" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock condition input"})
+		return
+	}
+
+	if !i.Condition.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock condition"})
+		return
+	}
+
+	if err := bc.useCase.UpdateStockCondition(stockId, i.Condition); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Book stock condition updated successfully"})
+}
+
+// UpdateStockLocation atualiza a filial e a seção onde um exemplar fica guardado.
+func (bc *bookController) UpdateStockLocation(c *gin.Context) {
+	stockId, err := strconv.Atoi(c.Param("stock-id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock Id"})
+		return
+	}
+
+	var i struct {
+		Branch  string `json:"branch" binding:"required"`
+		Section string `json:"section"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock location input"})
+		return
+	}
+
+	if err := bc.useCase.UpdateStockLocation(stockId, i.Branch, i.Section); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Book stock location updated successfully"})
+}
+
+// GetDamageReports lista os danos registrados para um exemplar.
+func (bc *bookController) GetDamageReports(c *gin.Context) {
+	stockId, err := strconv.Atoi(c.Param("stock-id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock Id"})
+		return
+	}
+
+	reports, err := bc.useCase.GetDamageReports(stockId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarController interface {
+	GetOpeningHours(c *gin.Context)
+	SetOpeningHours(c *gin.Context)
+	GetClosedDates(c *gin.Context)
+	CreateClosedDate(c *gin.Context)
+	DeleteClosedDate(c *gin.Context)
+	ImportNationalHolidays(c *gin.Context)
+}
+
+type calendarController struct {
+	useCase usecase.CalendarUseCase
+}
+
+func NewCalendarController(useCase usecase.CalendarUseCase) CalendarController {
+	return &calendarController{useCase: useCase}
+}
+
+func (cc *calendarController) GetOpeningHours(c *gin.Context) {
+	hours, err := cc.useCase.GetOpeningHours()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, hours)
+}
+
+// SetOpeningHours substitui o horário semanal da biblioteca.
+func (cc *calendarController) SetOpeningHours(c *gin.Context) {
+	var hours []model.OpeningHours
+	if err := c.ShouldBindJSON(&hours); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid opening hours input"})
+		return
+	}
+
+	if err := cc.useCase.SetOpeningHours(hours); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Opening hours updated successfully"})
+}
+
+// GetClosedDates lista os dias fechados, opcionalmente entre as datas 'from' e 'to' (YYYY-MM-DD).
+func (cc *calendarController) GetClosedDates(c *gin.Context) {
+	closedDates, err := cc.useCase.GetClosedDates(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, closedDates)
+}
+
+func (cc *calendarController) CreateClosedDate(c *gin.Context) {
+	var i struct {
+		Date   string `json:"date" binding:"required"`
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid closed date input"})
+		return
+	}
+
+	if err := cc.useCase.CreateClosedDate(i.Date, i.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Closed date created successfully"})
+}
+
+func (cc *calendarController) DeleteClosedDate(c *gin.Context) {
+	if err := cc.useCase.DeleteClosedDate(c.Param("date")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closed date deleted successfully"})
+}
+
+// ImportNationalHolidays cadastra os feriados nacionais do ano informado em 'year', ou de todos os anos disponíveis.
+func (cc *calendarController) ImportNationalHolidays(c *gin.Context) {
+	year := 0
+	if yearStr := c.Query("year"); yearStr != "" {
+		var err error
+		year, err = strconv.Atoi(yearStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+	}
+
+	imported, err := cc.useCase.ImportNationalHolidays(year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "National holidays imported successfully", "imported": imported})
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CirculationPolicyController interface {
+	GetCirculationPolicy(c *gin.Context)
+	UpdateCirculationPolicy(c *gin.Context)
+}
+
+type circulationPolicyController struct {
+	useCase usecase.CirculationPolicyUseCase
+}
+
+func NewCirculationPolicyController(useCase usecase.CirculationPolicyUseCase) CirculationPolicyController {
+	return &circulationPolicyController{useCase: useCase}
+}
+
+func (cc *circulationPolicyController) GetCirculationPolicy(c *gin.Context) {
+	policy, err := cc.useCase.GetCirculationPolicy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateCirculationPolicy substitui as regras de circulação, registrando quem fez a alteração.
+func (cc *circulationPolicyController) UpdateCirculationPolicy(c *gin.Context) {
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var i struct {
+		ReservationWindowHours       int      `json:"reservation_window_hours" binding:"required"`
+		BlockOnOverdueLoans          *bool    `json:"block_on_overdue_loans" binding:"required"`
+		MaxUnpaidFines               *float64 `json:"max_unpaid_fines"`
+		RequireValidMembership       *bool    `json:"require_valid_membership" binding:"required"`
+		RenewalBlockedByReservations *bool    `json:"renewal_blocked_by_reservations" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid circulation policy input"})
+		return
+	}
+
+	policy, err := cc.useCase.UpdateCirculationPolicy(model.CirculationPolicy{
+		ReservationWindowHours:       i.ReservationWindowHours,
+		BlockOnOverdueLoans:          *i.BlockOnOverdueLoans,
+		MaxUnpaidFines:               i.MaxUnpaidFines,
+		RequireValidMembership:       *i.RequireValidMembership,
+		RenewalBlockedByReservations: *i.RenewalBlockedByReservations,
+	}, adminId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryController interface {
+	OpenSession(c *gin.Context)
+	GetSessions(c *gin.Context)
+	GetSessionById(c *gin.Context)
+	AddScans(c *gin.Context)
+	CloseSession(c *gin.Context)
+	GetReport(c *gin.Context)
+	ApplyReport(c *gin.Context)
+}
+
+type inventoryController struct {
+	useCase usecase.InventoryUseCase
+}
+
+func NewInventoryController(useCase usecase.InventoryUseCase) InventoryController {
+	return &inventoryController{useCase: useCase}
+}
+
+// OpenSession abre uma sessão de inventário para uma filial e, opcionalmente, uma seção.
+func (ic *inventoryController) OpenSession(c *gin.Context) {
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var i struct {
+		Branch  string `json:"branch" binding:"required"`
+		Section string `json:"section"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory session input"})
+		return
+	}
+
+	session, err := ic.useCase.OpenSession(i.Branch, i.Section, adminId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set(middleware.AuditTargetIdKey, session.Id)
+	c.JSON(http.StatusCreated, session)
+}
+
+func (ic *inventoryController) GetSessions(c *gin.Context) {
+	status := c.Query("status")
+
+	sessions, err := ic.useCase.GetSessions(model.InventorySessionStatus(status))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (ic *inventoryController) GetSessionById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory session Id"})
+		return
+	}
+
+	session, err := ic.useCase.GetSessionById(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// AddScans recebe um lote de códigos lidos pelo scanner. Pode ser chamado várias vezes enquanto a sessão estiver aberta.
+func (ic *inventoryController) AddScans(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory session Id"})
+		return
+	}
+
+	var i struct {
+		Codes []int `json:"codes" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory scan input"})
+		return
+	}
+
+	added, err := ic.useCase.AddScans(id, i.Codes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Codes scanned", "received": len(i.Codes), "added": added})
+}
+
+// CloseSession encerra a sessão e retorna a reconciliação.
+func (ic *inventoryController) CloseSession(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory session Id"})
+		return
+	}
+
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	report, err := ic.useCase.CloseSession(id, adminId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (ic *inventoryController) GetReport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory session Id"})
+		return
+	}
+
+	report, err := ic.useCase.GetReport(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ApplyReport aplica em lote as mudanças de status indicadas pela reconciliação de uma sessão encerrada.
+func (ic *inventoryController) ApplyReport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory session Id"})
+		return
+	}
+
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var i struct {
+		MarkMissing   bool `json:"mark_missing"`
+		MarkAvailable bool `json:"mark_available"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory apply input"})
+		return
+	}
+
+	if !i.MarkMissing && !i.MarkAvailable {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one of 'mark_missing' or 'mark_available' must be true"})
+		return
+	}
+
+	result, err := ic.useCase.ApplyReport(id, adminId, i.MarkMissing, i.MarkAvailable)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"go-api/middleware"
 	"go-api/model"
+	"go-api/model/user"
+	"go-api/repository"
 	"go-api/usecase"
 	"net/http"
 	"strconv"
@@ -10,9 +14,12 @@ import (
 
 type LoanController interface {
 	CreateLoan(c *gin.Context)
+	CreateWalkInLoan(c *gin.Context)
 	GetLoansByFilters(c *gin.Context)
 	GetLoanById(c *gin.Context)
 	FinishLoan(c *gin.Context)
+	CheckIn(c *gin.Context)
+	RenewLoan(c *gin.Context)
 }
 
 type loanController struct {
@@ -80,10 +87,40 @@ func (lc *loanController) CreateLoan(c *gin.Context) {
 
 	loan, err := lc.loanUseCase.CreateLoanAndUpdateReservation(i.ReservationId, i.BookStockId, adminId)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		policyError(c, http.StatusBadRequest, err)
 		return
 	}
 
+	c.Set(middleware.AuditTargetIdKey, loan.Id)
+	c.JSON(http.StatusCreated, loan)
+}
+
+// CreateWalkInLoan empresta um exemplar a um leitor no balcão, sem reserva prévia.
+func (lc *loanController) CreateWalkInLoan(c *gin.Context) {
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var i struct {
+		UserId        int `json:"user_id" binding:"required"`
+		BookStockCode int `json:"book_stock_code" binding:"required"`
+		BorrowedDays  int `json:"borrowed_days" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	loan, err := lc.loanUseCase.CreateWalkInLoan(i.UserId, i.BookStockCode, i.BorrowedDays, adminId)
+	if err != nil {
+		policyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.Set(middleware.AuditTargetIdKey, loan.Id)
 	c.JSON(http.StatusCreated, loan)
 }
 
@@ -107,11 +144,110 @@ func (lc *loanController) FinishLoan(c *gin.Context) {
 		return
 	}
 
-	err = lc.loanUseCase.FinishLoan(loanId, adminId)
+	// O relatório de devolução é opcional, informando a condição do exemplar e eventuais danos
+	var report *model.LoanReturnRequest
+	if c.Request.ContentLength > 0 {
+		report = &model.LoanReturnRequest{}
+		if err := c.ShouldBindJSON(report); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid loan return input"})
+			return
+		}
+	}
+
+	err = lc.loanUseCase.FinishLoan(loanId, adminId, report)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		loanReturnError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "loan finished successfully"})
 }
+
+// CheckIn registra a devolução de um exemplar pelo código da etiqueta e responde com a próxima ação para ele.
+func (lc *loanController) CheckIn(c *gin.Context) {
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var request model.CheckInRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid check-in input"})
+		return
+	}
+
+	result, err := lc.loanUseCase.CheckIn(request, adminId)
+	if err != nil {
+		loanReturnError(c, err)
+		return
+	}
+
+	c.Set(middleware.AuditTargetIdKey, result.Loan.Id)
+	c.JSON(http.StatusOK, result)
+}
+
+// loanReturnError responde 404 para um empréstimo ou exemplar desconhecido ou sem empréstimo ativo, 400 para um
+// empréstimo já devolvido ou um relatório de devolução inválido e 500 para as demais falhas.
+func loanReturnError(c *gin.Context, err error) {
+	var notFoundErr *repository.NotFoundError
+	var reportErr *usecase.InvalidReturnReportError
+	switch {
+	case errors.As(err, &notFoundErr):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrLoanNotBorrowed), errors.As(err, &reportErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// RenewLoan renova um empréstimo ativo. Usuários sem a permissão de registrar empréstimos só podem renovar os
+// próprios empréstimos.
+func (lc *loanController) RenewLoan(c *gin.Context) {
+	loanId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid loan ID"})
+		return
+	}
+
+	// A renovação pelo próprio leitor não entra na trilha de auditoria, só a feita por funcionários
+	var ownerId *int
+	if !middleware.HasPermission(c, user.PermLoansCheckout) {
+		c.Set(middleware.AuditSkipKey, true)
+		userId, err := strconv.Atoi(c.GetString("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		ownerId = &userId
+	}
+
+	loan, err := lc.loanUseCase.RenewLoan(loanId, ownerId)
+	if err != nil {
+		renewLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// renewLoanError responde 404 para um empréstimo desconhecido ou de outro leitor, 400 para um empréstimo já devolvido,
+// 409 quando uma regra de circulação impede a renovação e 500 para as demais falhas. As recusas das regras voltam
+// com o código da regra, como em policyError.
+func renewLoanError(c *gin.Context, err error) {
+	var notFoundErr *repository.NotFoundError
+	var denial *model.PolicyDenial
+	switch {
+	case errors.As(err, &notFoundErr):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.As(err, &denial) && denial.Code == model.DenialLoanNotBorrowed:
+		c.JSON(http.StatusBadRequest, denial)
+	case errors.As(err, &denial):
+		c.JSON(http.StatusConflict, denial)
+	case errors.Is(err, repository.ErrLoanNotRenewable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
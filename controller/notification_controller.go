@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"fmt"
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/usecase"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController interface {
+	GetPreferences(c *gin.Context)
+	UpdatePreferences(c *gin.Context)
+	GetDeliveries(c *gin.Context)
+	GetInbox(c *gin.Context)
+	CountUnread(c *gin.Context)
+	MarkRead(c *gin.Context)
+	MarkAllRead(c *gin.Context)
+	StreamInbox(c *gin.Context)
+}
+
+// inboxHeartbeat é o intervalo dos comentários enviados no stream para manter a conexão aberta em proxies.
+const inboxHeartbeat = 30 * time.Second
+
+type notificationController struct {
+	useCase usecase.NotificationUseCase
+}
+
+func NewNotificationController(useCase usecase.NotificationUseCase) NotificationController {
+	return &notificationController{useCase: useCase}
+}
+
+// GetPreferences retorna as preferências de notificação do usuário logado.
+func (nc *notificationController) GetPreferences(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	preferences, err := nc.useCase.GetPreferences(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences substitui as preferências de notificação do usuário logado.
+func (nc *notificationController) UpdatePreferences(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var preferences model.NotificationPreferences
+	if err := c.ShouldBindJSON(&preferences); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification preferences input"})
+		return
+	}
+
+	if err := nc.useCase.UpdatePreferences(userId, preferences); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification preferences updated successfully"})
+}
+
+// GetDeliveries lista o registro de entregas de notificações, filtrado por 'status' e 'user_id'.
+func (nc *notificationController) GetDeliveries(c *gin.Context) {
+	userId := 0
+	if c.Query("user_id") != "" {
+		id, err := strconv.Atoi(c.Query("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		userId = id
+	}
+
+	deliveries, err := nc.useCase.GetDeliveries(model.NotificationDeliveryStatus(c.Query("status")), userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetInbox lista a caixa de entrada do usuário logado. Com 'unread=true', apenas as mensagens não lidas.
+func (nc *notificationController) GetInbox(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	notifications, err := nc.useCase.GetInbox(userId, c.Query("unread") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (nc *notificationController) CountUnread(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	count, err := nc.useCase.CountUnread(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+func (nc *notificationController) MarkRead(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := nc.useCase.MarkRead(id, userId); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (nc *notificationController) MarkAllRead(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	count, err := nc.useCase.MarkAllRead(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notifications marked as read", "marked": count})
+}
+
+// StreamInbox abre um stream Server-Sent Events com as novas mensagens da caixa de entrada do usuário logado. Cada
+// mensagem é enviada como um evento 'notification' com o JSON da mensagem, e a contagem de não lidas é enviada no
+// início como um evento 'unread'. A sessão é conferida a cada heartbeat, e o stream é encerrado com um evento
+// 'session_ended' quando ela é revogada, o usuário é desativado ou o token expira.
+func (nc *notificationController) StreamInbox(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// A assinatura é feita antes da contagem para que nenhuma mensagem criada entre as duas seja perdida
+	messages, unsubscribe := nc.useCase.Subscribe(userId)
+	defer unsubscribe()
+
+	count, err := nc.useCase.CountUnread(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("unread", gin.H{"unread": count})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(inboxHeartbeat)
+	defer heartbeat.Stop()
+
+	// O stream não dura além da validade do token de acesso usado para abri-lo
+	var expired <-chan time.Time
+	if expiresAt := c.GetTime("tokenExpiresAt"); !expiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(expiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	endSession := func() bool {
+		c.SSEvent("session_ended", gin.H{"error": "Session has expired or was revoked, please log in again"})
+		return false
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case n, ok := <-messages:
+			if !ok {
+				return false
+			}
+			c.SSEvent("notification", n)
+			return true
+		case <-heartbeat.C:
+			active, err := middleware.SessionActive(c)
+			if err != nil {
+				log.Printf("error checking session of inbox stream for user %d: %v", userId, err)
+				return false
+			}
+			if !active {
+				return endSession()
+			}
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+			return err == nil
+		case <-expired:
+			return endSession()
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
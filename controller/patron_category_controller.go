@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PatronCategoryController interface {
+	GetPatronCategories(c *gin.Context)
+	CreatePatronCategory(c *gin.Context)
+	UpdatePatronCategory(c *gin.Context)
+	DeletePatronCategory(c *gin.Context)
+}
+
+type patronCategoryController struct {
+	useCase usecase.PatronCategoryUseCase
+}
+
+func NewPatronCategoryController(useCase usecase.PatronCategoryUseCase) PatronCategoryController {
+	return &patronCategoryController{useCase: useCase}
+}
+
+func (pc *patronCategoryController) GetPatronCategories(c *gin.Context) {
+	categories, err := pc.useCase.GetPatronCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// CreatePatronCategory cria uma categoria de leitor com a sua política de empréstimo.
+func (pc *patronCategoryController) CreatePatronCategory(c *gin.Context) {
+	var category user.PatronCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patron category input"})
+		return
+	}
+
+	created, err := pc.useCase.CreatePatronCategory(category)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set(middleware.AuditTargetIdKey, created.Id)
+	c.JSON(http.StatusCreated, created)
+}
+
+func (pc *patronCategoryController) UpdatePatronCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patron category Id"})
+		return
+	}
+
+	var category user.PatronCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patron category input"})
+		return
+	}
+	category.Id = id
+
+	if err := pc.useCase.UpdatePatronCategory(category); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Patron category updated successfully"})
+}
+
+func (pc *patronCategoryController) DeletePatronCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patron category Id"})
+		return
+	}
+
+	if err := pc.useCase.DeletePatronCategory(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Patron category deleted successfully"})
+}
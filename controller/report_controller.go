@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportController interface {
+	GetUnusedCopies(c *gin.Context)
+	GetUnusedTitles(c *gin.Context)
+	GetTitleCirculation(c *gin.Context)
+	GetGenreCirculation(c *gin.Context)
+	GetDamagedCopies(c *gin.Context)
+	GetWaitlistPressure(c *gin.Context)
+	GetExpiringMemberships(c *gin.Context)
+}
+
+type reportController struct {
+	useCase usecase.ReportUseCase
+}
+
+func NewReportController(useCase usecase.ReportUseCase) ReportController {
+	return &reportController{useCase: useCase}
+}
+
+func (rc *reportController) GetUnusedCopies(c *gin.Context) {
+	months, err := strconv.Atoi(c.DefaultQuery("months", "12"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid months"})
+		return
+	}
+
+	reports, err := rc.useCase.GetUnusedCopies(months)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, "unused-copies", reports)
+}
+
+func (rc *reportController) GetUnusedTitles(c *gin.Context) {
+	months, err := strconv.Atoi(c.DefaultQuery("months", "12"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid months"})
+		return
+	}
+
+	reports, err := rc.useCase.GetUnusedTitles(months)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, "unused-titles", reports)
+}
+
+func (rc *reportController) GetTitleCirculation(c *gin.Context) {
+	months, limit, ok := circulationQuery(c)
+	if !ok {
+		return
+	}
+
+	reports, err := rc.useCase.GetTitleCirculation(months, limit, c.DefaultQuery("order", "most"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, "title-circulation", reports)
+}
+
+func (rc *reportController) GetGenreCirculation(c *gin.Context) {
+	months, limit, ok := circulationQuery(c)
+	if !ok {
+		return
+	}
+
+	reports, err := rc.useCase.GetGenreCirculation(months, limit, c.DefaultQuery("order", "most"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, "genre-circulation", reports)
+}
+
+func (rc *reportController) GetDamagedCopies(c *gin.Context) {
+	minReports, err := strconv.Atoi(c.DefaultQuery("min_reports", "2"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_reports"})
+		return
+	}
+
+	reports, err := rc.useCase.GetDamagedCopies(minReports)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, "damaged-copies", reports)
+}
+
+func (rc *reportController) GetWaitlistPressure(c *gin.Context) {
+	minRatio, err := strconv.ParseFloat(c.DefaultQuery("min_ratio", "1"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_ratio"})
+		return
+	}
+
+	reports, err := rc.useCase.GetWaitlistPressure(minRatio)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, "waitlist-pressure", reports)
+}
+
+func (rc *reportController) GetExpiringMemberships(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+		return
+	}
+
+	reports, err := rc.useCase.GetExpiringMemberships(days)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondReport(c, "expiring-memberships", reports)
+}
+
+// circulationQuery lê os parâmetros "months" (0 = todo o histórico) e "limit" dos relatórios de circulação.
+func circulationQuery(c *gin.Context) (int, int, bool) {
+	months, err := strconv.Atoi(c.DefaultQuery("months", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid months"})
+		return 0, 0, false
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return 0, 0, false
+	}
+
+	return months, limit, true
+}
+
+// respondReport responde com o relatório em JSON ou, com "?format=csv", como um arquivo CSV para download.
+func respondReport[T model.CSVRow](c *gin.Context, name string, reports *[]T) {
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, reports)
+	case "csv":
+		var row T
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", name))
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		writer := csv.NewWriter(c.Writer)
+		_ = writer.Write(row.CSVHeader())
+		for _, report := range *reports {
+			_ = writer.Write(report.CSVRecord())
+		}
+		writer.Flush()
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Valid values: 'json', 'csv'"})
+	}
+}
@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"go-api/middleware"
 	"go-api/model"
+	"go-api/model/user"
 	"go-api/usecase"
 	"net/http"
 	"strconv"
@@ -52,6 +55,8 @@ func (rc *reservationController) CreateReservation(c *gin.Context) {
 	var i struct {
 		BorrowedDays int `json:"borrowed_days" binding:"required"`
 		BookId       int `json:"book_id" binding:"required"`
+		// UserId é informado por funcionários que reservam em nome de um leitor.
+		UserId *int `json:"user_id"`
 	}
 
 	if err := c.ShouldBindJSON(&i); err != nil {
@@ -59,12 +64,39 @@ func (rc *reservationController) CreateReservation(c *gin.Context) {
 		return
 	}
 
-	reservation, err := rc.useCase.CreateReservation(i.BorrowedDays, userId, i.BookId)
+	var adminId *int
+	if i.UserId != nil && *i.UserId != userId {
+		if !middleware.HasPermission(c, user.PermReservationsWrite) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+		adminId = &userId
+		userId = *i.UserId
+	}
+
+	reservation, err := rc.useCase.CreateReservation(i.BorrowedDays, userId, i.BookId, adminId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		policyError(c, http.StatusInternalServerError, err)
 		return
 	}
 
+	// Só as reservas feitas por funcionários em nome de um leitor entram na trilha de auditoria
+	if adminId != nil {
+		c.Set(middleware.AuditTargetIdKey, reservation.Id)
+	} else {
+		c.Set(middleware.AuditSkipKey, true)
+	}
 	c.JSON(http.StatusCreated, reservation)
 
 }
+
+// policyError responde com 422 e o código da regra quando uma regra de circulação recusou a operação, ou com o
+// status informado para qualquer outro erro.
+func policyError(c *gin.Context, status int, err error) {
+	var denial *model.PolicyDenial
+	if errors.As(err, &denial) {
+		c.JSON(http.StatusUnprocessableEntity, denial)
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
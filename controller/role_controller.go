@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"go-api/middleware"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController interface {
+	GetRoles(c *gin.Context)
+	GetPermissions(c *gin.Context)
+	CreateRole(c *gin.Context)
+	UpdateRole(c *gin.Context)
+	SetRolePermissions(c *gin.Context)
+	DeleteRole(c *gin.Context)
+}
+
+type roleController struct {
+	useCase usecase.RoleUseCase
+}
+
+func NewRoleController(useCase usecase.RoleUseCase) RoleController {
+	return &roleController{useCase: useCase}
+}
+
+func (rc *roleController) GetRoles(c *gin.Context) {
+	roles, err := rc.useCase.GetRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+func (rc *roleController) GetPermissions(c *gin.Context) {
+	permissions, err := rc.useCase.GetPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// CreateRole cria uma role com as permissões informadas.
+func (rc *roleController) CreateRole(c *gin.Context) {
+	var r struct {
+		Name              string   `json:"name" binding:"required"`
+		RequiresTwoFactor bool     `json:"requires_2fa"`
+		Permissions       []string `json:"permissions"`
+	}
+
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role input"})
+		return
+	}
+
+	role, err := rc.useCase.CreateRole(r.Name, r.RequiresTwoFactor, r.Permissions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set(middleware.AuditTargetIdKey, role.Id)
+	c.JSON(http.StatusCreated, role)
+}
+
+func (rc *roleController) UpdateRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role Id"})
+		return
+	}
+
+	var r struct {
+		Name              string `json:"name" binding:"required"`
+		RequiresTwoFactor bool   `json:"requires_2fa"`
+	}
+
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role input"})
+		return
+	}
+
+	if err := rc.useCase.UpdateRole(id, r.Name, r.RequiresTwoFactor); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated successfully"})
+}
+
+// SetRolePermissions substitui o conjunto de permissões da role.
+func (rc *roleController) SetRolePermissions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role Id"})
+		return
+	}
+
+	var r struct {
+		Permissions []string `json:"permissions" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role permissions input"})
+		return
+	}
+
+	if err := rc.useCase.SetRolePermissions(id, r.Permissions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role permissions updated successfully"})
+}
+
+func (rc *roleController) DeleteRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role Id"})
+		return
+	}
+
+	if err := rc.useCase.DeleteRole(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
@@ -1,19 +1,53 @@
 package controller
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
 	"go-api/usecase"
 	"go-api/utils"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserController interface {
 	Register(c *gin.Context)
+	SignUp(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	RefreshSession(c *gin.Context)
+	Logout(c *gin.Context)
+	LogoutAll(c *gin.Context)
+	GetLoginLockouts(c *gin.Context)
+	VerifyLoginTwoFactor(c *gin.Context)
+	EnrollLoginTwoFactor(c *gin.Context)
+	EnrollTwoFactor(c *gin.Context)
+	ConfirmTwoFactor(c *gin.Context)
+	DisableTwoFactor(c *gin.Context)
+	ResetTwoFactor(c *gin.Context)
+	ClearLoginLockout(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
 	Login(c *gin.Context)
 	GetUsersByFilters(c *gin.Context)
 	GetUserById(c *gin.Context)
+	GetProfile(c *gin.Context)
+	ExportLoggedUserData(c *gin.Context)
+	ExportUserData(c *gin.Context)
+	AnonymizeUser(c *gin.Context)
+	RenewMembership(c *gin.Context)
+	SetPatronCategory(c *gin.Context)
+	UpdateProfile(c *gin.Context)
 	GetUserLoans(c *gin.Context)
 	ToggleUser(action string) gin.HandlerFunc
 	DeleteUser(c *gin.Context)
@@ -21,6 +55,7 @@ type UserController interface {
 	GetLoggedUserReservations(c *gin.Context)
 	CancelUserReservation(c *gin.Context)
 	CancelLoggedUserReservation(c *gin.Context)
+	GetUserFines(c *gin.Context)
 }
 
 type userController struct {
@@ -40,6 +75,8 @@ func (uc *userController) Register(c *gin.Context) {
 		Email    string `json:"email" binding:"required"`
 		Password string `json:"password" binding:"required"`
 		RoleId   int    `json:"role_id" binding:"required"`
+		// PatronCategoryId é opcional, sem ele o usuário é criado na categoria padrão.
+		PatronCategoryId *int `json:"patron_category_id"`
 	}
 	// Valida o input de dados
 	if err := c.ShouldBindJSON(&i); err != nil {
@@ -59,15 +96,218 @@ func (uc *userController) Register(c *gin.Context) {
 		return
 	}
 
-	userId, err := uc.useCase.Register(i.Name, i.Cpf, i.Phone, i.Email, hashedPassword, i.RoleId)
+	userId, err := uc.useCase.Register(i.Name, i.Cpf, i.Phone, i.Email, hashedPassword, i.RoleId, i.PatronCategoryId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		userError(c, err)
 		return
 	}
 
+	c.Set(middleware.AuditTargetIdKey, *userId)
 	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully", "user_id": userId})
 }
 
+// SignUp permite que um leitor crie a própria conta. A conta só pode ser usada após a verificação do email.
+func (uc *userController) SignUp(c *gin.Context) {
+	var i struct {
+		Name     string `json:"name" binding:"required"`
+		Cpf      string `json:"cpf" binding:"required"`
+		Phone    string `json:"phone" binding:"required"`
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sign up input"})
+		return
+	}
+
+	if !utils.IsValidCPF(i.Cpf) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cpf input"})
+		return
+	}
+
+	if !utils.IsValidEmail(i.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email input"})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(i.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error hashing password"})
+		return
+	}
+
+	userId, err := uc.useCase.SignUp(i.Name, i.Cpf, i.Phone, i.Email, hashedPassword)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User registered successfully. Check your email to verify your account",
+		"user_id": userId,
+	})
+}
+
+func (uc *userController) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification token is required"})
+		return
+	}
+
+	if err := uc.useCase.VerifyEmail(token); err != nil {
+		var conflictErr *repository.UserConflictError
+		if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email has been successfully verified"})
+}
+
+func (uc *userController) ResendVerification(c *gin.Context) {
+	var i struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := uc.useCase.ResendVerification(i.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists and is pending verification, a new email has been sent"})
+}
+
+// ChangePassword altera a senha do usuário logado e encerra as suas outras sessões.
+func (uc *userController) ChangePassword(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	sessionId, err := strconv.Atoi(c.GetString("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session Id"})
+		return
+	}
+
+	var i struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := uc.useCase.ChangePassword(userId, sessionId, i.CurrentPassword, i.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been successfully changed"})
+}
+
+// RefreshSession troca um token de atualização válido por um novo par de tokens.
+func (uc *userController) RefreshSession(c *gin.Context) {
+	var i struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	tokens, err := uc.useCase.RefreshSession(i.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout encerra a sessão do token de acesso usado na requisição.
+func (uc *userController) Logout(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	sessionId, err := strconv.Atoi(c.GetString("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session Id"})
+		return
+	}
+
+	if err := uc.useCase.Logout(userId, sessionId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll encerra todas as sessões do usuário logado, inclusive a atual.
+func (uc *userController) LogoutAll(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	if err := uc.useCase.LogoutAll(userId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All sessions have been logged out"})
+}
+
+func (uc *userController) ForgotPassword(c *gin.Context) {
+	var i struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := uc.useCase.ForgotPassword(i.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a password reset email has been sent"})
+}
+
+func (uc *userController) ResetPassword(c *gin.Context) {
+	var i struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := uc.useCase.ResetPassword(i.Token, i.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been successfully reset"})
+}
+
 // Login recebe um input JSON através do gin.Context e tenta realizar o login do usuário.
 func (uc *userController) Login(c *gin.Context) {
 	var input struct {
@@ -80,12 +320,62 @@ func (uc *userController) Login(c *gin.Context) {
 		return
 	}
 	// Tenta logar o usuário
-	token, err := uc.useCase.Login(input.Email, input.Password)
+	result, err := uc.useCase.Login(input.Email, input.Password, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// VerifyLoginTwoFactor conclui o login de uma conta com 2FA usando o desafio retornado pelo login e o código do
+// autenticador (ou um código de recuperação).
+func (uc *userController) VerifyLoginTwoFactor(c *gin.Context) {
+	var i struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil || (i.Code == "" && i.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor input"})
+		return
+	}
+
+	result, err := uc.useCase.VerifyLoginTwoFactor(i.ChallengeToken, i.Code, i.RecoveryCode, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// EnrollLoginTwoFactor inicia o cadastro do autenticador durante o login, quando a role do usuário exige 2FA.
+func (uc *userController) EnrollLoginTwoFactor(c *gin.Context) {
+	var i struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	enrollment, err := uc.useCase.EnrollLoginTwoFactor(i.ChallengeToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, token)
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// loginError responde aos erros de login, com 429 e Retry-After quando a conta ou o IP estão bloqueados.
+func loginError(c *gin.Context, err error) {
+	var lockedErr *usecase.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 }
 
 func (uc *userController) GetUsersByFilters(c *gin.Context) {
@@ -98,7 +388,8 @@ func (uc *userController) GetUsersByFilters(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, userAccountList)
+	fullCpf := middleware.HasPermission(c, user.PermUsersReadCpf)
+	c.JSON(http.StatusOK, user.NewAccountResponses(*userAccountList, fullCpf))
 }
 
 func (uc *userController) GetUserById(c *gin.Context) {
@@ -114,7 +405,215 @@ func (uc *userController) GetUserById(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, userAccount)
+	fullCpf := middleware.HasPermission(c, user.PermUsersReadCpf)
+	c.JSON(http.StatusOK, user.NewAccountResponse(userAccount, fullCpf))
+}
+
+// GetProfile retorna os dados da conta do usuário logado, com o cpf completo.
+func (uc *userController) GetProfile(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userAccount, err := uc.useCase.GetUserById(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user.NewAccountResponse(userAccount, true))
+}
+
+// UpdateProfile atualiza nome, telefone e email do usuário logado. Um novo email só passa a valer após a
+// confirmação pelo link enviado a ele.
+func (uc *userController) UpdateProfile(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var i struct {
+		Name  string `json:"name" binding:"required"`
+		Phone string `json:"phone" binding:"required"`
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile input"})
+		return
+	}
+
+	if !utils.IsValidPhone(i.Phone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone input"})
+		return
+	}
+
+	if !utils.IsValidEmail(i.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email input"})
+		return
+	}
+
+	userAccount, err := uc.useCase.UpdateProfile(userId, strings.TrimSpace(i.Name), i.Phone, i.Email)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.NewAccountResponse(userAccount, true))
+}
+
+// ExportLoggedUserData exporta os dados pessoais do usuário logado, em JSON ou, com "?format=zip", como um pacote ZIP.
+func (uc *userController) ExportLoggedUserData(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	uc.exportUserData(c, userId)
+}
+
+// ExportUserData exporta os dados pessoais de um usuário, para atender um pedido de acesso da LGPD.
+func (uc *userController) ExportUserData(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	uc.exportUserData(c, id)
+}
+
+func (uc *userController) exportUserData(c *gin.Context, id int) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Valid values: 'json', 'zip'"})
+		return
+	}
+
+	export, err := uc.useCase.ExportUserData(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	// O pacote ZIP tem um arquivo JSON para cada tipo de dado
+	files := []struct {
+		name string
+		data any
+	}{
+		{"account.json", export.Account},
+		{"reservations.json", export.Reservations},
+		{"loans.json", export.Loans},
+		{"fines.json", export.Fines},
+		{"sessions.json", export.Sessions},
+		{"purchase_suggestions.json", export.PurchaseSuggestions},
+		{"notification_preferences.json", export.NotificationPreferences},
+		{"notifications.json", export.Notifications},
+		{"notification_deliveries.json", export.NotificationDeliveries},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.GeneratedAt})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating export package"})
+			return
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating export package"})
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating export package"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=user-%d-data.zip", id))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// AnonymizeUser apaga os dados pessoais de um usuário, mantendo o seu histórico de circulação de forma anônima.
+func (uc *userController) AnonymizeUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	if err := uc.useCase.AnonymizeUser(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User has been successfully anonymized"})
+}
+
+// RenewMembership renova a carteirinha de um usuário por mais um ano.
+func (uc *userController) RenewMembership(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	userAccount, err := uc.useCase.RenewMembership(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Membership has been successfully renewed", "membership": userAccount.Membership})
+}
+
+// SetPatronCategory altera a categoria de leitor de um usuário.
+func (uc *userController) SetPatronCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	var i struct {
+		PatronCategoryId int `json:"patron_category_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patron category input"})
+		return
+	}
+
+	userAccount, err := uc.useCase.SetPatronCategory(id, i.PatronCategoryId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Patron category has been successfully updated",
+		"patron_category": userAccount.PatronCategory,
+	})
+}
+
+// userError responde aos erros de escrita de usuários, com 409 quando o cpf, telefone ou email já pertence a outra
+// conta.
+func userError(c *gin.Context, err error) {
+	var conflictErr *repository.UserConflictError
+	if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
 
 func (uc *userController) GetUserLoans(c *gin.Context) {
@@ -277,3 +776,133 @@ func (uc *userController) CancelLoggedUserReservation(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User reservation has been successfully canceled"})
 }
+
+func (uc *userController) GetUserFines(c *gin.Context) {
+	userIdStr, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized user"})
+		return
+	}
+
+	userId, err := strconv.Atoi(userIdStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	fines, err := uc.useCase.GetUserFines(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, fines)
+}
+
+// GetLoginLockouts lista as contas e IPs com tentativas de login malsucedidas. Com "locked=true", apenas os
+// bloqueados no momento.
+func (uc *userController) GetLoginLockouts(c *gin.Context) {
+	scope := c.Query("scope")
+	lockedOnly := c.Query("locked") == "true"
+
+	lockouts, err := uc.useCase.GetLoginLockouts(user.LoginLockoutScope(scope), lockedOnly)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, lockouts)
+}
+
+// ClearLoginLockout remove o bloqueio e zera as tentativas de uma conta ou IP.
+func (uc *userController) ClearLoginLockout(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lockout id"})
+		return
+	}
+
+	if err := uc.useCase.ClearLoginLockout(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Login lockout has been successfully cleared"})
+}
+
+// EnrollTwoFactor inicia o cadastro do autenticador do usuário logado, retornando o segredo e a URI otpauth
+// (conteúdo do QR code).
+func (uc *userController) EnrollTwoFactor(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	enrollment, err := uc.useCase.EnrollTwoFactor(userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (uc *userController) ConfirmTwoFactor(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	var i struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	recoveryCodes, err := uc.useCase.ConfirmTwoFactor(userId, i.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication has been enabled", "recovery_codes": recoveryCodes})
+}
+
+func (uc *userController) DisableTwoFactor(c *gin.Context) {
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user Id"})
+		return
+	}
+
+	var i struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&i); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := uc.useCase.DisableTwoFactor(userId, i.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication has been disabled"})
+}
+
+// ResetTwoFactor remove o autenticador de um usuário, para quando ele perde acesso ao aparelho e aos códigos de
+// recuperação.
+func (uc *userController) ResetTwoFactor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	if err := uc.useCase.ResetTwoFactor(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication has been successfully reset"})
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController interface {
+	GetEndpoints(c *gin.Context)
+	CreateEndpoint(c *gin.Context)
+	UpdateEndpoint(c *gin.Context)
+	DeleteEndpoint(c *gin.Context)
+	GetDeliveries(c *gin.Context)
+	ReplayDelivery(c *gin.Context)
+}
+
+type webhookController struct {
+	useCase usecase.WebhookUseCase
+}
+
+func NewWebhookController(useCase usecase.WebhookUseCase) WebhookController {
+	return &webhookController{useCase: useCase}
+}
+
+func (wc *webhookController) GetEndpoints(c *gin.Context) {
+	endpoints, err := wc.useCase.GetEndpoints()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints)
+}
+
+// CreateEndpoint cadastra um webhook e retorna o segredo usado para assinar os envios.
+func (wc *webhookController) CreateEndpoint(c *gin.Context) {
+	adminId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var endpoint model.WebhookEndpoint
+	if err := c.ShouldBindJSON(&endpoint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook endpoint input"})
+		return
+	}
+
+	created, err := wc.useCase.CreateEndpoint(endpoint, adminId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set(middleware.AuditTargetIdKey, created.Id)
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateEndpoint substitui a URL, a descrição, os eventos e a situação de um webhook.
+func (wc *webhookController) UpdateEndpoint(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook endpoint Id"})
+		return
+	}
+
+	var endpoint model.WebhookEndpoint
+	if err := c.ShouldBindJSON(&endpoint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook endpoint input"})
+		return
+	}
+	endpoint.Id = id
+
+	if err := wc.useCase.UpdateEndpoint(endpoint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook endpoint updated successfully"})
+}
+
+func (wc *webhookController) DeleteEndpoint(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook endpoint Id"})
+		return
+	}
+
+	if err := wc.useCase.DeleteEndpoint(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook endpoint deleted successfully"})
+}
+
+// GetDeliveries lista o registro de entregas dos webhooks, filtrado por 'endpoint_id', 'status' e 'event'.
+func (wc *webhookController) GetDeliveries(c *gin.Context) {
+	endpointId := 0
+	if c.Query("endpoint_id") != "" {
+		id, err := strconv.Atoi(c.Query("endpoint_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook endpoint Id"})
+			return
+		}
+		endpointId = id
+	}
+
+	deliveries, err := wc.useCase.GetDeliveries(endpointId, model.WebhookDeliveryStatus(c.Query("status")),
+		model.WebhookEvent(c.Query("event")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayDelivery reenvia uma entrega que falhou e retorna o resultado da nova tentativa.
+func (wc *webhookController) ReplayDelivery(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook delivery Id"})
+		return
+	}
+
+	delivery, err := wc.useCase.ReplayDelivery(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
@@ -32,14 +32,19 @@ CREATE TABLE IF NOT EXISTS book
     fk_author_id INTEGER references author (id) ON DELETE RESTRICT
 );
 
-CREATE TYPE book_stock_status AS ENUM ('available', 'borrowed', 'missing');
+CREATE TYPE book_stock_status AS ENUM ('available', 'borrowed', 'missing', 'damaged', 'in_repair', 'withdrawn');
+
+CREATE TYPE book_stock_condition AS ENUM ('new', 'good', 'fair', 'poor');
 
 CREATE TABLE IF NOT EXISTS book_stock
 (
     id         SERIAL PRIMARY KEY,
-    status     book_stock_status DEFAULT 'available',
+    status     book_stock_status    DEFAULT 'available',
+    condition  book_stock_condition DEFAULT 'good',
     code       INTEGER NOT NULL UNIQUE,
-    created_at TIMESTAMP         DEFAULT CURRENT_TIMESTAMP,
+    branch     VARCHAR(100),
+    section    VARCHAR(100),
+    created_at TIMESTAMP            DEFAULT CURRENT_TIMESTAMP,
     fk_book_id INTEGER REFERENCES book (id) ON DELETE CASCADE
 );
 
@@ -48,7 +53,7 @@ CREATE OR REPLACE FUNCTION prevent_book_stock_delete_if_borrowed()
 $$
 BEGIN
     IF OLD.status = 'borrowed' THEN
-        RAISE EXCEPTION 'Cannot delete book_stock unless the status is ''available'' or ''missing''.';
+        RAISE EXCEPTION 'Cannot delete book_stock while its status is ''borrowed''.';
     END IF;
     RETURN OLD;
 END;
@@ -67,7 +72,7 @@ $$
 DECLARE
     stock_and_reservations_count RECORD;
 BEGIN
-    IF TG_OP = 'UPDATE' AND NEW.status = 'missing' OR TG_OP = 'DELETE' THEN
+    IF TG_OP = 'UPDATE' AND NEW.status IN ('missing', 'withdrawn') OR TG_OP = 'DELETE' THEN
         -- Single query to get both total stock count and active reservations count
         SELECT COUNT(bs.id) AS total_stock_count,
                COUNT(r.id)  AS active_reservations_count
@@ -136,11 +141,45 @@ CREATE TABLE IF NOT EXISTS book_genre
 -- Account Role Table
 CREATE TABLE IF NOT EXISTS account_role
 (
-    id         SERIAL PRIMARY KEY,
-    name       VARCHAR(30) UNIQUE NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(30) UNIQUE NOT NULL,
+    -- Users with this role must complete TOTP two-factor authentication to log in
+    requires_2fa BOOLEAN   DEFAULT FALSE,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Default roles. Self sign-up creates accounts with the 'user' (patron) role. Permissions are granted in section 14
+INSERT INTO account_role (name)
+VALUES ('admin'),
+       ('user'),
+       ('librarian')
+ON CONFLICT (name) DO NOTHING;
+
+-- Patron Category Table. Each category has its own borrowing policy
+CREATE TABLE IF NOT EXISTS patron_category
+(
+    id              SERIAL PRIMARY KEY,
+    name            VARCHAR(30) UNIQUE NOT NULL,
+    -- Maximum pending reservations plus borrowed loans at the same time
+    max_items       INTEGER        NOT NULL CHECK ( max_items > 0 ),
+    -- Loan lengths (in days) a patron can choose when reserving
+    loan_days       INTEGER[]      NOT NULL CHECK ( CARDINALITY(loan_days) > 0 AND 0 < ALL (loan_days) ),
+    max_renewals    INTEGER        NOT NULL DEFAULT 0 CHECK ( max_renewals >= 0 ),
+    -- Charged per day (or part of a day) a loan is returned late
+    daily_fine_rate NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK ( daily_fine_rate >= 0 )
 );
 
+-- Default patron categories. Accounts created without a category are students
+INSERT INTO patron_category (name, max_items, loan_days, max_renewals, daily_fine_rate)
+VALUES ('student', 5, '{15, 30}', 2, 0.50),
+       ('staff', 10, '{30, 60, 90}', 3, 0.25),
+       ('visitor', 2, '{7, 14}', 0, 1.00),
+       ('child', 3, '{15}', 1, 0.25)
+ON CONFLICT (name) DO NOTHING;
+
+-- Account Status Enum Type
+CREATE TYPE account_status AS ENUM ('pending_verification', 'verified');
+
 -- User Account Table
 CREATE TABLE IF NOT EXISTS user_account
 (
@@ -149,11 +188,20 @@ CREATE TABLE IF NOT EXISTS user_account
     cpf             CHAR(11) UNIQUE     NOT NULL,
     phone           VARCHAR(20) UNIQUE  NOT NULL,
     email           VARCHAR(150) UNIQUE NOT NULL,
+    -- New email waiting for verification; it replaces `email` once confirmed
+    pending_email   VARCHAR(150),
     password_hash   VARCHAR(255)        NOT NULL,
     created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     is_active       BOOLEAN   DEFAULT TRUE,
-    fk_account_role INTEGER REFERENCES account_role (id) ON DELETE RESTRICT
+    status          account_status DEFAULT 'verified',
+    -- Library card validity; reservations are blocked after the expiry date until the membership is renewed
+    membership_started_on DATE DEFAULT CURRENT_DATE,
+    membership_expires_on DATE DEFAULT (CURRENT_DATE + INTERVAL '1 year')::DATE,
+    -- Set when the personal data was scrubbed; the row is kept so circulation history stays intact
+    anonymized_at   TIMESTAMP,
+    fk_account_role INTEGER REFERENCES account_role (id) ON DELETE RESTRICT,
+    fk_patron_category INTEGER NOT NULL REFERENCES patron_category (id) ON DELETE RESTRICT
 );
 
 -- Trigger to update `updated_at` on user account updates
@@ -185,8 +233,10 @@ CREATE TABLE IF NOT EXISTS reservation
 (
     id            SERIAL PRIMARY KEY,
     reserved_at   TIMESTAMP          DEFAULT CURRENT_TIMESTAMP,
-    expires_at    TIMESTAMP GENERATED ALWAYS AS ( reserved_at + INTERVAL '24 hours') STORED,
-    borrowed_days INTEGER NOT NULL CHECK ( borrowed_days <= 90 ),
+    -- Set on creation from circulation_policy.reservation_window_hours
+    expires_at    TIMESTAMP NOT NULL,
+    -- Allowed lengths depend on the patron category (patron_category.loan_days)
+    borrowed_days INTEGER NOT NULL CHECK ( borrowed_days > 0 ),
     status        reservation_status DEFAULT 'pending',
     fk_user_id    INTEGER REFERENCES user_account (id) ON DELETE CASCADE,
     fk_admin_id   INTEGER REFERENCES user_account (id) ON DELETE SET NULL,
@@ -226,6 +276,7 @@ CREATE TABLE IF NOT EXISTS loan
     return_by         TIMESTAMP NOT NULL,
     returned_at       TIMESTAMP CHECK (returned_at IS NULL OR returned_at > loaned_at),
     status            loan_status DEFAULT 'borrowed',
+    renewal_count     INTEGER     DEFAULT 0,
     fk_admin_id       INTEGER   REFERENCES user_account (id) ON DELETE SET NULL,
     fk_book_stock_id  INTEGER REFERENCES book_stock (id) ON DELETE CASCADE,
     fk_reservation_id INTEGER REFERENCES reservation (id) ON DELETE CASCADE
@@ -238,6 +289,12 @@ $$
 BEGIN
     -- Check if the loan status is changed to 'returned'
     IF NEW.status = 'returned' AND OLD.status != 'returned' THEN
+        -- Record the copy going back to the shelf in its history
+        INSERT INTO book_stock_history (old_status, new_status, reason, fk_book_stock_id, fk_actor_id, fk_loan_id)
+        SELECT status, 'available', 'loan returned', id, NEW.fk_admin_id, NEW.id
+        FROM book_stock
+        WHERE id = NEW.fk_book_stock_id;
+
         UPDATE book_stock
         SET status = 'available'
         WHERE id = NEW.fk_book_stock_id;
@@ -275,4 +332,713 @@ CREATE OR REPLACE TRIGGER before_loan_delete_check_status
     BEFORE DELETE
     ON loan
     FOR EACH ROW
-EXECUTE FUNCTION prevent_loan_delete_if_active()
+EXECUTE FUNCTION prevent_loan_delete_if_active();
+
+-- ===========================
+-- 6. Damage Report and Fine Tables
+-- ===========================
+
+-- Damage Report Table
+CREATE TABLE IF NOT EXISTS damage_report
+(
+    id               SERIAL PRIMARY KEY,
+    condition        book_stock_condition NOT NULL,
+    notes            TEXT                 NOT NULL,
+    reported_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    fk_book_stock_id INTEGER REFERENCES book_stock (id) ON DELETE CASCADE,
+    fk_loan_id       INTEGER REFERENCES loan (id) ON DELETE SET NULL,
+    fk_reporter_id   INTEGER REFERENCES user_account (id) ON DELETE SET NULL
+);
+
+-- Fine Reason Enum Type
+CREATE TYPE fine_reason AS ENUM ('damage', 'overdue');
+
+-- Fine Table
+CREATE TABLE IF NOT EXISTS fine
+(
+    id                  SERIAL PRIMARY KEY,
+    amount              NUMERIC(10, 2) NOT NULL CHECK ( amount > 0 ),
+    reason              fine_reason    NOT NULL,
+    issued_at           TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    paid_at             TIMESTAMP,
+    fk_user_id          INTEGER REFERENCES user_account (id) ON DELETE CASCADE,
+    fk_loan_id          INTEGER REFERENCES loan (id) ON DELETE SET NULL,
+    fk_damage_report_id INTEGER REFERENCES damage_report (id) ON DELETE SET NULL
+);
+
+-- ===========================
+-- 7. Book Stock History Table
+-- ===========================
+
+-- Book Stock History Table (append-only record of every status change of a copy)
+CREATE TABLE IF NOT EXISTS book_stock_history
+(
+    id               SERIAL PRIMARY KEY,
+    old_status       book_stock_status,
+    new_status       book_stock_status NOT NULL,
+    reason           TEXT,
+    changed_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    fk_book_stock_id INTEGER REFERENCES book_stock (id) ON DELETE CASCADE,
+    fk_actor_id      INTEGER REFERENCES user_account (id) ON DELETE SET NULL,
+    fk_loan_id       INTEGER REFERENCES loan (id) ON DELETE SET NULL
+);
+
+-- Trigger to keep the history append-only, only allowing foreign keys to be cleared by ON DELETE SET NULL
+CREATE OR REPLACE FUNCTION prevent_book_stock_history_update()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF (NEW.id, NEW.old_status, NEW.new_status, NEW.reason, NEW.changed_at, NEW.fk_book_stock_id)
+        IS DISTINCT FROM (OLD.id, OLD.old_status, OLD.new_status, OLD.reason, OLD.changed_at, OLD.fk_book_stock_id)
+        OR (NEW.fk_actor_id IS DISTINCT FROM OLD.fk_actor_id AND NEW.fk_actor_id IS NOT NULL)
+        OR (NEW.fk_loan_id IS DISTINCT FROM OLD.fk_loan_id AND NEW.fk_loan_id IS NOT NULL) THEN
+        RAISE EXCEPTION 'book_stock_history is append-only.';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER before_book_stock_history_update
+    BEFORE UPDATE
+    ON book_stock_history
+    FOR EACH ROW
+EXECUTE FUNCTION prevent_book_stock_history_update();
+
+-- ===========================
+-- 8. Inventory Tables
+-- ===========================
+
+-- Inventory Session Status Enum Type
+CREATE TYPE inventory_session_status AS ENUM ('open', 'closed');
+
+-- Inventory Session Table (a shelf count of a branch, optionally restricted to one section)
+CREATE TABLE IF NOT EXISTS inventory_session
+(
+    id           SERIAL PRIMARY KEY,
+    branch       VARCHAR(100) NOT NULL,
+    section      VARCHAR(100),
+    status       inventory_session_status DEFAULT 'open',
+    opened_at    TIMESTAMP                DEFAULT CURRENT_TIMESTAMP,
+    closed_at    TIMESTAMP,
+    applied_at   TIMESTAMP,
+    fk_opened_by INTEGER REFERENCES user_account (id) ON DELETE SET NULL,
+    fk_closed_by INTEGER REFERENCES user_account (id) ON DELETE SET NULL
+);
+
+-- Inventory Scan Table (copy codes read by the scanner, matched against book_stock.code when reconciling)
+CREATE TABLE IF NOT EXISTS inventory_scan
+(
+    id            SERIAL PRIMARY KEY,
+    code          INTEGER NOT NULL,
+    scanned_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    fk_session_id INTEGER REFERENCES inventory_session (id) ON DELETE CASCADE,
+    UNIQUE (fk_session_id, code)
+);
+
+-- Inventory Discrepancy Kind Enum Type
+CREATE TYPE inventory_discrepancy_kind AS ENUM ('not_scanned', 'scanned_missing', 'scanned_borrowed', 'unknown_code');
+
+-- Inventory Discrepancy Table (the reconciliation frozen when a session is closed, so the report and its later
+-- application only cover the copies as they were at close)
+CREATE TABLE IF NOT EXISTS inventory_discrepancy
+(
+    id               SERIAL PRIMARY KEY,
+    kind             inventory_discrepancy_kind NOT NULL,
+    code             INTEGER                    NOT NULL,
+    stock_status     book_stock_status,
+    fk_session_id    INTEGER REFERENCES inventory_session (id) ON DELETE CASCADE,
+    fk_book_stock_id INTEGER REFERENCES book_stock (id) ON DELETE CASCADE,
+    UNIQUE (fk_session_id, kind, code)
+);
+
+-- ===========================
+-- 9. Acquisition Tables
+-- ===========================
+
+-- Purchase Suggestion Status Enum Type
+CREATE TYPE purchase_suggestion_status AS ENUM ('pending', 'ordered', 'rejected');
+
+-- Purchase Suggestion Table (titles patrons would like the library to buy)
+CREATE TABLE IF NOT EXISTS purchase_suggestion
+(
+    id          SERIAL PRIMARY KEY,
+    title       VARCHAR(200) NOT NULL,
+    author_name VARCHAR(100),
+    notes       TEXT,
+    status      purchase_suggestion_status DEFAULT 'pending',
+    created_at  TIMESTAMP                  DEFAULT CURRENT_TIMESTAMP,
+    fk_user_id  INTEGER REFERENCES user_account (id) ON DELETE SET NULL,
+    fk_book_id  INTEGER REFERENCES book (id) ON DELETE SET NULL
+);
+
+-- Purchase Order Status Enum Type
+CREATE TYPE purchase_order_status AS ENUM ('ordered', 'partially_received', 'received', 'cancelled');
+
+-- Purchase Order Table
+CREATE TABLE IF NOT EXISTS purchase_order
+(
+    id                SERIAL PRIMARY KEY,
+    vendor            VARCHAR(150)   NOT NULL,
+    quantity          INTEGER        NOT NULL CHECK ( quantity > 0 ),
+    received_quantity INTEGER        NOT NULL DEFAULT 0 CHECK ( received_quantity <= quantity ),
+    unit_cost         NUMERIC(10, 2) NOT NULL CHECK ( unit_cost >= 0 ),
+    status            purchase_order_status   DEFAULT 'ordered',
+    ordered_at        TIMESTAMP               DEFAULT CURRENT_TIMESTAMP,
+    received_at       TIMESTAMP,
+    fk_book_id        INTEGER        NOT NULL REFERENCES book (id) ON DELETE RESTRICT,
+    fk_suggestion_id  INTEGER REFERENCES purchase_suggestion (id) ON DELETE SET NULL,
+    fk_admin_id       INTEGER REFERENCES user_account (id) ON DELETE SET NULL
+);
+
+-- ===========================
+-- 10. Password Reset Tables
+-- ===========================
+
+-- Password Reset Token Table. Only the SHA-256 of the token is stored
+CREATE TABLE IF NOT EXISTS password_reset_token
+(
+    id         SERIAL PRIMARY KEY,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP       NOT NULL,
+    used_at    TIMESTAMP,
+    fk_user_id INTEGER         NOT NULL REFERENCES user_account (id) ON DELETE CASCADE
+);
+
+-- ===========================
+-- 11. User Session Tables
+-- ===========================
+
+-- User Session Table. Each login creates a session; the access token carries the session id (jti) and the
+-- refresh token is rotated on every use. Only SHA-256 hashes of refresh tokens are stored
+CREATE TABLE IF NOT EXISTS user_session
+(
+    id                  SERIAL PRIMARY KEY,
+    refresh_token_hash  CHAR(64) UNIQUE NOT NULL,
+    -- Hash of the last rotated refresh token, used to detect reuse of a stolen token
+    previous_token_hash CHAR(64),
+    ip_address          VARCHAR(45),
+    user_agent          VARCHAR(255),
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at          TIMESTAMP       NOT NULL,
+    revoked_at          TIMESTAMP,
+    fk_user_id          INTEGER         NOT NULL REFERENCES user_account (id) ON DELETE CASCADE
+);
+
+-- ===========================
+-- 12. Login Lockout Tables
+-- ===========================
+
+-- Login Lockout Scope Enum Type
+CREATE TYPE login_lockout_scope AS ENUM ('account', 'ip');
+
+-- Login Lockout Table. Tracks failed login attempts per account (email) and per client IP
+CREATE TABLE IF NOT EXISTS login_lockout
+(
+    id             SERIAL PRIMARY KEY,
+    scope          login_lockout_scope NOT NULL,
+    subject        VARCHAR(150)        NOT NULL,
+    failed_count   INTEGER             NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    locked_until   TIMESTAMP,
+    UNIQUE (scope, subject)
+);
+
+-- ===========================
+-- 13. Two-Factor Authentication Tables
+-- ===========================
+
+-- User TOTP Table. The secret is only active after the user confirms a code (confirmed_at)
+CREATE TABLE IF NOT EXISTS user_totp
+(
+    fk_user_id     INTEGER PRIMARY KEY REFERENCES user_account (id) ON DELETE CASCADE,
+    secret         VARCHAR(64) NOT NULL,
+    confirmed_at   TIMESTAMP,
+    -- Last accepted time step, so the same code cannot be used twice
+    last_used_step BIGINT,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- TOTP Recovery Code Table. Only the SHA-256 of each single-use code is stored
+CREATE TABLE IF NOT EXISTS totp_recovery_code
+(
+    id         SERIAL PRIMARY KEY,
+    code_hash  CHAR(64) NOT NULL,
+    used_at    TIMESTAMP,
+    fk_user_id INTEGER  NOT NULL REFERENCES user_account (id) ON DELETE CASCADE,
+    UNIQUE (fk_user_id, code_hash)
+);
+
+-- ===========================
+-- 14. Permission Tables
+-- ===========================
+
+-- Permission Table
+CREATE TABLE IF NOT EXISTS permission
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(50) UNIQUE NOT NULL,
+    description VARCHAR(255)       NOT NULL
+);
+
+-- Role Permission Table
+CREATE TABLE IF NOT EXISTS role_permission
+(
+    fk_role_id       INTEGER NOT NULL REFERENCES account_role (id) ON DELETE CASCADE,
+    fk_permission_id INTEGER NOT NULL REFERENCES permission (id) ON DELETE CASCADE,
+    PRIMARY KEY (fk_role_id, fk_permission_id)
+);
+
+INSERT INTO permission (name, description)
+VALUES ('books:write', 'Create and update books'),
+       ('books:delete', 'Delete books'),
+       ('stock:read', 'View book copies, their history and damage reports'),
+       ('stock:write', 'Add, update and remove book copies'),
+       ('reservations:read', 'View all reservations'),
+       ('reservations:write', 'Create reservations on behalf of patrons'),
+       ('loans:read', 'View all loans'),
+       ('loans:checkout', 'Check out loans'),
+       ('loans:return', 'Return loans'),
+       ('users:read', 'View users and their reservations'),
+       ('users:read_cpf', 'View full user CPFs instead of masked ones'),
+       ('users:write', 'Register, activate and deactivate users and manage their security settings'),
+       ('users:delete', 'Delete users'),
+       ('users:privacy', 'Export and anonymize user personal data (LGPD requests)'),
+       ('inventory:manage', 'Run inventory sessions'),
+       ('acquisitions:manage', 'Manage purchase suggestions, orders and budget'),
+       ('reports:read', 'View collection reports'),
+       ('roles:manage', 'Manage roles and their permissions'),
+       ('patron_categories:manage', 'Manage patron categories and their borrowing policies'),
+       ('circulation_policy:manage', 'Edit the circulation policy rules'),
+       ('calendar:manage', 'Edit opening hours and closed dates'),
+       ('notifications:read', 'View the notification delivery log'),
+       ('webhooks:manage', 'Manage webhook endpoints and replay their deliveries'),
+       ('audit:read', 'View and verify the audit log of administrative actions')
+ON CONFLICT (name) DO NOTHING;
+
+-- The admin role has every permission
+INSERT INTO role_permission (fk_role_id, fk_permission_id)
+SELECT r.id, p.id
+FROM account_role r
+         CROSS JOIN permission p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- The librarian role runs the front desk: circulation, copies and inventory, but no user or catalog deletion
+INSERT INTO role_permission (fk_role_id, fk_permission_id)
+SELECT r.id, p.id
+FROM account_role r
+         JOIN permission p ON p.name IN ('stock:read', 'stock:write', 'reservations:read', 'reservations:write',
+                                         'loans:read', 'loans:checkout', 'loans:return', 'users:read',
+                                         'inventory:manage', 'reports:read')
+WHERE r.name = 'librarian'
+ON CONFLICT DO NOTHING;
+
+-- ===========================
+-- 15. Circulation Policy Tables
+-- ===========================
+
+-- Library-wide circulation rules. Per-category limits (items, loan lengths, renewals, fine rates) live in
+-- patron_category; this single row holds the rules shared by every patron.
+CREATE TABLE IF NOT EXISTS circulation_policy
+(
+    id                              INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    -- How many open hours (see opening_hours) a pending reservation waits to be collected
+    reservation_window_hours        INTEGER   NOT NULL DEFAULT 24 CHECK (reservation_window_hours > 0),
+    -- Patrons with overdue loans cannot reserve or check out
+    block_on_overdue_loans          BOOLEAN   NOT NULL DEFAULT TRUE,
+    -- Patrons whose unpaid fines exceed this amount cannot reserve or check out (NULL means no limit)
+    max_unpaid_fines                NUMERIC(10, 2) CHECK (max_unpaid_fines >= 0),
+    -- Patrons with an expired membership cannot reserve or check out
+    require_valid_membership        BOOLEAN   NOT NULL DEFAULT TRUE,
+    -- Loans cannot be renewed while other patrons have pending reservations for the book
+    renewal_blocked_by_reservations BOOLEAN   NOT NULL DEFAULT TRUE,
+    updated_at                      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    fk_updated_by                   INTEGER REFERENCES user_account (id) ON DELETE SET NULL
+);
+
+INSERT INTO circulation_policy (id)
+VALUES (1)
+ON CONFLICT (id) DO NOTHING;
+
+-- ===========================
+-- 16. Calendar Tables
+-- ===========================
+
+-- Weekly opening hours. Weekdays follow EXTRACT(DOW): 0 is Sunday. A weekday without a row is closed.
+CREATE TABLE IF NOT EXISTS opening_hours
+(
+    weekday   SMALLINT PRIMARY KEY CHECK (weekday BETWEEN 0 AND 6),
+    opens_at  TIME NOT NULL,
+    closes_at TIME NOT NULL CHECK (closes_at > opens_at)
+);
+
+INSERT INTO opening_hours (weekday, opens_at, closes_at)
+VALUES (1, '08:00', '20:00'),
+       (2, '08:00', '20:00'),
+       (3, '08:00', '20:00'),
+       (4, '08:00', '20:00'),
+       (5, '08:00', '20:00'),
+       (6, '08:00', '12:00')
+ON CONFLICT (weekday) DO NOTHING;
+
+-- Holidays and other dates the library is closed
+CREATE TABLE IF NOT EXISTS closed_date
+(
+    date   DATE PRIMARY KEY,
+    reason VARCHAR(100) NOT NULL
+);
+
+-- Whether the library opens on the given date
+CREATE OR REPLACE FUNCTION is_open_day(check_date DATE)
+    RETURNS BOOLEAN AS
+$$
+SELECT EXISTS (SELECT 1 FROM opening_hours WHERE weekday = EXTRACT(DOW FROM check_date))
+           AND NOT EXISTS (SELECT 1 FROM closed_date WHERE date = check_date);
+$$ LANGUAGE sql STABLE;
+
+-- Moves a timestamp forward, keeping its time, until it falls on an open day. Used for loan due dates.
+CREATE OR REPLACE FUNCTION next_open_day(start_at TIMESTAMP)
+    RETURNS TIMESTAMP AS
+$$
+DECLARE
+    result TIMESTAMP := start_at;
+BEGIN
+    -- Without any opening hours every day would be closed, so the date is kept
+    IF NOT EXISTS (SELECT 1 FROM opening_hours) THEN
+        RETURN start_at;
+    END IF;
+
+    FOR i IN 1..366
+        LOOP
+            EXIT WHEN is_open_day(result::DATE);
+            result := result + INTERVAL '1 day';
+        END LOOP;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Adds a number of open hours to a timestamp, skipping the time the library is closed. Used for the reservation
+-- pickup window.
+CREATE OR REPLACE FUNCTION add_open_hours(start_at TIMESTAMP, open_hours INTEGER)
+    RETURNS TIMESTAMP AS
+$$
+DECLARE
+    remaining    INTERVAL := MAKE_INTERVAL(hours => open_hours);
+    current_day  DATE     := start_at::DATE;
+    window_start TIMESTAMP;
+    window_end   TIMESTAMP;
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM opening_hours) THEN
+        RETURN start_at + remaining;
+    END IF;
+
+    FOR i IN 1..366
+        LOOP
+            IF is_open_day(current_day) THEN
+                SELECT current_day + opens_at, current_day + closes_at
+                INTO window_start, window_end
+                FROM opening_hours
+                WHERE weekday = EXTRACT(DOW FROM current_day);
+
+                window_start := GREATEST(window_start, start_at);
+                IF window_end > window_start THEN
+                    IF window_start + remaining <= window_end THEN
+                        RETURN window_start + remaining;
+                    END IF;
+                    remaining := remaining - (window_end - window_start);
+                END IF;
+            END IF;
+            current_day := current_day + 1;
+        END LOOP;
+    -- Only reached if the library stays closed for a whole year
+    RETURN start_at + MAKE_INTERVAL(hours => open_hours);
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- ===========================
+-- 17. Notification Tables
+-- ===========================
+
+-- Notification Channel Enum Type
+CREATE TYPE notification_channel AS ENUM ('email', 'webhook', 'inbox');
+
+-- Notification Delivery Status Enum Type
+CREATE TYPE notification_delivery_status AS ENUM ('pending', 'sent', 'failed');
+
+-- Per-user notification preferences. Users without a row get the defaults below.
+CREATE TABLE IF NOT EXISTS notification_preference
+(
+    fk_user_id    INTEGER PRIMARY KEY REFERENCES user_account (id) ON DELETE CASCADE,
+    locale        VARCHAR(5) NOT NULL DEFAULT 'pt-BR' CHECK (locale IN ('pt-BR', 'en')),
+    email_enabled BOOLEAN    NOT NULL DEFAULT TRUE,
+    inbox_enabled BOOLEAN    NOT NULL DEFAULT TRUE,
+    -- The webhook channel is only used when a URL is set
+    webhook_url   VARCHAR(500)
+);
+
+-- In-app inbox
+CREATE TABLE IF NOT EXISTS notification
+(
+    id         SERIAL PRIMARY KEY,
+    event      VARCHAR(50)  NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    body       TEXT         NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    read_at    TIMESTAMP,
+    fk_user_id INTEGER      NOT NULL REFERENCES user_account (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_user ON notification (fk_user_id, created_at DESC);
+
+-- Delivery log. Each notification is rendered once and stored here per channel, so failed deliveries can be retried
+-- with the same content. The dedupe key keeps scheduled reminders from being sent twice.
+CREATE TABLE IF NOT EXISTS notification_delivery
+(
+    id              SERIAL PRIMARY KEY,
+    event           VARCHAR(50)                  NOT NULL,
+    channel         notification_channel         NOT NULL,
+    dedupe_key      VARCHAR(100)                 NOT NULL,
+    recipient       VARCHAR(500)                 NOT NULL,
+    subject         VARCHAR(255)                 NOT NULL,
+    body            TEXT                         NOT NULL,
+    status          notification_delivery_status NOT NULL DEFAULT 'pending',
+    attempts        INTEGER                      NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP,
+    created_at      TIMESTAMP                             DEFAULT CURRENT_TIMESTAMP,
+    sent_at         TIMESTAMP,
+    fk_user_id      INTEGER                      NOT NULL REFERENCES user_account (id) ON DELETE CASCADE,
+    UNIQUE (dedupe_key, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_retry ON notification_delivery (next_attempt_at)
+    WHERE status = 'pending';
+
+-- ===========================
+-- 18. Webhook Tables
+-- ===========================
+
+-- Endpoints registered by admins. Each one receives the events it is subscribed to, signed with its secret.
+CREATE TABLE IF NOT EXISTS webhook_endpoint
+(
+    id          SERIAL PRIMARY KEY,
+    url         VARCHAR(500) NOT NULL,
+    description VARCHAR(255),
+    events      TEXT[]       NOT NULL CHECK (CARDINALITY(events) > 0),
+    secret      VARCHAR(64)  NOT NULL,
+    is_active   BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    fk_admin_id INTEGER      REFERENCES user_account (id) ON DELETE SET NULL
+);
+
+-- Webhook Delivery Status Enum Type
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'failed');
+
+-- Delivery log. The payload is stored so retries and replays send exactly the same event.
+CREATE TABLE IF NOT EXISTS webhook_delivery
+(
+    id              SERIAL PRIMARY KEY,
+    event_id        VARCHAR(64)             NOT NULL,
+    event           VARCHAR(50)             NOT NULL,
+    payload         JSONB                   NOT NULL,
+    status          webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts        INTEGER                 NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP,
+    created_at      TIMESTAMP                        DEFAULT CURRENT_TIMESTAMP,
+    delivered_at    TIMESTAMP,
+    fk_endpoint_id  INTEGER                 NOT NULL REFERENCES webhook_endpoint (id) ON DELETE CASCADE,
+    -- Outbox events are published at least once, so the same event may reach an endpoint twice
+    UNIQUE (event_id, fk_endpoint_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_endpoint ON webhook_delivery (fk_endpoint_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_retry ON webhook_delivery (next_attempt_at)
+    WHERE status = 'pending';
+
+-- ===========================
+-- 19. Outbox Tables
+-- ===========================
+
+-- Circulation events, written by the triggers below in the same transaction as the change that caused them and
+-- published by the API afterwards. Events stay here until every sink accepts them.
+CREATE TABLE IF NOT EXISTS outbox_event
+(
+    id              BIGSERIAL PRIMARY KEY,
+    event_id        UUID        NOT NULL DEFAULT gen_random_uuid(),
+    event           VARCHAR(50) NOT NULL,
+    payload         JSONB       NOT NULL,
+    created_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at    TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_event_unpublished ON outbox_event (next_attempt_at)
+    WHERE published_at IS NULL;
+
+CREATE OR REPLACE FUNCTION enqueue_outbox_event(event_name VARCHAR, event_payload JSONB)
+    RETURNS VOID AS
+$$
+BEGIN
+    INSERT INTO outbox_event (event, payload) VALUES (event_name, event_payload);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger to publish reservations made by patrons or staff. Walk-in checkouts create their reservation already
+-- collected and are published as loans only.
+CREATE OR REPLACE FUNCTION publish_reservation_created()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM enqueue_outbox_event('reservation.created', JSONB_BUILD_OBJECT(
+            'id', NEW.id,
+            'status', NEW.status,
+            'borrowed_days', NEW.borrowed_days,
+            'reserved_at', NEW.reserved_at,
+            'expires_at', NEW.expires_at,
+            'user_id', NEW.fk_user_id,
+            'book_id', NEW.fk_book_id,
+            'admin_id', NEW.fk_admin_id));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER reservation_created
+    AFTER INSERT
+    ON reservation
+    FOR EACH ROW
+    WHEN (NEW.status = 'pending')
+EXECUTE FUNCTION publish_reservation_created();
+
+-- Trigger to publish loans when they are created and when they are returned
+CREATE OR REPLACE FUNCTION publish_loan_event()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    event_name VARCHAR(50);
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        event_name := 'loan.created';
+    ELSIF NEW.status = 'returned' AND OLD.status != 'returned' THEN
+        event_name := 'loan.returned';
+    ELSE
+        RETURN NEW;
+    END IF;
+
+    PERFORM enqueue_outbox_event(event_name, JSONB_BUILD_OBJECT(
+            'id', NEW.id,
+            'status', NEW.status,
+            'loaned_at', NEW.loaned_at,
+            'return_by', NEW.return_by,
+            'returned_at', NEW.returned_at,
+            'renewal_count', NEW.renewal_count,
+            'user_id', r.fk_user_id,
+            'book_id', r.fk_book_id,
+            'book_stock_id', NEW.fk_book_stock_id,
+            'reservation_id', NEW.fk_reservation_id,
+            'admin_id', NEW.fk_admin_id))
+    FROM reservation r
+    WHERE r.id = NEW.fk_reservation_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER loan_event
+    AFTER INSERT OR UPDATE OF status
+    ON loan
+    FOR EACH ROW
+EXECUTE FUNCTION publish_loan_event();
+
+-- Trigger to publish copy status changes. Every change is recorded in book_stock_history, including the ones made
+-- by the loan_status_change trigger on returns; copies added to stock have no old status and are not published.
+CREATE OR REPLACE FUNCTION publish_stock_status_changed()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM enqueue_outbox_event('stock.status_changed', JSONB_BUILD_OBJECT(
+            'book_stock_id', NEW.fk_book_stock_id,
+            'old_status', NEW.old_status,
+            'new_status', NEW.new_status,
+            'reason', COALESCE(NEW.reason, ''),
+            'loan_id', NEW.fk_loan_id,
+            'actor_id', NEW.fk_actor_id));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stock_status_changed
+    AFTER INSERT
+    ON book_stock_history
+    FOR EACH ROW
+    WHEN (NEW.old_status IS NOT NULL AND NEW.old_status != NEW.new_status)
+EXECUTE FUNCTION publish_stock_status_changed();
+
+-- Trigger to publish user deactivations
+CREATE OR REPLACE FUNCTION publish_user_deactivated()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM enqueue_outbox_event('user.deactivated', JSONB_BUILD_OBJECT('user_id', NEW.id));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER user_deactivated
+    AFTER UPDATE OF is_active
+    ON user_account
+    FOR EACH ROW
+    WHEN (OLD.is_active AND NOT NEW.is_active)
+EXECUTE FUNCTION publish_user_deactivated();
+
+-- ===========================
+-- 20. Audit Tables
+-- ===========================
+
+-- Append-only trail of administrative actions. Each row stores the hash of the previous one, so editing or removing
+-- rows breaks the chain from that point on. The actor has no foreign key: the trail must outlive deleted users.
+-- Snapshots are JSON, not JSONB, so they are kept byte for byte as they were hashed.
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id          BIGSERIAL PRIMARY KEY,
+    actor_id    INTEGER     NOT NULL,
+    action      VARCHAR(50) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id   VARCHAR(50),
+    before      JSON,
+    after       JSON,
+    ip          VARCHAR(45) NOT NULL,
+    request_id  VARCHAR(100) NOT NULL,
+    created_at  TIMESTAMP   NOT NULL,
+    prev_hash   CHAR(64)    NOT NULL,
+    hash        CHAR(64)    NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_request ON audit_log (request_id);
+
+-- Trigger to keep the audit log append-only
+CREATE OR REPLACE FUNCTION prevent_audit_log_change()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only.';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER before_audit_log_change
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION prevent_audit_log_change();
+
+CREATE OR REPLACE TRIGGER before_audit_log_truncate
+    BEFORE TRUNCATE
+    ON audit_log
+    FOR EACH STATEMENT
+EXECUTE FUNCTION prevent_audit_log_change();
@@ -0,0 +1,18 @@
+package initializers
+
+import (
+	"go-api/mailer"
+	"log"
+)
+
+var Mailer mailer.Mailer
+
+// InitMailer inicializa o envio de emails. Sem SMTP_HOST definido, os emails são apenas escritos no log.
+func InitMailer() {
+	if SmtpHost == "" {
+		log.Println("SMTP_HOST environment variable not set, emails will only be logged")
+		Mailer = mailer.NewLogMailer()
+		return
+	}
+	Mailer = mailer.NewSMTPMailer(SmtpHost, SmtpPort, SmtpUsername, SmtpPassword, SmtpFrom)
+}
@@ -3,6 +3,8 @@ package initializers
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +18,28 @@ var JwtKey []byte
 // Port é a porta que irá atender as requisições HTTP
 var Port string
 
+// AppBaseURL é a URL pública da API, usada nos links enviados por email.
+var AppBaseURL string
+
+// Configuração do servidor SMTP. Se SmtpHost estiver vazio, os emails são apenas escritos no log.
+var (
+	SmtpHost     string
+	SmtpPort     string
+	SmtpUsername string
+	SmtpPassword string
+	SmtpFrom     string
+)
+
+// TrustedProxies são os proxies reversos autorizados a informar o IP do cliente no X-Forwarded-For. Vazio, o header é
+// ignorado e o IP é sempre o da conexão, para que o bloqueio de login por IP não possa ser contornado.
+var TrustedProxies []string
+
+// LoanReminderDays é a antecedência, em dias, do lembrete de devolução enviado aos leitores.
+var LoanReminderDays int
+
+// OutboxLogEvents indica se os eventos publicados pelo outbox também são escritos no log.
+var OutboxLogEvents bool
+
 // LoadEnv carrega as variáveis de ambiente necessárias.
 func LoadEnv() {
 	// Carrega as variáveis do arquivo .env se existir
@@ -42,4 +66,37 @@ func LoadEnv() {
 		Port = "8080"
 	}
 
+	AppBaseURL = strings.TrimSuffix(os.Getenv("APP_BASE_URL"), "/")
+	if AppBaseURL == "" {
+		AppBaseURL = "http://localhost:" + Port
+	}
+
+	SmtpHost = os.Getenv("SMTP_HOST")
+	SmtpPort = os.Getenv("SMTP_PORT")
+	if SmtpPort == "" {
+		SmtpPort = "587"
+	}
+	SmtpUsername = os.Getenv("SMTP_USERNAME")
+	SmtpPassword = os.Getenv("SMTP_PASSWORD")
+	SmtpFrom = os.Getenv("SMTP_FROM")
+	if SmtpFrom == "" {
+		SmtpFrom = "no-reply@localhost"
+	}
+
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			TrustedProxies = append(TrustedProxies, proxy)
+		}
+	}
+
+	LoanReminderDays = 2
+	if days := os.Getenv("LOAN_REMINDER_DAYS"); days != "" {
+		parsed, err := strconv.Atoi(days)
+		if err != nil || parsed < 1 {
+			log.Fatal("LOAN_REMINDER_DAYS must be a positive integer")
+		}
+		LoanReminderDays = parsed
+	}
+
+	OutboxLogEvents = os.Getenv("OUTBOX_LOG_EVENTS") == "true"
 }
@@ -0,0 +1,122 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// smtpTimeout limita a conexão e toda a conversa com o servidor SMTP, para que um servidor lento não prenda o envio.
+const smtpTimeout = 30 * time.Second
+
+// Message é um email em texto puro.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envia emails. Implementações podem ser trocadas sem alterar os casos de uso.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+type smtpMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer cria um Mailer que envia emails por um servidor SMTP. Se username estiver vazio, o envio é feito
+// sem autenticação, o que permite testar contra um servidor local (ex.: MailHog ou Mailpit).
+func NewSMTPMailer(host, port, username, password, from string) Mailer {
+	return &smtpMailer{host: host, port: port, username: username, password: password, from: from}
+}
+
+func (sm *smtpMailer) Send(msg Message) error {
+	if err := sm.send(msg); err != nil {
+		return fmt.Errorf("error sending email to %s: %v", msg.To, err)
+	}
+	return nil
+}
+
+// send faz o mesmo que smtp.SendMail, mas com smtpTimeout na conexão e no restante da conversa.
+func (sm *smtpMailer) send(msg Message) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(sm.host, sm.port), smtpTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, sm.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: sm.host}); err != nil {
+			return err
+		}
+	}
+	if sm.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", sm.username, sm.password, sm.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sm.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(sm.build(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (sm *smtpMailer) build(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + sm.from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	// O assunto é codificado conforme a RFC 2047, já que os headers só podem ter ASCII
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+type logMailer struct{}
+
+// NewLogMailer cria um Mailer que apenas escreve os emails no log. Útil em desenvolvimento, quando não há um
+// servidor SMTP configurado.
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+func (lm *logMailer) Send(msg Message) error {
+	log.Printf("email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
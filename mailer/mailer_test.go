@@ -0,0 +1,165 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// smtpStub é um servidor SMTP mínimo que aceita um único email e guarda o envelope e os dados recebidos.
+type smtpStub struct {
+	listener net.Listener
+	from     string
+	rcpt     []string
+	data     string
+	done     chan error
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stub := &smtpStub{listener: listener, done: make(chan error, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go func() { stub.done <- stub.serve() }()
+	return stub
+}
+
+func (s *smtpStub) addr() (string, string) {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host, port
+}
+
+func (s *smtpStub) serve() error {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) error {
+		_, err := conn.Write([]byte(line + "\r\n"))
+		return err
+	}
+	if err := reply("220 localhost ESMTP stub"); err != nil {
+		return err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			err = reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = strings.TrimPrefix(cmd, "MAIL FROM:")
+			err = reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.rcpt = append(s.rcpt, strings.TrimPrefix(cmd, "RCPT TO:"))
+			err = reply("250 OK")
+		case cmd == "DATA":
+			if err := reply("354 end data with <CR><LF>.<CR><LF>"); err != nil {
+				return err
+			}
+			// Lê os dados crus, sem normalizar as quebras de linha, para conferir o CRLF
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return err
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.data = data.String()
+			err = reply("250 OK")
+		case cmd == "QUIT":
+			return reply("221 bye")
+		default:
+			err = reply("502 command not implemented")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	tests := []struct {
+		name        string
+		subject     string
+		body        string
+		wantSubject string
+		wantBody    string
+	}{
+		{
+			name:        "ascii subject",
+			subject:     "Email verification",
+			body:        "Hello,\nclick the link below.",
+			wantSubject: "Subject: Email verification\r\n",
+			wantBody:    "Hello,\r\nclick the link below.",
+		},
+		{
+			name:        "non-ascii subject",
+			subject:     "Redefinição de senha",
+			body:        "Olá,\nuse o link abaixo.",
+			wantSubject: "Subject: =?utf-8?q?Redefini=C3=A7=C3=A3o_de_senha?=\r\n",
+			wantBody:    "Olá,\r\nuse o link abaixo.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newSMTPStub(t)
+			host, port := stub.addr()
+			m := NewSMTPMailer(host, port, "", "", "library@example.com")
+
+			err := m.Send(Message{To: "reader@example.com", Subject: tt.subject, Body: tt.body})
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if err := <-stub.done; err != nil {
+				t.Fatalf("smtp stub: %v", err)
+			}
+
+			if stub.from != "<library@example.com>" {
+				t.Errorf("MAIL FROM = %q, want %q", stub.from, "<library@example.com>")
+			}
+			if len(stub.rcpt) != 1 || stub.rcpt[0] != "<reader@example.com>" {
+				t.Errorf("RCPT TO = %q, want [<reader@example.com>]", stub.rcpt)
+			}
+
+			headers, body, found := strings.Cut(stub.data, "\r\n\r\n")
+			if !found {
+				t.Fatalf("message has no header/body separator: %q", stub.data)
+			}
+			for _, want := range []string{
+				"From: library@example.com\r\n",
+				"To: reader@example.com\r\n",
+				tt.wantSubject,
+				"MIME-Version: 1.0\r\n",
+				"Content-Type: text/plain; charset=\"utf-8\"\r\n",
+				"Content-Transfer-Encoding: 8bit\r\n",
+			} {
+				if !strings.Contains(headers+"\r\n", want) {
+					t.Errorf("headers missing %q in %q", want, headers)
+				}
+			}
+			// O smtp.SendMail termina os dados com CRLF antes do ponto final
+			if body != tt.wantBody+"\r\n" {
+				t.Errorf("body = %q, want %q", body, tt.wantBody+"\r\n")
+			}
+			if strings.Contains(strings.ReplaceAll(stub.data, "\r\n", ""), "\n") {
+				t.Errorf("message has bare LF: %q", stub.data)
+			}
+		})
+	}
+}
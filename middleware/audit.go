@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"encoding/json"
+	"go-api/initializers"
+	"go-api/model"
+	"go-api/repository"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditTargetIdKey é a chave do contexto em que o handler informa o id do alvo quando ele só é conhecido depois da
+// ação, como nas criações.
+const AuditTargetIdKey = "auditTargetId"
+
+// AuditSkipKey é a chave do contexto em que o handler indica que a ação não precisa ser registrada, nas rotas usadas
+// tanto por funcionários quanto pelo próprio leitor, como a renovação de um empréstimo.
+const AuditSkipKey = "auditSkip"
+
+// AuditTarget descreve a entidade afetada por uma ação auditada. Param é o parâmetro da rota com o id do alvo (vazio
+// quando o alvo é único, como as regras de circulação) e Load busca o alvo para as fotografias de antes e depois.
+// Com FromHandler, o id é lido de AuditTargetIdKey depois do handler e só há a fotografia de depois.
+type AuditTarget struct {
+	Type        string
+	Param       string
+	FromHandler bool
+	Load        func(id int) (any, error)
+}
+
+// IdFromHandler retorna o alvo com o id informado pelo handler em AuditTargetIdKey, para as ações em que ele não está
+// na rota.
+func (t AuditTarget) IdFromHandler() AuditTarget {
+	t.Param = ""
+	t.FromHandler = true
+	return t
+}
+
+// Audit registra a ação na trilha de auditoria quando o handler termina com sucesso. O alvo é carregado antes e depois
+// do handler para guardar o que mudou, junto com quem fez a ação, o IP e o id da requisição. Deve vir depois da
+// autenticação, para que o autor da ação seja conhecido.
+func Audit(action string, target AuditTarget) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetId := 0
+		if target.Param != "" {
+			id, err := strconv.Atoi(c.Param(target.Param))
+			if err != nil {
+				// Id inválido: o handler responde com o erro e não há o que auditar
+				c.Next()
+				return
+			}
+			targetId = id
+		}
+
+		var before json.RawMessage
+		if !target.FromHandler {
+			before = auditSnapshot(target, targetId)
+		}
+		c.Next()
+		if c.Writer.Status() >= http.StatusBadRequest || c.GetBool(AuditSkipKey) {
+			return
+		}
+		hasTargetId := target.Param != ""
+		if target.FromHandler {
+			targetId = c.GetInt(AuditTargetIdKey)
+			hasTargetId = targetId != 0
+		}
+
+		actorId, _ := strconv.Atoi(c.GetString("userId"))
+		entry := model.AuditEntry{
+			ActorId:    actorId,
+			Action:     action,
+			TargetType: target.Type,
+			Before:     before,
+			After:      auditSnapshot(target, targetId),
+			Ip:         c.ClientIP(),
+			RequestId:  c.GetString("requestId"),
+		}
+		if hasTargetId {
+			entry.TargetId = strconv.Itoa(targetId)
+		}
+
+		// A resposta já foi enviada, então uma falha aqui só pode ser registrada no log
+		if _, err := repository.NewAuditRepository(initializers.DB).CreateEntry(entry); err != nil {
+			log.Printf("audit: failed to record %s on %s %s: %v", action, target.Type, entry.TargetId, err)
+		}
+	}
+}
+
+// auditSnapshot retorna o alvo em JSON, ou nil se ele não existe.
+func auditSnapshot(target AuditTarget, id int) json.RawMessage {
+	value, err := target.Load(id)
+	if err != nil {
+		return nil
+	}
+	snapshot, err := json.Marshal(value)
+	if err != nil || string(snapshot) == "null" {
+		return nil
+	}
+	return snapshot
+}
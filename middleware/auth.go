@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"go-api/initializers"
+	"go-api/repository"
 	"go-api/utils"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -24,33 +28,97 @@ func JWTAuthMiddleware(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	// Verifica se a sessão do token não foi encerrada e se o usuário ainda está ativo
+	userId, errUser := strconv.Atoi(claims.Issuer)
+	sessionId, errSession := strconv.Atoi(claims.ID)
+	if errUser != nil || errSession != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	active, err := repository.NewSessionRepository(initializers.DB).IsSessionActive(sessionId, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+	if !active {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has expired or was revoked, please log in again"})
+		c.Abort()
+		return
+	}
 	// Define as informações do usuário no contexto
 	c.Set("userId", claims.Issuer)
 	c.Set("role", claims.Subject)
+	c.Set("sessionId", claims.ID)
+	if claims.ExpiresAt != nil {
+		c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+	}
 	c.Next()
 }
 
-// RoleRequired verifica se o usuário possui a role necessária para acessar o recurso.
-func RoleRequired(role string) gin.HandlerFunc {
+// SessionActive confere de novo se a sessão do token da requisição continua ativa e se o usuário ainda está ativo.
+// Conexões longas, como o stream da caixa de entrada, só passam pelo JWTAuthMiddleware ao abrir e devem chamá-la
+// periodicamente.
+func SessionActive(c *gin.Context) (bool, error) {
+	userId, errUser := strconv.Atoi(c.GetString("userId"))
+	sessionId, errSession := strconv.Atoi(c.GetString("sessionId"))
+	if errUser != nil || errSession != nil {
+		return false, nil
+	}
+	return repository.NewSessionRepository(initializers.DB).IsSessionActive(sessionId, userId)
+}
+
+// PermissionRequired verifica se a role atual do usuário possui a permissão necessária para acessar o recurso.
+// As permissões são lidas do banco de dados, então alterações nas roles valem imediatamente.
+func PermissionRequired(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Obtém a role do usuário a partir do contexto
-		userRole, existe := c.Get("role")
-		if !existe {
-			// Se a role não for encontrada, retorna status Unauthorized
+		permissions, err := userPermissions(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if permissions == nil {
+			// Se o usuário não foi autenticado, retorna status Unauthorized
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
 
-		// Compara a role do usuário com a role necessária
-		if userRole != role {
-			// Se as roles não coincidirem, retorna status Forbidden
+		if !slices.Contains(permissions, permission) {
+			// Se a role não tiver a permissão, retorna status Forbidden
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 			c.Abort()
 			return
 		}
 
-		// Se a role for válida, permite que a requisição prossiga
 		c.Next()
 	}
 }
+
+// HasPermission informa se o usuário autenticado na requisição possui a permissão.
+func HasPermission(c *gin.Context, permission string) bool {
+	permissions, err := userPermissions(c)
+	return err == nil && slices.Contains(permissions, permission)
+}
+
+// userPermissions carrega as permissões do usuário autenticado uma única vez por requisição. Retorna nil se a
+// requisição não passou pelo JWTAuthMiddleware.
+func userPermissions(c *gin.Context) ([]string, error) {
+	if permissions, exists := c.Get("permissions"); exists {
+		return permissions.([]string), nil
+	}
+
+	userId, err := strconv.Atoi(c.GetString("userId"))
+	if err != nil {
+		return nil, nil
+	}
+
+	permissions, err := repository.NewRoleRepository(initializers.DB).GetUserPermissions(userId)
+	if err != nil {
+		return nil, err
+	}
+	c.Set("permissions", permissions)
+	return permissions, nil
+}
@@ -11,8 +11,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		// Configuração dos cabeçalhos CORS
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*") 
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS") 
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization") 
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length") 
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-Request-Id") 
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, X-Request-Id") 
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == http.MethodOptions {
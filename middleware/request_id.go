@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+const requestIdHeader = "X-Request-Id"
+
+// maxRequestIdLength é o tamanho máximo aceito para um X-Request-Id enviado pelo cliente, o mesmo da coluna
+// request_id da trilha de auditoria.
+const maxRequestIdLength = 100
+
+// RequestIDMiddleware identifica cada requisição. O X-Request-Id enviado pelo cliente (ou por um proxy) é mantido;
+// sem ele, é gerado um UUID. O id fica no contexto como 'requestId' e volta no header da resposta.
+func RequestIDMiddleware(c *gin.Context) {
+	requestId := c.GetHeader(requestIdHeader)
+	if !validRequestId(requestId) {
+		requestId = newRequestId()
+	}
+
+	c.Set("requestId", requestId)
+	c.Writer.Header().Set(requestIdHeader, requestId)
+	c.Next()
+}
+
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, r := range requestId {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestId gera um UUID versão 4.
+func newRequestId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
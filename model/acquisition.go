@@ -0,0 +1,60 @@
+package model
+
+import (
+	"go-api/model/user"
+	"time"
+)
+
+type PurchaseSuggestionStatus string
+
+const (
+	PurchaseSuggestionPending  PurchaseSuggestionStatus = "pending"
+	PurchaseSuggestionOrdered  PurchaseSuggestionStatus = "ordered"
+	PurchaseSuggestionRejected PurchaseSuggestionStatus = "rejected"
+)
+
+// PurchaseSuggestion é um título que um usuário sugeriu para compra.
+type PurchaseSuggestion struct {
+	Id          int                      `json:"id"`
+	Title       string                   `json:"title"`
+	AuthorName  string                   `json:"author_name"`
+	Notes       string                   `json:"notes"`
+	Status      PurchaseSuggestionStatus `json:"status"`
+	CreatedAt   time.Time                `json:"created_at"`
+	UserAccount *user.Account            `json:"user_account,omitempty"`
+	BookId      *int                     `json:"book_id"`
+}
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderOrdered           PurchaseOrderStatus = "ordered"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+	PurchaseOrderCancelled         PurchaseOrderStatus = "cancelled"
+)
+
+// PurchaseOrder é um pedido de compra de exemplares de um livro junto a um fornecedor.
+type PurchaseOrder struct {
+	Id               int                 `json:"id"`
+	Vendor           string              `json:"vendor"`
+	Quantity         int                 `json:"quantity"`
+	ReceivedQuantity int                 `json:"received_quantity"`
+	UnitCost         float64             `json:"unit_cost"`
+	TotalCost        float64             `json:"total_cost"`
+	Status           PurchaseOrderStatus `json:"status"`
+	OrderedAt        time.Time           `json:"ordered_at"`
+	ReceivedAt       *time.Time          `json:"received_at"`
+	Book             Book                `json:"book"`
+	SuggestionId     *int                `json:"suggestion_id"`
+	AdminAccount     *user.Account       `json:"admin_account,omitempty"`
+}
+
+// AcquisitionBudget totaliza os gastos com pedidos de compra em um período.
+type AcquisitionBudget struct {
+	Period        string  `json:"period"`
+	OrderCount    int     `json:"order_count"`
+	OrderedCopies int     `json:"ordered_copies"`
+	OrderedTotal  float64 `json:"ordered_total"`
+	ReceivedTotal float64 `json:"received_total"`
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go-api/model/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditGenesisHash é o PrevHash do primeiro registro da trilha de auditoria.
+var AuditGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// AuditEntry é um registro da trilha de auditoria das ações administrativas. Before e After são as fotografias do
+// alvo antes e depois da ação; ficam vazias quando o alvo não existia (criação) ou deixou de existir (exclusão).
+type AuditEntry struct {
+	Id         int64           `json:"id"`
+	ActorId    int             `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetId   string          `json:"target_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Ip         string          `json:"ip"`
+	RequestId  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// ComputeHash calcula o hash do registro encadeado ao hash do registro anterior (PrevHash). Alterar, remover ou
+// reordenar qualquer registro quebra a cadeia a partir dele.
+func (e AuditEntry) ComputeHash() string {
+	fields := []string{
+		e.PrevHash,
+		strconv.Itoa(e.ActorId),
+		e.Action,
+		e.TargetType,
+		e.TargetId,
+		string(e.Before),
+		string(e.After),
+		e.Ip,
+		e.RequestId,
+		e.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000"),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter são os filtros da consulta da trilha de auditoria. Campos vazios não filtram; From e To são datas
+// (AAAA-MM-DD) inclusivas.
+type AuditFilter struct {
+	ActorId    int
+	Action     string
+	TargetType string
+	TargetId   string
+	RequestId  string
+	From       string
+	To         string
+}
+
+// AuditVerification é o resultado da verificação da cadeia de hashes da trilha de auditoria.
+type AuditVerification struct {
+	Valid      bool   `json:"valid"`
+	Checked    int    `json:"checked"`
+	BrokenAtId *int64 `json:"broken_at_id,omitempty"`
+}
+
+// As fotografias de reservas e empréstimos trocam as contas do leitor e do funcionário por user.AccountSnapshot, para
+// que a trilha de auditoria não guarde dados pessoais.
+type reservationSnapshot struct {
+	*Reservation
+	UserAccount  *user.AccountSnapshot `json:"user_account,omitempty"`
+	AdminAccount *user.AccountSnapshot `json:"admin_account,omitempty"`
+}
+
+type loanSnapshot struct {
+	*Loan
+	UserAccount  *user.AccountSnapshot `json:"user_account,omitempty"`
+	AdminAccount *user.AccountSnapshot `json:"admin_account,omitempty"`
+}
+
+// NewReservationSnapshot monta a reserva registrada na trilha de auditoria.
+func NewReservationSnapshot(reservation *Reservation) any {
+	return reservationSnapshot{
+		Reservation:  reservation,
+		UserAccount:  user.NewAccountSnapshot(reservation.UserAccount),
+		AdminAccount: user.NewAccountSnapshot(reservation.AdminAccount),
+	}
+}
+
+// NewLoanSnapshot monta o empréstimo registrado na trilha de auditoria.
+func NewLoanSnapshot(loan *Loan) any {
+	return loanSnapshot{
+		Loan:         loan,
+		UserAccount:  user.NewAccountSnapshot(loan.UserAccount),
+		AdminAccount: user.NewAccountSnapshot(loan.AdminAccount),
+	}
+}
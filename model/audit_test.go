@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAuditEntryComputeHash(t *testing.T) {
+	entry := AuditEntry{
+		ActorId:    7,
+		Action:     "loan.finish",
+		TargetType: "loan",
+		TargetId:   "42",
+		Before:     json.RawMessage(`{"status":"borrowed"}`),
+		After:      json.RawMessage(`{"status":"returned"}`),
+		Ip:         "10.0.0.1",
+		RequestId:  "req-1",
+		CreatedAt:  time.Date(2026, 10, 19, 14, 32, 0, 123456789, time.UTC),
+		PrevHash:   AuditGenesisHash,
+	}
+	// Hash calculado de forma independente: SHA-256 dos campos separados por 0x1f, com o horário em microssegundos
+	const want = "4cb958285fcba3f1c781938aa1e10b2d60efbd3705254b10fbda5826f168918f"
+	if got := entry.ComputeHash(); got != want {
+		t.Fatalf("ComputeHash() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(e *AuditEntry)
+	}{
+		{"prev hash", func(e *AuditEntry) { e.PrevHash = want }},
+		{"actor", func(e *AuditEntry) { e.ActorId = 8 }},
+		{"action", func(e *AuditEntry) { e.Action = "loan.renew" }},
+		{"target type", func(e *AuditEntry) { e.TargetType = "reservation" }},
+		{"target id", func(e *AuditEntry) { e.TargetId = "43" }},
+		{"before", func(e *AuditEntry) { e.Before = json.RawMessage(`{"status":"late"}`) }},
+		{"after", func(e *AuditEntry) { e.After = nil }},
+		{"ip", func(e *AuditEntry) { e.Ip = "10.0.0.2" }},
+		{"request id", func(e *AuditEntry) { e.RequestId = "req-2" }},
+		{"created at", func(e *AuditEntry) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+		// Os campos são separados, então mover texto de um campo para o vizinho muda o hash
+		{"field boundary", func(e *AuditEntry) { e.Action, e.TargetType = "loan.finishloan", "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := entry
+			tt.modify(&modified)
+			if modified.ComputeHash() == want {
+				t.Errorf("ComputeHash() did not change when %s changed", tt.name)
+			}
+		})
+	}
+
+	t.Run("time zone and nanoseconds", func(t *testing.T) {
+		same := entry
+		// O banco guarda microssegundos; o fuso e os nanossegundos não entram no hash
+		same.CreatedAt = time.Date(2026, 10, 19, 11, 32, 0, 123456000, time.FixedZone("BRT", -3*60*60))
+		if got := same.ComputeHash(); got != want {
+			t.Errorf("ComputeHash() = %q, want %q", got, want)
+		}
+	})
+}
@@ -6,11 +6,36 @@ const (
 	BookStockAvailable BookStockStatus = "available"
 	BookStockBorrowed  BookStockStatus = "borrowed"
 	BookStockMissing   BookStockStatus = "missing"
+	BookStockDamaged   BookStockStatus = "damaged"
+	BookStockInRepair  BookStockStatus = "in_repair"
+	BookStockWithdrawn BookStockStatus = "withdrawn"
 )
 
+// BookStockCondition é o grau de conservação física de um exemplar.
+type BookStockCondition string
+
+const (
+	BookStockConditionNew  BookStockCondition = "new"
+	BookStockConditionGood BookStockCondition = "good"
+	BookStockConditionFair BookStockCondition = "fair"
+	BookStockConditionPoor BookStockCondition = "poor"
+)
+
+// IsValid verifica se a condição é um dos graus conhecidos.
+func (c BookStockCondition) IsValid() bool {
+	switch c {
+	case BookStockConditionNew, BookStockConditionGood, BookStockConditionFair, BookStockConditionPoor:
+		return true
+	}
+	return false
+}
+
 type BookStock struct {
-	Id     int             `json:"id"`
-	Status BookStockStatus `json:"status,omitempty"`
-	Code   int             `json:"code"`
-	BookId int             `json:"book_id"`
+	Id        int                `json:"id"`
+	Status    BookStockStatus    `json:"status,omitempty"`
+	Condition BookStockCondition `json:"condition,omitempty"`
+	Code      int                `json:"code"`
+	Branch    string             `json:"branch,omitempty"`
+	Section   string             `json:"section,omitempty"`
+	BookId    int                `json:"book_id"`
 }
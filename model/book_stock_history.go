@@ -0,0 +1,18 @@
+package model
+
+import (
+	"go-api/model/user"
+	"time"
+)
+
+// BookStockHistory é um registro imutável de uma mudança de status de um exemplar.
+type BookStockHistory struct {
+	Id          int              `json:"id"`
+	OldStatus   *BookStockStatus `json:"old_status"`
+	NewStatus   BookStockStatus  `json:"new_status"`
+	Reason      string           `json:"reason"`
+	ChangedAt   time.Time        `json:"changed_at"`
+	BookStockId int              `json:"book_stock_id"`
+	Actor       *user.Account    `json:"actor,omitempty"`
+	LoanId      *int             `json:"loan_id"`
+}
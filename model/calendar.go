@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// OpeningHours é o horário de funcionamento da biblioteca em um dia da semana. Weekday segue time.Weekday, com 0
+// para domingo. Os horários usam o formato "15:04".
+type OpeningHours struct {
+	Weekday  int    `json:"weekday"`
+	OpensAt  string `json:"opens_at"`
+	ClosesAt string `json:"closes_at"`
+}
+
+// ClosedDate é um dia em que a biblioteca não abre, como um feriado. Prazos de devolução que caem nesses dias são
+// adiados e multas por atraso não correm neles.
+type ClosedDate struct {
+	Date   time.Time `json:"date"`
+	Reason string    `json:"reason"`
+}
@@ -0,0 +1,33 @@
+package model
+
+// CheckInAction é o que o balcão de devolução deve fazer com o exemplar devolvido.
+type CheckInAction string
+
+const (
+	// CheckInReshelve indica que o exemplar volta à sua estante.
+	CheckInReshelve CheckInAction = "reshelve"
+	// CheckInHold indica que o exemplar deve ser separado para o próximo leitor da fila de reservas.
+	CheckInHold CheckInAction = "hold"
+	// CheckInTransfer indica que o exemplar pertence a outra unidade e deve ser enviado para ela.
+	CheckInTransfer CheckInAction = "transfer"
+	// CheckInRepair indica que o exemplar foi devolvido danificado e saiu de circulação.
+	CheckInRepair CheckInAction = "repair"
+)
+
+// CheckInRequest é a devolução de um exemplar lido pelo código da etiqueta no balcão. Branch é a unidade onde a
+// devolução acontece, usada para decidir se o exemplar precisa ser transferido.
+type CheckInRequest struct {
+	Code   int    `json:"code" binding:"required"`
+	Branch string `json:"branch"`
+	LoanReturnRequest
+}
+
+// CheckInResult descreve a devolução registrada, as multas cobradas e a próxima ação para o exemplar.
+type CheckInResult struct {
+	Loan        *Loan         `json:"loan"`
+	Fines       []Fine        `json:"fines"`
+	Action      CheckInAction `json:"action"`
+	Reservation *Reservation  `json:"reservation,omitempty"`
+	Branch      string        `json:"branch,omitempty"`
+	Section     string        `json:"section,omitempty"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// CirculationPolicy reúne as regras de circulação válidas para todos os leitores. Os limites de cada categoria de
+// leitor (itens, prazos, renovações e multas) ficam em user.PatronCategory.
+type CirculationPolicy struct {
+	ReservationWindowHours       int       `json:"reservation_window_hours"`
+	BlockOnOverdueLoans          bool      `json:"block_on_overdue_loans"`
+	MaxUnpaidFines               *float64  `json:"max_unpaid_fines"`
+	RequireValidMembership       bool      `json:"require_valid_membership"`
+	RenewalBlockedByReservations bool      `json:"renewal_blocked_by_reservations"`
+	UpdatedAt                    time.Time `json:"updated_at"`
+	UpdatedBy                    *int      `json:"updated_by"`
+}
+
+// PolicyDenialCode identifica a regra de circulação que impediu uma operação.
+type PolicyDenialCode string
+
+const (
+	DenialUserInactive        PolicyDenialCode = "user_inactive"
+	DenialMembershipExpired   PolicyDenialCode = "membership_expired"
+	DenialLoanDaysNotAllowed  PolicyDenialCode = "loan_days_not_allowed"
+	DenialItemLimitReached    PolicyDenialCode = "item_limit_reached"
+	DenialOverdueLoans        PolicyDenialCode = "overdue_loans"
+	DenialUnpaidFines         PolicyDenialCode = "unpaid_fines"
+	DenialLoanNotBorrowed     PolicyDenialCode = "loan_not_borrowed"
+	DenialLoanOverdue         PolicyDenialCode = "loan_overdue"
+	DenialRenewalLimitReached PolicyDenialCode = "renewal_limit_reached"
+	DenialBookReserved        PolicyDenialCode = "book_reserved"
+)
+
+// PolicyDenial é o erro retornado quando uma regra de circulação impede a operação. Details traz os valores que
+// explicam a recusa, como o limite da categoria e a quantidade atual do leitor.
+type PolicyDenial struct {
+	Code    PolicyDenialCode `json:"code"`
+	Message string           `json:"error"`
+	Details map[string]any   `json:"details,omitempty"`
+}
+
+func (d *PolicyDenial) Error() string {
+	return d.Message
+}
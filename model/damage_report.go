@@ -0,0 +1,18 @@
+package model
+
+import (
+	"go-api/model/user"
+	"time"
+)
+
+// DamageReport registra um dano encontrado em um exemplar, normalmente na devolução de um empréstimo.
+type DamageReport struct {
+	Id          int                `json:"id"`
+	Condition   BookStockCondition `json:"condition"`
+	Notes       string             `json:"notes"`
+	ReportedAt  time.Time          `json:"reported_at"`
+	BookStockId int                `json:"book_stock_id"`
+	LoanId      *int               `json:"loan_id"`
+	Reporter    *user.Account      `json:"reporter,omitempty"`
+	Fine        *Fine              `json:"fine,omitempty"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type FineReason string
+
+const (
+	FineDamage  FineReason = "damage"
+	FineOverdue FineReason = "overdue"
+)
+
+// Fine é uma cobrança feita a um usuário, como a reparação de um exemplar danificado.
+type Fine struct {
+	Id             int        `json:"id"`
+	Amount         float64    `json:"amount"`
+	Reason         FineReason `json:"reason"`
+	IssuedAt       time.Time  `json:"issued_at"`
+	PaidAt         *time.Time `json:"paid_at"`
+	UserId         int        `json:"user_id"`
+	LoanId         *int       `json:"loan_id"`
+	DamageReportId *int       `json:"damage_report_id"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"go-api/model/user"
+	"time"
+)
+
+type InventorySessionStatus string
+
+const (
+	InventorySessionOpen   InventorySessionStatus = "open"
+	InventorySessionClosed InventorySessionStatus = "closed"
+)
+
+// InventoryDiscrepancyKind classifica as divergências da reconciliação gravadas quando a sessão é encerrada.
+type InventoryDiscrepancyKind string
+
+const (
+	InventoryNotScanned      InventoryDiscrepancyKind = "not_scanned"
+	InventoryScannedMissing  InventoryDiscrepancyKind = "scanned_missing"
+	InventoryScannedBorrowed InventoryDiscrepancyKind = "scanned_borrowed"
+	InventoryUnknownCode     InventoryDiscrepancyKind = "unknown_code"
+)
+
+// InventorySession é uma contagem de estante de uma filial, opcionalmente restrita a uma seção.
+type InventorySession struct {
+	Id        int                    `json:"id"`
+	Branch    string                 `json:"branch"`
+	Section   string                 `json:"section,omitempty"`
+	Status    InventorySessionStatus `json:"status"`
+	OpenedAt  time.Time              `json:"opened_at"`
+	ClosedAt  *time.Time             `json:"closed_at"`
+	AppliedAt *time.Time             `json:"applied_at"`
+	ScanCount int                    `json:"scan_count"`
+	OpenedBy  *user.Account          `json:"opened_by,omitempty"`
+	ClosedBy  *user.Account          `json:"closed_by,omitempty"`
+}
+
+// InventoryItem é um exemplar listado na reconciliação de uma sessão de inventário.
+type InventoryItem struct {
+	BookStockId int             `json:"book_stock_id"`
+	Code        int             `json:"code"`
+	Status      BookStockStatus `json:"status"`
+	Branch      string          `json:"branch,omitempty"`
+	Section     string          `json:"section,omitempty"`
+	BookId      int             `json:"book_id"`
+	BookTitle   string          `json:"book_title"`
+}
+
+// InventoryReport é a reconciliação entre os exemplares esperados na estante e os códigos lidos na sessão. Em sessões
+// encerradas, ela é a reconciliação do momento do encerramento; Status é o status do exemplar naquele momento.
+type InventoryReport struct {
+	Session            InventorySession `json:"session"`
+	ExpectedNotScanned []InventoryItem  `json:"expected_not_scanned"`
+	ScannedMissing     []InventoryItem  `json:"scanned_missing"`
+	ScannedBorrowed    []InventoryItem  `json:"scanned_borrowed"`
+	UnknownCodes       []int            `json:"unknown_codes"`
+}
+
+// InventoryApplyResult resume as mudanças de status aplicadas a partir de uma reconciliação.
+type InventoryApplyResult struct {
+	MarkedMissing   []int            `json:"marked_missing"`
+	MarkedAvailable []int            `json:"marked_available"`
+	Failed          []InventoryError `json:"failed"`
+}
+
+// InventoryError descreve um exemplar cuja mudança de status não pôde ser aplicada.
+type InventoryError struct {
+	BookStockId int    `json:"book_stock_id"`
+	Error       string `json:"error"`
+}
@@ -18,6 +18,7 @@ type Loan struct {
 	ReturnBy      time.Time     `json:"return_by" db:"return_by"`
 	ReturnedAt    *time.Time    `json:"returned_at" db:"returned_at"`
 	Status        LoanStatus    `json:"status" db:"status"`
+	RenewalCount  int           `json:"renewal_count" db:"renewal_count"`
 	UserAccount   *user.Account `json:"user_account,omitempty" db:"user_account"`
 	AdminAccount  *user.Account `json:"admin_account,omitempty" db:"admin_account"`
 	BookStock     *BookStock    `json:"book_stock,omitempty"`
@@ -37,3 +38,11 @@ type LoanUpdateRequest struct {
 	Status     LoanStatus `json:"status" binding:"required" db:"status"`
 	ReturnedAt *time.Time `json:"returned_at"`
 }
+
+// LoanReturnRequest descreve a condição do exemplar no momento da devolução e, opcionalmente, um dano
+// encontrado e o valor cobrado do usuário por ele.
+type LoanReturnRequest struct {
+	Condition    BookStockCondition `json:"condition"`
+	DamageNotes  string             `json:"damage_notes"`
+	DamageCharge float64            `json:"damage_charge"`
+}
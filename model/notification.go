@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// NotificationEvent é o acontecimento que gera uma notificação para o leitor.
+type NotificationEvent string
+
+const (
+	EventReservationReady    NotificationEvent = "reservation_ready"
+	EventReservationExpiring NotificationEvent = "reservation_expiring"
+	EventLoanDue             NotificationEvent = "loan_due"
+	EventFineIssued          NotificationEvent = "fine_issued"
+	EventHoldPositionChanged NotificationEvent = "hold_position_changed"
+)
+
+// NotificationChannel é um meio de entrega das notificações.
+type NotificationChannel string
+
+const (
+	ChannelEmail   NotificationChannel = "email"
+	ChannelWebhook NotificationChannel = "webhook"
+	ChannelInbox   NotificationChannel = "inbox"
+)
+
+type NotificationDeliveryStatus string
+
+const (
+	DeliveryPending NotificationDeliveryStatus = "pending"
+	DeliverySent    NotificationDeliveryStatus = "sent"
+	DeliveryFailed  NotificationDeliveryStatus = "failed"
+)
+
+// NotificationPreferences são as escolhas do leitor sobre como receber notificações. Sem WebhookUrl, o canal de
+// webhook fica desativado.
+type NotificationPreferences struct {
+	Locale       string  `json:"locale"`
+	EmailEnabled bool    `json:"email_enabled"`
+	InboxEnabled bool    `json:"inbox_enabled"`
+	WebhookUrl   *string `json:"webhook_url"`
+}
+
+// NotificationDelivery é o registro de uma tentativa de entrega de notificação por um canal. Entregas pendentes são
+// enviadas, ou reenviadas, a partir de NextAttemptAt.
+type NotificationDelivery struct {
+	Id            int                        `json:"id"`
+	Event         NotificationEvent          `json:"event"`
+	Channel       NotificationChannel        `json:"channel"`
+	UserId        int                        `json:"user_id"`
+	Recipient     string                     `json:"recipient"`
+	Subject       string                     `json:"subject"`
+	Body          string                     `json:"body"`
+	Status        NotificationDeliveryStatus `json:"status"`
+	Attempts      int                        `json:"attempts"`
+	LastError     *string                    `json:"last_error"`
+	NextAttemptAt *time.Time                 `json:"next_attempt_at"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	SentAt        *time.Time                 `json:"sent_at"`
+}
+
+// Notification é uma mensagem da caixa de entrada do leitor no aplicativo.
+type Notification struct {
+	Id        int               `json:"id"`
+	Event     NotificationEvent `json:"event"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	CreatedAt time.Time         `json:"created_at"`
+	ReadAt    *time.Time        `json:"read_at"`
+}
+
+// NotificationReminder é um lembrete agendado ainda não enviado: um empréstimo perto do vencimento ou uma reserva
+// perto de expirar. Due é o prazo do empréstimo ou da reserva.
+type NotificationReminder struct {
+	Event     NotificationEvent
+	UserId    int
+	BookTitle string
+	Due       time.Time
+	DedupeKey string
+}
+
+// HoldPosition é a posição de uma reserva pendente na fila do seu livro, começando em 1.
+type HoldPosition struct {
+	ReservationId int
+	UserId        int
+	BookTitle     string
+	Position      int
+}
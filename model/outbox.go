@@ -0,0 +1,22 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent é um evento da circulação gravado no outbox na mesma transação da mudança que o causou. Payload são
+// os dados do evento, montados pelo banco de dados.
+type OutboxEvent struct {
+	Id         int64
+	EventId    string
+	Event      WebhookEvent
+	Payload    json.RawMessage
+	OccurredAt time.Time
+	Attempts   int
+}
+
+// Envelope retorna o corpo publicado nos destinos do outbox.
+func (e OutboxEvent) Envelope() WebhookPayload {
+	return WebhookPayload{Id: e.EventId, Event: e.Event, OccurredAt: e.OccurredAt, Data: e.Payload}
+}
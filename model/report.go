@@ -0,0 +1,158 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CSVRow é implementado pelas linhas de relatório que podem ser exportadas em CSV.
+type CSVRow interface {
+	CSVHeader() []string
+	CSVRecord() []string
+}
+
+// UnusedCopyReport é um exemplar sem empréstimos no período analisado.
+type UnusedCopyReport struct {
+	BookStockId  int             `json:"book_stock_id"`
+	Code         int             `json:"code"`
+	Status       BookStockStatus `json:"status"`
+	Branch       string          `json:"branch,omitempty"`
+	Section      string          `json:"section,omitempty"`
+	BookId       int             `json:"book_id"`
+	BookTitle    string          `json:"book_title"`
+	LastLoanedAt *time.Time      `json:"last_loaned_at"`
+}
+
+func (r UnusedCopyReport) CSVHeader() []string {
+	return []string{"book_stock_id", "code", "status", "branch", "section", "book_id", "book_title", "last_loaned_at"}
+}
+
+func (r UnusedCopyReport) CSVRecord() []string {
+	return []string{
+		strconv.Itoa(r.BookStockId), strconv.Itoa(r.Code), string(r.Status), r.Branch, r.Section,
+		strconv.Itoa(r.BookId), r.BookTitle, formatCSVTime(r.LastLoanedAt),
+	}
+}
+
+// UnusedTitleReport é um livro sem empréstimos de nenhum de seus exemplares no período analisado.
+type UnusedTitleReport struct {
+	BookId       int        `json:"book_id"`
+	BookTitle    string     `json:"book_title"`
+	AuthorName   string     `json:"author_name"`
+	CopyCount    int        `json:"copy_count"`
+	LastLoanedAt *time.Time `json:"last_loaned_at"`
+}
+
+func (r UnusedTitleReport) CSVHeader() []string {
+	return []string{"book_id", "book_title", "author_name", "copy_count", "last_loaned_at"}
+}
+
+func (r UnusedTitleReport) CSVRecord() []string {
+	return []string{
+		strconv.Itoa(r.BookId), r.BookTitle, r.AuthorName, strconv.Itoa(r.CopyCount), formatCSVTime(r.LastLoanedAt),
+	}
+}
+
+// TitleCirculationReport é a quantidade de empréstimos de um livro.
+type TitleCirculationReport struct {
+	BookId    int    `json:"book_id"`
+	BookTitle string `json:"book_title"`
+	LoanCount int    `json:"loan_count"`
+}
+
+func (r TitleCirculationReport) CSVHeader() []string {
+	return []string{"book_id", "book_title", "loan_count"}
+}
+
+func (r TitleCirculationReport) CSVRecord() []string {
+	return []string{strconv.Itoa(r.BookId), r.BookTitle, strconv.Itoa(r.LoanCount)}
+}
+
+// GenreCirculationReport é a quantidade de empréstimos de livros de um gênero.
+type GenreCirculationReport struct {
+	GenreId   int    `json:"genre_id"`
+	GenreName string `json:"genre_name"`
+	LoanCount int    `json:"loan_count"`
+}
+
+func (r GenreCirculationReport) CSVHeader() []string {
+	return []string{"genre_id", "genre_name", "loan_count"}
+}
+
+func (r GenreCirculationReport) CSVRecord() []string {
+	return []string{strconv.Itoa(r.GenreId), r.GenreName, strconv.Itoa(r.LoanCount)}
+}
+
+// DamagedCopyReport é um exemplar com danos registrados.
+type DamagedCopyReport struct {
+	BookStockId    int                `json:"book_stock_id"`
+	Code           int                `json:"code"`
+	Status         BookStockStatus    `json:"status"`
+	Condition      BookStockCondition `json:"condition"`
+	BookId         int                `json:"book_id"`
+	BookTitle      string             `json:"book_title"`
+	DamageCount    int                `json:"damage_count"`
+	LastReportedAt time.Time          `json:"last_reported_at"`
+}
+
+func (r DamagedCopyReport) CSVHeader() []string {
+	return []string{"book_stock_id", "code", "status", "condition", "book_id", "book_title", "damage_count", "last_reported_at"}
+}
+
+func (r DamagedCopyReport) CSVRecord() []string {
+	return []string{
+		strconv.Itoa(r.BookStockId), strconv.Itoa(r.Code), string(r.Status), string(r.Condition),
+		strconv.Itoa(r.BookId), r.BookTitle, strconv.Itoa(r.DamageCount), formatCSVTime(&r.LastReportedAt),
+	}
+}
+
+// WaitlistReport compara as reservas pendentes de um livro com a quantidade de exemplares em circulação.
+type WaitlistReport struct {
+	BookId              int      `json:"book_id"`
+	BookTitle           string   `json:"book_title"`
+	CopyCount           int      `json:"copy_count"`
+	PendingReservations int      `json:"pending_reservations"`
+	Ratio               *float64 `json:"ratio"`
+}
+
+func (r WaitlistReport) CSVHeader() []string {
+	return []string{"book_id", "book_title", "copy_count", "pending_reservations", "ratio"}
+}
+
+func (r WaitlistReport) CSVRecord() []string {
+	ratio := ""
+	if r.Ratio != nil {
+		ratio = fmt.Sprintf("%.2f", *r.Ratio)
+	}
+	return []string{
+		strconv.Itoa(r.BookId), r.BookTitle, strconv.Itoa(r.CopyCount), strconv.Itoa(r.PendingReservations), ratio,
+	}
+}
+
+// ExpiringMembershipReport é um usuário ativo cuja carteirinha vence no período analisado.
+type ExpiringMembershipReport struct {
+	UserId    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	ExpiresOn time.Time `json:"expires_on"`
+	DaysLeft  int       `json:"days_left"`
+}
+
+func (r ExpiringMembershipReport) CSVHeader() []string {
+	return []string{"user_id", "name", "email", "phone", "expires_on", "days_left"}
+}
+
+func (r ExpiringMembershipReport) CSVRecord() []string {
+	return []string{
+		strconv.Itoa(r.UserId), r.Name, r.Email, r.Phone, r.ExpiresOn.Format(time.DateOnly), strconv.Itoa(r.DaysLeft),
+	}
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
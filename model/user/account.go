@@ -1,12 +1,121 @@
 package user
 
+import "time"
+
+// AccountStatus indica se o email da conta já foi verificado.
+type AccountStatus string
+
+const (
+	AccountPendingVerification AccountStatus = "pending_verification"
+	AccountVerified            AccountStatus = "verified"
+)
+
+// Account é a conta como armazenada no banco. O hash da senha e o cpf nunca são serializados; as respostas da API
+// devem usar AccountResponse.
 type Account struct {
-	Id           int         `json:"id"`
-	Name         string      `json:"name"`
-	Cpf          string      `json:"cpf"`
-	Phone        string      `json:"phone"`
-	Email        string      `json:"email"`
-	PasswordHash string      `json:"password_hash"`
-	AccountRole  AccountRole `json:"account_role"`
-	IsActive     bool        `json:"is_active"`
+	Id           int           `json:"id"`
+	Name         string        `json:"name"`
+	Cpf          string        `json:"-"`
+	Phone        string        `json:"phone"`
+	Email        string        `json:"email"`
+	PendingEmail *string       `json:"pending_email,omitempty"`
+	PasswordHash string        `json:"-"`
+	AccountRole  AccountRole   `json:"account_role"`
+	Status       AccountStatus `json:"status"`
+	IsActive     bool          `json:"is_active"`
+	AnonymizedAt *time.Time    `json:"anonymized_at,omitempty"`
+	Membership   Membership    `json:"membership"`
+	// PatronCategory só é carregada nas consultas de usuários, não nas contas associadas a outros objetos
+	PatronCategory *PatronCategory `json:"patron_category,omitempty"`
+}
+
+// Membership é a validade da carteirinha do usuário, renovada anualmente. Expired é calculado pelo banco de dados.
+type Membership struct {
+	StartedOn time.Time `json:"started_on"`
+	ExpiresOn time.Time `json:"expires_on"`
+	Expired   bool      `json:"expired"`
+}
+
+// AccountResponse é a conta retornada pela API, sem credenciais.
+type AccountResponse struct {
+	Id             int             `json:"id"`
+	Name           string          `json:"name"`
+	Cpf            string          `json:"cpf"`
+	Phone          string          `json:"phone"`
+	Email          string          `json:"email"`
+	PendingEmail   *string         `json:"pending_email,omitempty"`
+	AccountRole    AccountRole     `json:"account_role"`
+	Status         AccountStatus   `json:"status"`
+	IsActive       bool            `json:"is_active"`
+	AnonymizedAt   *time.Time      `json:"anonymized_at,omitempty"`
+	Membership     Membership      `json:"membership"`
+	PatronCategory *PatronCategory `json:"patron_category,omitempty"`
+}
+
+// NewAccountResponse monta a resposta da conta. O cpf só é retornado completo se fullCpf for verdadeiro.
+func NewAccountResponse(account *Account, fullCpf bool) *AccountResponse {
+	cpf := account.Cpf
+	if !fullCpf {
+		cpf = MaskCpf(cpf)
+	}
+
+	return &AccountResponse{
+		Id:             account.Id,
+		Name:           account.Name,
+		Cpf:            cpf,
+		Phone:          account.Phone,
+		Email:          account.Email,
+		PendingEmail:   account.PendingEmail,
+		AccountRole:    account.AccountRole,
+		Status:         account.Status,
+		IsActive:       account.IsActive,
+		AnonymizedAt:   account.AnonymizedAt,
+		Membership:     account.Membership,
+		PatronCategory: account.PatronCategory,
+	}
+}
+
+// AccountSnapshot é a conta como registrada na trilha de auditoria. A trilha é append-only, então ela guarda apenas o
+// que as ações administrativas alteram e nenhum dado pessoal, que não poderia ser apagado na anonimização.
+type AccountSnapshot struct {
+	Id             int             `json:"id"`
+	AccountRole    AccountRole     `json:"account_role"`
+	Status         AccountStatus   `json:"status"`
+	IsActive       bool            `json:"is_active"`
+	AnonymizedAt   *time.Time      `json:"anonymized_at,omitempty"`
+	Membership     Membership      `json:"membership"`
+	PatronCategory *PatronCategory `json:"patron_category,omitempty"`
+}
+
+// NewAccountSnapshot monta a conta registrada na trilha de auditoria. Retorna nil se a conta for nil.
+func NewAccountSnapshot(account *Account) *AccountSnapshot {
+	if account == nil {
+		return nil
+	}
+	return &AccountSnapshot{
+		Id:             account.Id,
+		AccountRole:    account.AccountRole,
+		Status:         account.Status,
+		IsActive:       account.IsActive,
+		AnonymizedAt:   account.AnonymizedAt,
+		Membership:     account.Membership,
+		PatronCategory: account.PatronCategory,
+	}
+}
+
+// NewAccountResponses monta a resposta de uma lista de contas.
+func NewAccountResponses(accounts []Account, fullCpf bool) []AccountResponse {
+	responses := make([]AccountResponse, 0, len(accounts))
+	for i := range accounts {
+		responses = append(responses, *NewAccountResponse(&accounts[i], fullCpf))
+	}
+	return responses
+}
+
+// MaskCpf mantém apenas os dígitos centrais do cpf, no formato "***.456.789-**".
+func MaskCpf(cpf string) string {
+	if len(cpf) != 11 {
+		return ""
+	}
+	return "***." + cpf[3:6] + "." + cpf[6:9] + "-**"
 }
@@ -1,6 +1,15 @@
 package user
 
+// Nomes das roles cadastradas em account_role.
+const (
+	RoleAdmin     = "admin"
+	RoleUser      = "user"
+	RoleLibrarian = "librarian"
+)
+
 type AccountRole struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id                int      `json:"id"`
+	Name              string   `json:"name"`
+	RequiresTwoFactor bool     `json:"requires_2fa"`
+	Permissions       []string `json:"permissions,omitempty"`
 }
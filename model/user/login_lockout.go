@@ -0,0 +1,32 @@
+package user
+
+import "time"
+
+// LoginLockoutScope indica se as tentativas de login foram contadas por conta (email) ou por IP.
+type LoginLockoutScope string
+
+const (
+	LockoutAccount LoginLockoutScope = "account"
+	LockoutIp      LoginLockoutScope = "ip"
+)
+
+// LoginLockout registra as tentativas de login malsucedidas de uma conta ou IP e o bloqueio temporário atual.
+type LoginLockout struct {
+	Id           int               `json:"id"`
+	Scope        LoginLockoutScope `json:"scope"`
+	Subject      string            `json:"subject"`
+	FailedCount  int               `json:"failed_count"`
+	LastFailedAt time.Time         `json:"last_failed_at"`
+	LockedUntil  *time.Time        `json:"locked_until"`
+	IsLocked     bool              `json:"is_locked"`
+}
+
+// NewLoginLockoutSnapshot monta o bloqueio registrado na trilha de auditoria. O assunto dos bloqueios por conta é o
+// email, um dado pessoal, e não é registrado.
+func NewLoginLockoutSnapshot(lockout *LoginLockout) LoginLockout {
+	snapshot := *lockout
+	if snapshot.Scope == LockoutAccount {
+		snapshot.Subject = ""
+	}
+	return snapshot
+}
@@ -0,0 +1,21 @@
+package user
+
+import "slices"
+
+// DefaultPatronCategory é a categoria das contas criadas sem uma categoria informada.
+const DefaultPatronCategory = "student"
+
+// PatronCategory é a política de empréstimo de uma categoria de leitores (estudante, servidor, visitante...).
+type PatronCategory struct {
+	Id            int     `json:"id"`
+	Name          string  `json:"name"`
+	MaxItems      int     `json:"max_items"`
+	LoanDays      []int64 `json:"loan_days"`
+	MaxRenewals   int     `json:"max_renewals"`
+	DailyFineRate float64 `json:"daily_fine_rate"`
+}
+
+// AllowsLoanDays informa se a categoria permite empréstimos com essa duração.
+func (pc *PatronCategory) AllowsLoanDays(days int) bool {
+	return slices.Contains(pc.LoanDays, int64(days))
+}
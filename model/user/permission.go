@@ -0,0 +1,35 @@
+package user
+
+// Permissões verificadas pelas rotas. Cada role recebe um conjunto delas na tabela role_permission.
+const (
+	PermBooksWrite              = "books:write"
+	PermBooksDelete             = "books:delete"
+	PermStockRead               = "stock:read"
+	PermStockWrite              = "stock:write"
+	PermReservationsRead        = "reservations:read"
+	PermReservationsWrite       = "reservations:write"
+	PermLoansRead               = "loans:read"
+	PermLoansCheckout           = "loans:checkout"
+	PermLoansReturn             = "loans:return"
+	PermUsersRead               = "users:read"
+	PermUsersReadCpf            = "users:read_cpf"
+	PermUsersWrite              = "users:write"
+	PermUsersDelete             = "users:delete"
+	PermUsersPrivacy            = "users:privacy"
+	PermInventoryManage         = "inventory:manage"
+	PermAcquisitionsManage      = "acquisitions:manage"
+	PermReportsRead             = "reports:read"
+	PermRolesManage             = "roles:manage"
+	PermPatronCategoriesManage  = "patron_categories:manage"
+	PermCirculationPolicyManage = "circulation_policy:manage"
+	PermCalendarManage          = "calendar:manage"
+	PermNotificationsRead       = "notifications:read"
+	PermWebhooksManage          = "webhooks:manage"
+	PermAuditRead               = "audit:read"
+)
+
+type Permission struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,23 @@
+package user
+
+import "time"
+
+// Session é uma sessão de login. O token de acesso carrega o Id da sessão e o token de atualização é trocado a
+// cada uso.
+type Session struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"user_id"`
+	IpAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// AuthTokens é o par de tokens retornado no login e na atualização da sessão.
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
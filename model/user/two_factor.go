@@ -0,0 +1,30 @@
+package user
+
+import "time"
+
+// TOTP é o segredo de autenticação em dois fatores do usuário. Só passa a ser exigido no login depois de
+// confirmado com um código válido.
+type TOTP struct {
+	UserId       int        `json:"user_id"`
+	Secret       string     `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep *int64     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TOTPEnrollment é retornado no cadastro do autenticador. OtpauthURI é o conteúdo do QR code.
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// LoginResult é a resposta do login. Quando a conta exige o segundo fator, os tokens não são emitidos e o cliente
+// deve enviar o ChallengeToken junto com o código TOTP. RecoveryCodes só é preenchido quando o autenticador é
+// cadastrado durante o login.
+type LoginResult struct {
+	*AuthTokens
+	TwoFactorRequired  bool     `json:"two_factor_required,omitempty"`
+	EnrollmentRequired bool     `json:"enrollment_required,omitempty"`
+	ChallengeToken     string   `json:"challenge_token,omitempty"`
+	RecoveryCodes      []string `json:"recovery_codes,omitempty"`
+}
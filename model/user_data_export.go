@@ -0,0 +1,21 @@
+package model
+
+import (
+	"go-api/model/user"
+	"time"
+)
+
+// UserDataExport reúne os dados pessoais mantidos sobre um usuário, para atender pedidos de acesso da LGPD.
+type UserDataExport struct {
+	GeneratedAt         time.Time             `json:"generated_at"`
+	Account             *user.AccountResponse `json:"account"`
+	Reservations        []Reservation         `json:"reservations"`
+	Loans               []Loan                `json:"loans"`
+	Fines               []Fine                `json:"fines"`
+	Sessions            []user.Session        `json:"sessions"`
+	PurchaseSuggestions []PurchaseSuggestion  `json:"purchase_suggestions"`
+	// Preferências, caixa de entrada e registro das notificações enviadas ao usuário
+	NotificationPreferences *NotificationPreferences `json:"notification_preferences"`
+	Notifications           []Notification           `json:"notifications"`
+	NotificationDeliveries  []NotificationDelivery   `json:"notification_deliveries"`
+}
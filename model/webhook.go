@@ -0,0 +1,79 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent é um acontecimento da circulação publicado pelo outbox e enviado aos webhooks inscritos nele.
+type WebhookEvent string
+
+const (
+	WebhookReservationCreated WebhookEvent = "reservation.created"
+	WebhookLoanCreated        WebhookEvent = "loan.created"
+	WebhookLoanReturned       WebhookEvent = "loan.returned"
+	WebhookStockStatusChanged WebhookEvent = "stock.status_changed"
+	WebhookUserDeactivated    WebhookEvent = "user.deactivated"
+)
+
+var webhookEvents = []WebhookEvent{
+	WebhookReservationCreated,
+	WebhookLoanCreated,
+	WebhookLoanReturned,
+	WebhookStockStatusChanged,
+	WebhookUserDeactivated,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	for _, event := range webhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookEndpoint é uma URL cadastrada para receber os eventos em que foi inscrita. Secret assina os envios e só é
+// retornado na criação.
+type WebhookEndpoint struct {
+	Id          int            `json:"id"`
+	Url         string         `json:"url" binding:"required"`
+	Description string         `json:"description"`
+	Events      []WebhookEvent `json:"events" binding:"required"`
+	IsActive    bool           `json:"is_active"`
+	Secret      string         `json:"secret,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// WebhookPayload é o corpo enviado aos webhooks e aos demais destinos do outbox. Id identifica o evento e se repete
+// nos reenvios, para que o destinatário possa descartar duplicados.
+type WebhookPayload struct {
+	Id         string       `json:"id"`
+	Event      WebhookEvent `json:"event"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Data       any          `json:"data"`
+}
+
+// WebhookDelivery é o envio de um evento a um webhook e o resultado da última tentativa.
+type WebhookDelivery struct {
+	Id             int                   `json:"id"`
+	EndpointId     int                   `json:"endpoint_id"`
+	EventId        string                `json:"event_id"`
+	Event          WebhookEvent          `json:"event"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus *int                  `json:"response_status"`
+	LastError      *string               `json:"last_error"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+}
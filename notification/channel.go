@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-api/mailer"
+	"go-api/model"
+	"go-api/webhook"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Message é uma notificação já renderizada, pronta para ser entregue a um destinatário. Recipient é o endereço no
+// canal: o email, a URL do webhook ou vazio para a caixa de entrada.
+type Message struct {
+	UserId    int
+	Event     model.NotificationEvent
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// Channel entrega notificações por um meio. Implementações podem ser trocadas sem alterar os casos de uso.
+type Channel interface {
+	Send(msg Message) error
+}
+
+type emailChannel struct {
+	mailer mailer.Mailer
+}
+
+// NewEmailChannel cria um Channel que envia as notificações por email.
+func NewEmailChannel(m mailer.Mailer) Channel {
+	return &emailChannel{mailer: m}
+}
+
+func (ec *emailChannel) Send(msg Message) error {
+	return ec.mailer.Send(mailer.Message{To: msg.Recipient, Subject: msg.Subject, Body: msg.Body})
+}
+
+type webhookChannel struct {
+	client *http.Client
+}
+
+// NewWebhookChannel cria um Channel que envia as notificações como JSON, por POST, para a URL cadastrada pelo leitor.
+// Como a URL vem do leitor, os envios só usam https e só se conectam a endereços públicos.
+func NewWebhookChannel(timeout time.Duration) Channel {
+	return &webhookChannel{client: webhook.NewPublicClient(timeout)}
+}
+
+func (wc *webhookChannel) Send(msg Message) error {
+	// URLs cadastradas antes da exigência de https são recusadas no envio
+	if u, err := url.Parse(msg.Recipient); err != nil || u.Scheme != "https" {
+		return fmt.Errorf("webhook url must use https")
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"event":   msg.Event,
+		"user_id": msg.UserId,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+		"sent_at": time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %v", err)
+	}
+
+	resp, err := wc.client.Post(msg.Recipient, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error sending webhook to %s: %v", msg.Recipient, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with status %d", msg.Recipient, resp.StatusCode)
+	}
+	return nil
+}
+
+// InboxStore grava as mensagens da caixa de entrada do leitor.
+type InboxStore interface {
+	CreateNotification(userId int, event model.NotificationEvent, title, body string) (*model.Notification, error)
+}
+
+type inboxChannel struct {
+	store InboxStore
+	hub   *Hub
+}
+
+// NewInboxChannel cria um Channel que grava as notificações na caixa de entrada do leitor no aplicativo e as repassa
+// às conexões abertas pelo hub.
+func NewInboxChannel(store InboxStore, hub *Hub) Channel {
+	return &inboxChannel{store: store, hub: hub}
+}
+
+func (ic *inboxChannel) Send(msg Message) error {
+	n, err := ic.store.CreateNotification(msg.UserId, msg.Event, msg.Subject, msg.Body)
+	if err != nil {
+		return err
+	}
+	ic.hub.Publish(msg.UserId, *n)
+	return nil
+}
@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"go-api/model"
+	"sync"
+)
+
+// subscriberBuffer é quantas mensagens cada assinante pode acumular antes de as próximas serem descartadas.
+const subscriberBuffer = 16
+
+// Hub repassa as mensagens gravadas na caixa de entrada para as conexões abertas do leitor, como os streams SSE.
+// Funciona apenas dentro do processo: com várias instâncias da API, cada uma avisa as suas próprias conexões.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan model.Notification]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[int]map[chan model.Notification]struct{})}
+}
+
+// Subscribe registra uma conexão do usuário e retorna o canal de mensagens e a função que cancela a assinatura.
+func (h *Hub) Subscribe(userId int) (<-chan model.Notification, func()) {
+	ch := make(chan model.Notification, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[userId] == nil {
+		h.subscribers[userId] = make(map[chan model.Notification]struct{})
+	}
+	h.subscribers[userId][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subscribers[userId], ch)
+			if len(h.subscribers[userId]) == 0 {
+				delete(h.subscribers, userId)
+			}
+			close(ch)
+		})
+	}
+}
+
+// Publish envia a mensagem a todas as conexões do usuário. Conexões que não estão consumindo as mensagens perdem a
+// mensagem em vez de bloquear quem publicou; a caixa de entrada continua disponível na listagem.
+func (h *Hub) Publish(userId int, n model.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[userId] {
+		select {
+		case ch <- n:
+		default:
+		}
+	}
+}
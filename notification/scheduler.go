@@ -0,0 +1,53 @@
+package notification
+
+import (
+	"log"
+	"time"
+)
+
+// Job é uma tarefa periódica do agendador, como o envio de lembretes ou o reenvio de entregas que falharam.
+type Job struct {
+	Name string
+	Run  func() error
+}
+
+// Scheduler executa tarefas periodicamente em segundo plano.
+type Scheduler struct {
+	interval time.Duration
+	jobs     []Job
+	stop     chan struct{}
+}
+
+func NewScheduler(interval time.Duration, jobs ...Job) *Scheduler {
+	return &Scheduler{interval: interval, jobs: jobs, stop: make(chan struct{})}
+}
+
+// Start executa as tarefas imediatamente e depois a cada intervalo, até Stop ser chamado. Erros são escritos no log
+// e não interrompem as próximas execuções.
+func (s *Scheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.runJobs()
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) Stop() {
+	close(s.stop)
+}
+
+func (s *Scheduler) runJobs() {
+	for _, job := range s.jobs {
+		if err := job.Run(); err != nil {
+			log.Printf("scheduled job %s failed: %v", job.Name, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package notification
+
+import (
+	"fmt"
+	"go-api/model"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	LocalePtBR    = "pt-BR"
+	LocaleEnglish = "en"
+)
+
+// Data são os valores disponíveis nos modelos de mensagem.
+type Data struct {
+	Name      string
+	BookTitle string
+	ExpiresAt time.Time
+	ReturnBy  time.Time
+	Amount    float64
+	Position  int
+}
+
+type messageTemplate struct {
+	subject string
+	body    string
+}
+
+// templates guarda os modelos de cada evento por idioma. Datas e valores são formatados com as funções datetime e
+// money do idioma.
+var templates = map[model.NotificationEvent]map[string]messageTemplate{
+	model.EventReservationReady: {
+		LocalePtBR: {
+			subject: "Sua reserva está pronta para retirada",
+			body: `Olá, {{.Name}}!
+
+O livro "{{.BookTitle}}" está separado para você. Retire-o na biblioteca até {{datetime .ExpiresAt}}, depois disso a reserva expira.`,
+		},
+		LocaleEnglish: {
+			subject: "Your reservation is ready for pickup",
+			body: `Hello, {{.Name}}!
+
+The book "{{.BookTitle}}" is being held for you. Pick it up at the library by {{datetime .ExpiresAt}}, after which the reservation expires.`,
+		},
+	},
+	model.EventReservationExpiring: {
+		LocalePtBR: {
+			subject: "Sua reserva está perto de expirar",
+			body: `Olá, {{.Name}}!
+
+A reserva do livro "{{.BookTitle}}" expira em {{datetime .ExpiresAt}}. Retire-o na biblioteca antes disso para não perdê-la.`,
+		},
+		LocaleEnglish: {
+			subject: "Your reservation is about to expire",
+			body: `Hello, {{.Name}}!
+
+Your reservation for "{{.BookTitle}}" expires on {{datetime .ExpiresAt}}. Pick it up at the library before then to keep it.`,
+		},
+	},
+	model.EventLoanDue: {
+		LocalePtBR: {
+			subject: "Devolução próxima",
+			body: `Olá, {{.Name}}!
+
+O empréstimo do livro "{{.BookTitle}}" vence em {{datetime .ReturnBy}}. Devolva ou renove o empréstimo até lá para evitar multas.`,
+		},
+		LocaleEnglish: {
+			subject: "Loan due soon",
+			body: `Hello, {{.Name}}!
+
+Your loan of "{{.BookTitle}}" is due on {{datetime .ReturnBy}}. Return or renew it by then to avoid fines.`,
+		},
+	},
+	model.EventFineIssued: {
+		LocalePtBR: {
+			subject: "Nova multa registrada",
+			body: `Olá, {{.Name}}!
+
+Foi registrada uma multa de {{money .Amount}} referente ao livro "{{.BookTitle}}". Enquanto houver multas em aberto, novas reservas e empréstimos podem ser recusados.`,
+		},
+		LocaleEnglish: {
+			subject: "New fine issued",
+			body: `Hello, {{.Name}}!
+
+A fine of {{money .Amount}} was issued for "{{.BookTitle}}". While fines are unpaid, new reservations and loans may be refused.`,
+		},
+	},
+	model.EventHoldPositionChanged: {
+		LocalePtBR: {
+			subject: "Sua posição na fila de reservas mudou",
+			body: `Olá, {{.Name}}!
+
+Sua reserva do livro "{{.BookTitle}}" agora está na posição {{.Position}} da fila.`,
+		},
+		LocaleEnglish: {
+			subject: "Your place in the reservation queue changed",
+			body: `Hello, {{.Name}}!
+
+Your reservation for "{{.BookTitle}}" is now number {{.Position}} in the queue.`,
+		},
+	},
+}
+
+var datetimeLayouts = map[string]string{
+	LocalePtBR:    "02/01/2006 15:04",
+	LocaleEnglish: "Jan 2, 2006 3:04 PM",
+}
+
+// moneyFormats formatam valores em reais no padrão de cada idioma.
+var moneyFormats = map[string]func(float64) string{
+	LocalePtBR:    func(v float64) string { return "R$ " + strings.Replace(fmt.Sprintf("%.2f", v), ".", ",", 1) },
+	LocaleEnglish: func(v float64) string { return fmt.Sprintf("R$%.2f", v) },
+}
+
+// IsSupportedLocale informa se há modelos de mensagem para o idioma.
+func IsSupportedLocale(locale string) bool {
+	_, ok := datetimeLayouts[locale]
+	return ok
+}
+
+// Render monta o assunto e o corpo da mensagem de um evento no idioma informado. Idiomas sem modelo usam pt-BR.
+func Render(event model.NotificationEvent, locale string, data Data) (string, string, error) {
+	byLocale, ok := templates[event]
+	if !ok {
+		return "", "", fmt.Errorf("no template for notification event '%s'", event)
+	}
+	if !IsSupportedLocale(locale) {
+		locale = LocalePtBR
+	}
+	tmpl := byLocale[locale]
+
+	layout := datetimeLayouts[locale]
+	funcs := template.FuncMap{
+		"datetime": func(t time.Time) string { return t.Format(layout) },
+		"money":    moneyFormats[locale],
+	}
+
+	body, err := template.New(string(event)).Funcs(funcs).Parse(tmpl.body)
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing template for '%s': %v", event, err)
+	}
+	var b strings.Builder
+	if err := body.Execute(&b, data); err != nil {
+		return "", "", fmt.Errorf("error rendering template for '%s': %v", event, err)
+	}
+	return tmpl.subject, b.String(), nil
+}
@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Msg é uma mensagem recebida por um assinante do Bus.
+type Msg struct {
+	Subject string
+	Data    []byte
+}
+
+// Subscription é a assinatura de um assunto no Bus.
+type Subscription struct {
+	bus *Bus
+	id  int
+}
+
+// Unsubscribe cancela a assinatura. Mensagens publicadas depois disso não são mais entregues.
+func (s *Subscription) Unsubscribe() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	delete(s.bus.subscriptions, s.id)
+	return nil
+}
+
+type subscription struct {
+	subject string
+	handler func(msg *Msg)
+}
+
+// Bus é uma implementação em memória da publicação e assinatura de assuntos do NATS, para quando não há um
+// servidor NATS. Assuntos são separados por pontos e as assinaturas aceitam os curingas "*", para um elemento, e
+// ">", para todos os elementos restantes. Os assinantes são chamados na goroutine de quem publicou.
+type Bus struct {
+	mu            sync.Mutex
+	nextId        int
+	subscriptions map[int]subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{subscriptions: make(map[int]subscription)}
+}
+
+func (b *Bus) Publish(subject string, data []byte) error {
+	if subject == "" || strings.ContainsAny(subject, "*> ") {
+		return fmt.Errorf("invalid subject '%s'", subject)
+	}
+
+	b.mu.Lock()
+	var handlers []func(msg *Msg)
+	for _, s := range b.subscriptions {
+		if subjectMatches(s.subject, subject) {
+			handlers = append(handlers, s.handler)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(&Msg{Subject: subject, Data: data})
+	}
+	return nil
+}
+
+func (b *Bus) Subscribe(subject string, handler func(msg *Msg)) (*Subscription, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("invalid subject '%s'", subject)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextId++
+	b.subscriptions[b.nextId] = subscription{subject: subject, handler: handler}
+	return &Subscription{bus: b, id: b.nextId}, nil
+}
+
+// subjectMatches informa se o assunto publicado corresponde ao assunto assinado, com os curingas do NATS.
+func subjectMatches(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
@@ -0,0 +1,85 @@
+package outbox
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestBusFansOutToEverySubscriber(t *testing.T) {
+	bus := NewBus()
+
+	var received []string
+	subscribe := func(name, subject string) *Subscription {
+		subscription, err := bus.Subscribe(subject, func(msg *Msg) {
+			received = append(received, name+":"+msg.Subject+":"+string(msg.Data))
+		})
+		if err != nil {
+			t.Fatalf("Subscribe(%s) error = %v", subject, err)
+		}
+		return subscription
+	}
+	subscribe("exact", "library.loan.created")
+	subscribe("second exact", "library.loan.created")
+	subscribe("single wildcard", "library.*.created")
+	subscribe("tail wildcard", "library.>")
+	subscribe("other subject", "library.reservation.created")
+	unsubscribed := subscribe("unsubscribed", "library.>")
+	if err := unsubscribed.Unsubscribe(); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+
+	if err := bus.Publish("library.loan.created", []byte("42")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	// A ordem entre os assinantes não é garantida
+	sort.Strings(received)
+	want := []string{
+		"exact:library.loan.created:42",
+		"second exact:library.loan.created:42",
+		"single wildcard:library.loan.created:42",
+		"tail wildcard:library.loan.created:42",
+	}
+	if len(received) != len(want) {
+		t.Fatalf("received = %q, want %q", received, want)
+	}
+	for i := range want {
+		if received[i] != want[i] {
+			t.Errorf("received[%d] = %q, want %q", i, received[i], want[i])
+		}
+	}
+}
+
+func TestBusPublishRejectsInvalidSubjects(t *testing.T) {
+	for _, subject := range []string{"", "library.*", "library.>", "library loan"} {
+		if err := NewBus().Publish(subject, nil); err == nil {
+			t.Errorf("Publish(%q) error = nil, want an invalid subject error", subject)
+		}
+	}
+}
+
+func TestSubjectMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{"library.loan.created", "library.loan.created", true},
+		{"library.loan.created", "library.loan.returned", false},
+		{"library.*.created", "library.loan.created", true},
+		{"library.*", "library.loan.created", false},
+		{"library.>", "library.loan.created", true},
+		{"library.>", "library", false},
+		{">", "library", true},
+		{"library.loan", "library.loan.created", false},
+		{"library.loan.created", "library.loan", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.subject, func(t *testing.T) {
+			if got := subjectMatches(tt.pattern, tt.subject); got != tt.want {
+				t.Errorf("subjectMatches(%q, %q) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+			}
+		})
+	}
+}
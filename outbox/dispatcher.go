@@ -0,0 +1,106 @@
+package outbox
+
+import (
+	"fmt"
+	"go-api/model"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sink é um destino dos eventos do outbox. Publish deve ser idempotente pelo id do evento: um evento pode ser
+// publicado mais de uma vez se o processo parar antes de marcá-lo como publicado ou se outro destino falhar.
+type Sink interface {
+	Publish(event model.OutboxEvent) error
+}
+
+// Store guarda os eventos do outbox.
+type Store interface {
+	ClaimEvents(limit int, lease time.Duration) (*[]model.OutboxEvent, error)
+	MarkEventPublished(id int64) error
+	MarkEventFailed(id int64, publishErr string) error
+}
+
+const (
+	// batchSize é quantos eventos são reservados por vez.
+	batchSize = 100
+	// lease é por quanto tempo os eventos reservados ficam indisponíveis para outras instâncias da API. Deve ser maior
+	// que o tempo de publicação de um lote.
+	lease = 2 * time.Minute
+)
+
+// Dispatcher publica periodicamente os eventos pendentes do outbox em todos os destinos, na ordem em que foram
+// gravados. Um evento só é marcado como publicado quando todos os destinos o aceitam; caso contrário, é publicado de
+// novo em todos eles mais tarde, o que garante a entrega pelo menos uma vez.
+type Dispatcher struct {
+	store    Store
+	sinks    map[string]Sink
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func NewDispatcher(store Store, sinks map[string]Sink, interval time.Duration) *Dispatcher {
+	return &Dispatcher{store: store, sinks: sinks, interval: interval, stop: make(chan struct{})}
+}
+
+// Start publica os eventos pendentes imediatamente e depois a cada intervalo, até Stop ser chamado.
+func (d *Dispatcher) Start() {
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := d.Dispatch(); err != nil {
+				log.Printf("outbox dispatch failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+}
+
+// Dispatch reserva e publica um lote de eventos pendentes. Retorna erro apenas se não conseguir ler ou atualizar o
+// outbox; falhas dos destinos ficam registradas no evento.
+func (d *Dispatcher) Dispatch() error {
+	events, err := d.store.ClaimEvents(batchSize, lease)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(*events, func(i, j int) bool { return (*events)[i].Id < (*events)[j].Id })
+	for _, event := range *events {
+		if err := d.publish(event); err != nil {
+			if err := d.store.MarkEventFailed(event.Id, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.store.MarkEventPublished(event.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish entrega o evento a todos os destinos e junta as falhas em um único erro.
+func (d *Dispatcher) publish(event model.OutboxEvent) error {
+	var failures []string
+	for name, sink := range d.sinks {
+		if err := sink.Publish(event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return fmt.Errorf("error publishing event %s: %s", event.EventId, strings.Join(failures, "; "))
+	}
+	return nil
+}
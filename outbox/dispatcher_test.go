@@ -0,0 +1,182 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"go-api/model"
+	"sync"
+	"testing"
+	"time"
+)
+
+// leaseStore é um Store em memória que reserva os eventos como o repositório: um evento reservado só volta a ser
+// entregue depois que a reserva vence, segundo o relógio now, que os testes avançam.
+type leaseStore struct {
+	mu          sync.Mutex
+	now         time.Time
+	events      []model.OutboxEvent
+	leasedUntil map[int64]time.Time
+	published   map[int64]bool
+	failures    map[int64]int
+}
+
+func newLeaseStore(n int) *leaseStore {
+	store := &leaseStore{
+		now:         time.Date(2026, 10, 19, 14, 0, 0, 0, time.UTC),
+		leasedUntil: map[int64]time.Time{},
+		published:   map[int64]bool{},
+		failures:    map[int64]int{},
+	}
+	for i := 1; i <= n; i++ {
+		store.events = append(store.events, model.OutboxEvent{
+			Id: int64(i), EventId: fmt.Sprintf("evt-%d", i), Event: model.WebhookLoanCreated,
+		})
+	}
+	return store
+}
+
+func (s *leaseStore) ClaimEvents(limit int, lease time.Duration) (*[]model.OutboxEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claimed := []model.OutboxEvent{}
+	for _, event := range s.events {
+		if len(claimed) == limit {
+			break
+		}
+		if s.published[event.Id] || s.now.Before(s.leasedUntil[event.Id]) {
+			continue
+		}
+		s.leasedUntil[event.Id] = s.now.Add(lease)
+		claimed = append(claimed, event)
+	}
+	return &claimed, nil
+}
+
+func (s *leaseStore) MarkEventPublished(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published[id] = true
+	return nil
+}
+
+func (s *leaseStore) MarkEventFailed(id int64, publishErr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[id]++
+	return nil
+}
+
+func (s *leaseStore) advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// countingSink conta as publicações de cada evento e falha nas que estão em failing.
+type countingSink struct {
+	mu        sync.Mutex
+	published map[string]int
+	failing   map[string]bool
+}
+
+func newCountingSink() *countingSink {
+	return &countingSink{published: map[string]int{}, failing: map[string]bool{}}
+}
+
+func (s *countingSink) Publish(event model.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published[event.EventId]++
+	if s.failing[event.EventId] {
+		return errors.New("sink unavailable")
+	}
+	return nil
+}
+
+func (s *countingSink) count(eventId string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.published[eventId]
+}
+
+func TestDispatcherClaimsEachEventOnce(t *testing.T) {
+	store := newLeaseStore(250)
+	sink := newCountingSink()
+
+	// Várias instâncias da API disputando o mesmo outbox
+	var wg sync.WaitGroup
+	for range 4 {
+		dispatcher := NewDispatcher(store, map[string]Sink{"bus": sink}, time.Minute)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 3 {
+				if err := dispatcher.Dispatch(); err != nil {
+					t.Errorf("Dispatch() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, event := range store.events {
+		if got := sink.count(event.EventId); got != 1 {
+			t.Errorf("event %s published %d times, want 1", event.EventId, got)
+		}
+		if !store.published[event.Id] {
+			t.Errorf("event %s not marked as published", event.EventId)
+		}
+	}
+}
+
+func TestDispatcherRetriesAfterSinkFailure(t *testing.T) {
+	store := newLeaseStore(3)
+	bus, webhooks := newCountingSink(), newCountingSink()
+	webhooks.failing["evt-2"] = true
+	dispatcher := NewDispatcher(store, map[string]Sink{"bus": bus, "webhooks": webhooks}, time.Minute)
+
+	if err := dispatcher.Dispatch(); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if store.published[2] || store.failures[2] != 1 {
+		t.Fatalf("event evt-2: published = %v, failures = %d, want a recorded failure", store.published[2],
+			store.failures[2])
+	}
+
+	// Enquanto a reserva não vence, o evento que falhou não é publicado de novo
+	webhooks.failing["evt-2"] = false
+	if err := dispatcher.Dispatch(); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if got := bus.count("evt-2"); got != 1 {
+		t.Fatalf("evt-2 published %d times to bus before the lease expired, want 1", got)
+	}
+
+	store.advance(lease + time.Second)
+	if err := dispatcher.Dispatch(); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+
+	// O evento é publicado de novo em todos os destinos, inclusive no que já o tinha aceitado
+	tests := []struct {
+		eventId string
+		id      int64
+		want    int
+	}{
+		{"evt-1", 1, 1},
+		{"evt-2", 2, 2},
+		{"evt-3", 3, 1},
+	}
+	for _, tt := range tests {
+		if got := bus.count(tt.eventId); got != tt.want {
+			t.Errorf("%s published %d times to bus, want %d", tt.eventId, got, tt.want)
+		}
+		if got := webhooks.count(tt.eventId); got != tt.want {
+			t.Errorf("%s published %d times to webhooks, want %d", tt.eventId, got, tt.want)
+		}
+		if !store.published[tt.id] {
+			t.Errorf("%s not marked as published", tt.eventId)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api/model"
+	"log"
+)
+
+type logSink struct{}
+
+// NewLogSink cria um Sink que escreve os eventos no log.
+func NewLogSink() Sink {
+	return &logSink{}
+}
+
+func (ls *logSink) Publish(event model.OutboxEvent) error {
+	log.Printf("outbox event %s %s: %s", event.Event, event.EventId, event.Payload)
+	return nil
+}
+
+// Publisher é a parte de uma conexão NATS usada para publicar. *nats.Conn a implementa, assim como o Bus em memória.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+type busSink struct {
+	conn   Publisher
+	prefix string
+}
+
+// NewBusSink cria um Sink que publica cada evento, em JSON, no assunto "<prefix>.<evento>", por exemplo
+// "library.loan.created".
+func NewBusSink(conn Publisher, prefix string) Sink {
+	return &busSink{conn: conn, prefix: prefix}
+}
+
+func (bs *busSink) Publish(event model.OutboxEvent) error {
+	data, err := json.Marshal(event.Envelope())
+	if err != nil {
+		return fmt.Errorf("error encoding event: %v", err)
+	}
+	return bs.conn.Publish(bs.prefix+"."+string(event.Event), data)
+}
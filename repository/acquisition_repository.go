@@ -0,0 +1,432 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model"
+	"go-api/model/user"
+	"strconv"
+)
+
+type AcquisitionRepository interface {
+	CreateSuggestion(title, authorName, notes string, userId int) (*model.PurchaseSuggestion, error)
+	GetSuggestions(status model.PurchaseSuggestionStatus, userId *int) (*[]model.PurchaseSuggestion, error)
+	GetSuggestionById(id int) (*model.PurchaseSuggestion, error)
+	UpdateSuggestionStatus(id int, status model.PurchaseSuggestionStatus, bookId *int) error
+	CreateOrder(bookId int, vendor string, quantity int, unitCost float64, suggestionId *int, adminId int) (*model.PurchaseOrder, error)
+	GetOrders(status model.PurchaseOrderStatus) (*[]model.PurchaseOrder, error)
+	GetOrderById(id int) (*model.PurchaseOrder, error)
+	CancelOrder(id int) error
+	ReceiveOrder(id int, codes []int, branch, section string, adminId int) (*[]model.BookStock, error)
+	GetBudget(period, from, to string) (*[]model.AcquisitionBudget, error)
+}
+
+type acquisitionRepository struct {
+	db *sql.DB
+}
+
+func NewAcquisitionRepository(db *sql.DB) AcquisitionRepository {
+	return &acquisitionRepository{db: db}
+}
+
+func (ar *acquisitionRepository) CreateSuggestion(title, authorName, notes string, userId int) (*model.PurchaseSuggestion, error) {
+	query := `
+		INSERT INTO purchase_suggestion (title, author_name, notes, fk_user_id)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+		RETURNING id, created_at;
+	`
+
+	suggestion := model.PurchaseSuggestion{
+		Title:       title,
+		AuthorName:  authorName,
+		Notes:       notes,
+		Status:      model.PurchaseSuggestionPending,
+		UserAccount: &user.Account{Id: userId},
+	}
+	err := ar.db.QueryRow(query, title, authorName, notes, userId).Scan(&suggestion.Id, &suggestion.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating purchase suggestion: %v", err)
+	}
+	return &suggestion, nil
+}
+
+const purchaseSuggestionQuery = `
+	SELECT ps.id,
+	       ps.title,
+	       COALESCE(ps.author_name, '') AS author_name,
+	       COALESCE(ps.notes, '')       AS notes,
+	       ps.status,
+	       ps.created_at,
+	       u.id                         AS user_id,
+	       u.name                       AS user_name,
+	       ps.fk_book_id                AS book_id
+	FROM 
+	       purchase_suggestion ps
+	LEFT JOIN
+	       user_account u ON ps.fk_user_id = u.id
+	WHERE 
+	       1=1
+`
+
+func scanPurchaseSuggestion(row interface{ Scan(dest ...any) error }) (*model.PurchaseSuggestion, error) {
+	var suggestion model.PurchaseSuggestion
+	var userId *int
+	var userName *string
+
+	err := row.Scan(
+		&suggestion.Id,
+		&suggestion.Title,
+		&suggestion.AuthorName,
+		&suggestion.Notes,
+		&suggestion.Status,
+		&suggestion.CreatedAt,
+		&userId,
+		&userName,
+		&suggestion.BookId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if userId != nil {
+		suggestion.UserAccount = &user.Account{Id: *userId, Name: *userName}
+	}
+	return &suggestion, nil
+}
+
+// GetSuggestions retorna as sugestões de compra, filtradas por status e pelo usuário que as fez quando informados.
+func (ar *acquisitionRepository) GetSuggestions(status model.PurchaseSuggestionStatus, userId *int) (*[]model.PurchaseSuggestion, error) {
+	query := purchaseSuggestionQuery
+
+	var args []interface{}
+
+	if status != "" {
+		query += ` AND ps.status = $` + strconv.Itoa(len(args)+1)
+		args = append(args, string(status))
+	}
+
+	if userId != nil {
+		query += ` AND ps.fk_user_id = $` + strconv.Itoa(len(args)+1)
+		args = append(args, *userId)
+	}
+
+	query += ` ORDER BY ps.created_at DESC`
+
+	rows, err := ar.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching purchase suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]model.PurchaseSuggestion, 0)
+	for rows.Next() {
+		suggestion, err := scanPurchaseSuggestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, *suggestion)
+	}
+	return &suggestions, nil
+}
+
+func (ar *acquisitionRepository) GetSuggestionById(id int) (*model.PurchaseSuggestion, error) {
+	query := purchaseSuggestionQuery + ` AND ps.id = $1`
+
+	suggestion, err := scanPurchaseSuggestion(ar.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("purchase suggestion with id %d not found", id)
+		}
+		return nil, err
+	}
+	return suggestion, nil
+}
+
+// UpdateSuggestionStatus atualiza o status de uma sugestão e, se informado, o livro ao qual ela se refere.
+func (ar *acquisitionRepository) UpdateSuggestionStatus(id int, status model.PurchaseSuggestionStatus, bookId *int) error {
+	query := `
+		UPDATE purchase_suggestion
+		SET status = $1, fk_book_id = COALESCE($2, fk_book_id)
+		WHERE id = $3
+		RETURNING id;
+	`
+
+	err := ar.db.QueryRow(query, string(status), bookId, id).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("purchase suggestion with id %d not found", id)
+		}
+		return fmt.Errorf("error updating purchase suggestion: %v", err)
+	}
+	return nil
+}
+
+func (ar *acquisitionRepository) CreateOrder(bookId int, vendor string, quantity int, unitCost float64, suggestionId *int, adminId int) (*model.PurchaseOrder, error) {
+	query := `
+		INSERT INTO purchase_order (vendor, quantity, unit_cost, fk_book_id, fk_suggestion_id, fk_admin_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+
+	var orderId int
+	err := ar.db.QueryRow(query, vendor, quantity, unitCost, bookId, suggestionId, adminId).Scan(&orderId)
+	if err != nil {
+		return nil, fmt.Errorf("error creating purchase order: %v", err)
+	}
+	return ar.GetOrderById(orderId)
+}
+
+const purchaseOrderQuery = `
+	SELECT po.id,
+	       po.vendor,
+	       po.quantity,
+	       po.received_quantity,
+	       po.unit_cost,
+	       po.quantity * po.unit_cost AS total_cost,
+	       po.status,
+	       po.ordered_at,
+	       po.received_at,
+	       b.id                       AS book_id,
+	       b.title                    AS book_title,
+	       po.fk_suggestion_id        AS suggestion_id,
+	       a.id                       AS admin_id,
+	       a.name                     AS admin_name
+	FROM 
+	       purchase_order po
+	JOIN
+	       book b ON po.fk_book_id = b.id
+	LEFT JOIN
+	       user_account a ON po.fk_admin_id = a.id
+	WHERE 
+	       1=1
+`
+
+func scanPurchaseOrder(row interface{ Scan(dest ...any) error }) (*model.PurchaseOrder, error) {
+	var order model.PurchaseOrder
+	var adminId *int
+	var adminName *string
+
+	err := row.Scan(
+		&order.Id,
+		&order.Vendor,
+		&order.Quantity,
+		&order.ReceivedQuantity,
+		&order.UnitCost,
+		&order.TotalCost,
+		&order.Status,
+		&order.OrderedAt,
+		&order.ReceivedAt,
+		&order.Book.Id,
+		&order.Book.Title,
+		&order.SuggestionId,
+		&adminId,
+		&adminName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if adminId != nil {
+		order.AdminAccount = &user.Account{Id: *adminId, Name: *adminName}
+	}
+	return &order, nil
+}
+
+// GetOrders retorna os pedidos de compra, filtrados por status se não for uma string vazia.
+func (ar *acquisitionRepository) GetOrders(status model.PurchaseOrderStatus) (*[]model.PurchaseOrder, error) {
+	query := purchaseOrderQuery
+
+	var args []interface{}
+
+	if status != "" {
+		query += ` AND po.status = $1`
+		args = append(args, string(status))
+	}
+
+	query += ` ORDER BY po.ordered_at DESC`
+
+	rows, err := ar.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching purchase orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]model.PurchaseOrder, 0)
+	for rows.Next() {
+		order, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	return &orders, nil
+}
+
+func (ar *acquisitionRepository) GetOrderById(id int) (*model.PurchaseOrder, error) {
+	query := purchaseOrderQuery + ` AND po.id = $1`
+
+	order, err := scanPurchaseOrder(ar.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("purchase order with id %d not found", id)
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+func (ar *acquisitionRepository) CancelOrder(id int) error {
+	query := `
+		UPDATE purchase_order
+		SET status = 'cancelled'
+		WHERE id = $1 AND status IN ('ordered', 'partially_received')
+		RETURNING id;
+	`
+
+	err := ar.db.QueryRow(query, id).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("purchase order with id %d not found or already closed", id)
+		}
+		return fmt.Errorf("error cancelling purchase order: %v", err)
+	}
+	return nil
+}
+
+// ReceiveOrder cria um exemplar para cada código recebido e atualiza a quantidade recebida do pedido em uma
+// única transação, de forma que um código inválido não deixe o pedido parcialmente registrado.
+func (ar *acquisitionRepository) ReceiveOrder(id int, codes []int, branch, section string, adminId int) (*[]model.BookStock, error) {
+	tx, err := ar.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var bookId, remaining int
+	var status model.PurchaseOrderStatus
+	err = tx.QueryRow(`
+		SELECT fk_book_id, quantity - received_quantity, status
+		FROM purchase_order
+		WHERE id = $1
+		FOR UPDATE;
+	`, id).Scan(&bookId, &remaining, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("purchase order with id %d not found", id)
+		}
+		return nil, err
+	}
+
+	if status != model.PurchaseOrderOrdered && status != model.PurchaseOrderPartiallyReceived {
+		return nil, fmt.Errorf("purchase order is '%s' and cannot receive copies", status)
+	}
+
+	if len(codes) > remaining {
+		return nil, fmt.Errorf("purchase order has only %d copies left to receive", remaining)
+	}
+
+	stockQuery := `
+		INSERT INTO book_stock (code, branch, section, fk_book_id)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+		RETURNING id;
+	`
+
+	reason := fmt.Sprintf("received from purchase order %d", id)
+	bookStocks := make([]model.BookStock, 0, len(codes))
+	for _, code := range codes {
+		bookStock := model.BookStock{
+			Code:      code,
+			Status:    model.BookStockAvailable,
+			Condition: model.BookStockConditionGood,
+			Branch:    branch,
+			Section:   section,
+			BookId:    bookId,
+		}
+
+		err := tx.QueryRow(stockQuery, code, branch, section, bookId).Scan(&bookStock.Id)
+		if err != nil {
+			return nil, fmt.Errorf("error adding book with code '%d' to stock: %v", code, err)
+		}
+
+		err = insertStockHistory(tx, bookStock.Id, nil, string(model.BookStockAvailable), &adminId, reason, nil)
+		if err != nil {
+			return nil, err
+		}
+		bookStocks = append(bookStocks, bookStock)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE purchase_order
+		SET received_quantity = received_quantity + $1,
+		    status = CASE WHEN received_quantity + $1 = quantity THEN 'received' ELSE 'partially_received' END::purchase_order_status,
+		    received_at = CASE WHEN received_quantity + $1 = quantity THEN CURRENT_TIMESTAMP ELSE received_at END
+		WHERE id = $2;
+	`, len(codes), id)
+	if err != nil {
+		return nil, fmt.Errorf("error updating purchase order: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &bookStocks, nil
+}
+
+// GetBudget totaliza os pedidos de compra não cancelados por mês ou ano da data do pedido, opcionalmente entre
+// duas datas (formato YYYY-MM-DD, podendo ser strings vazias).
+func (ar *acquisitionRepository) GetBudget(period, from, to string) (*[]model.AcquisitionBudget, error) {
+	format := "YYYY-MM"
+	if period == "year" {
+		format = "YYYY"
+	}
+
+	query := `
+	SELECT TO_CHAR(po.ordered_at, '` + format + `')       AS period,
+	       COUNT(*)                                       AS order_count,
+	       SUM(po.quantity)                               AS ordered_copies,
+	       SUM(po.quantity * po.unit_cost)                AS ordered_total,
+	       SUM(po.received_quantity * po.unit_cost)       AS received_total
+	FROM 
+	       purchase_order po
+	WHERE 
+	       po.status != 'cancelled'`
+
+	var args []interface{}
+
+	if from != "" {
+		query += ` AND po.ordered_at::date >= $` + strconv.Itoa(len(args)+1)
+		args = append(args, from)
+	}
+
+	if to != "" {
+		query += ` AND po.ordered_at::date <= $` + strconv.Itoa(len(args)+1)
+		args = append(args, to)
+	}
+
+	query += `
+	GROUP BY 1
+	ORDER BY 1`
+
+	rows, err := ar.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching acquisition budget: %w", err)
+	}
+	defer rows.Close()
+
+	budget := make([]model.AcquisitionBudget, 0)
+	for rows.Next() {
+		var entry model.AcquisitionBudget
+		if err := rows.Scan(
+			&entry.Period,
+			&entry.OrderCount,
+			&entry.OrderedCopies,
+			&entry.OrderedTotal,
+			&entry.ReceivedTotal,
+		); err != nil {
+			return nil, err
+		}
+		budget = append(budget, entry)
+	}
+	return &budget, nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model"
+	"strconv"
+	"time"
+)
+
+type AuditRepository interface {
+	CreateEntry(entry model.AuditEntry) (*model.AuditEntry, error)
+	GetEntries(filter model.AuditFilter) (*[]model.AuditEntry, error)
+	GetChain() (*[]model.AuditEntry, error)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+const auditEntryColumns = `
+	id, actor_id, action, target_type, target_id, before, after, ip, request_id, created_at, prev_hash, hash
+	`
+
+// CreateEntry grava um registro no fim da trilha de auditoria, encadeado ao hash do último registro. As gravações são
+// serializadas por um advisory lock para que duas requisições simultâneas não encadeiem no mesmo registro.
+func (ar *auditRepository) CreateEntry(entry model.AuditEntry) (*model.AuditEntry, error) {
+	tx, err := ar.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('audit_log'))`); err != nil {
+		return nil, fmt.Errorf("error locking audit log: %v", err)
+	}
+
+	err = tx.QueryRow(`SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&entry.PrevHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		entry.PrevHash = model.AuditGenesisHash
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting last audit entry: %v", err)
+	}
+
+	// O horário é definido aqui, com a precisão do banco, porque faz parte do hash
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Hash = entry.ComputeHash()
+
+	query := `
+	INSERT INTO audit_log (actor_id, action, target_type, target_id, before, after, ip, request_id, created_at,
+	                       prev_hash, hash)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	RETURNING id
+	`
+	err = tx.QueryRow(query, entry.ActorId, entry.Action, entry.TargetType, nullString(entry.TargetId),
+		nullJson(entry.Before), nullJson(entry.After), entry.Ip, entry.RequestId, entry.CreatedAt, entry.PrevHash,
+		entry.Hash).Scan(&entry.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error creating audit entry: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing audit entry: %v", err)
+	}
+	return &entry, nil
+}
+
+func (ar *auditRepository) GetEntries(filter model.AuditFilter) (*[]model.AuditEntry, error) {
+	query := `SELECT` + auditEntryColumns + `FROM audit_log WHERE 1=1`
+
+	var args []interface{}
+	if filter.ActorId != 0 {
+		query += ` AND actor_id = $` + strconv.Itoa(len(args)+1)
+		args = append(args, filter.ActorId)
+	}
+	if filter.Action != "" {
+		query += ` AND action = $` + strconv.Itoa(len(args)+1)
+		args = append(args, filter.Action)
+	}
+	if filter.TargetType != "" {
+		query += ` AND target_type = $` + strconv.Itoa(len(args)+1)
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetId != "" {
+		query += ` AND target_id = $` + strconv.Itoa(len(args)+1)
+		args = append(args, filter.TargetId)
+	}
+	if filter.RequestId != "" {
+		query += ` AND request_id = $` + strconv.Itoa(len(args)+1)
+		args = append(args, filter.RequestId)
+	}
+	if filter.From != "" {
+		query += ` AND created_at >= $` + strconv.Itoa(len(args)+1) + `::date`
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		query += ` AND created_at < $` + strconv.Itoa(len(args)+1) + `::date + 1`
+		args = append(args, filter.To)
+	}
+	query += ` ORDER BY id DESC`
+
+	rows, err := ar.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %v", err)
+	}
+	return scanAuditEntries(rows)
+}
+
+// GetChain retorna a trilha de auditoria inteira na ordem em que foi gravada, para a verificação dos hashes.
+func (ar *auditRepository) GetChain() (*[]model.AuditEntry, error) {
+	rows, err := ar.db.Query(`SELECT` + auditEntryColumns + `FROM audit_log ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %v", err)
+	}
+	return scanAuditEntries(rows)
+}
+
+func scanAuditEntries(rows *sql.Rows) (*[]model.AuditEntry, error) {
+	defer rows.Close()
+
+	entries := []model.AuditEntry{}
+	for rows.Next() {
+		var entry model.AuditEntry
+		var targetId sql.NullString
+		var before, after []byte
+		err := rows.Scan(&entry.Id, &entry.ActorId, &entry.Action, &entry.TargetType, &targetId, &before, &after,
+			&entry.Ip, &entry.RequestId, &entry.CreatedAt, &entry.PrevHash, &entry.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit entry: %v", err)
+		}
+		entry.TargetId = targetId.String
+		entry.Before = before
+		entry.After = after
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %v", err)
+	}
+	return &entries, nil
+}
+
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// nullJson converte um JSON para o parâmetro da consulta. Ele é enviado como texto, não como bytes, para ser gravado
+// como JSON e não como bytea.
+func nullJson(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"go-api/model"
+	"go-api/model/user"
 	"strconv"
+	"time"
 )
 
 type BookRepository interface {
@@ -14,11 +16,17 @@ type BookRepository interface {
 	GetBookById(id int) (*model.Book, error)
 	UpdateBook(bookId int, title, synopsis string, authorId int) error
 	DeleteBook(bookId int) error
-	AddStock(code, bookId int) (*model.BookStock, error)
+	AddStock(code, bookId int, branch, section string, actorId *int) (*model.BookStock, error)
 	GetStock(code *int, bookId int) (*[]model.BookStock, error)
 	GetStockById(id int) (*model.BookStock, error)
-	UpdateStockStatus(id int, status string) error
+	GetStockByCode(code int) (*model.BookStock, error)
+	UpdateStockStatus(id int, status string, actorId *int, reason string, loanId *int) error
 	RemoveStock(id int, bookId *int) error
+	UpdateStockCondition(id int, condition string) error
+	UpdateStockLocation(id int, branch, section string) error
+	CreateDamageReport(bookStockId int, loanId *int, reporterId int, condition, notes string) (*model.DamageReport, error)
+	GetDamageReports(bookStockId int) (*[]model.DamageReport, error)
+	GetStockHistory(bookStockId int) (*[]model.BookStockHistory, error)
 }
 
 type bookRepository struct {
@@ -29,6 +37,16 @@ func NewBookRepository(db *sql.DB) BookRepository {
 	return &bookRepository{db: db}
 }
 
+// NotFoundError indica que o registro procurado não existe, para que o controller possa diferenciá-lo de uma falha
+// do banco.
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
 // CreateBook cria um novo livro no banco de dados e o retorna.
 func (br *bookRepository) CreateBook(title, synopsis string, authorId int, genreIds []int) (*model.Book, error) {
 	query := `INSERT INTO book (title, synopsis, fk_author_id) VALUES ($1, $2, $3) RETURNING id;`
@@ -288,24 +306,51 @@ func (br *bookRepository) DeleteBook(bookId int) error {
 	return nil
 }
 
-func (br *bookRepository) AddStock(code, bookId int) (*model.BookStock, error) {
-	query := `INSERT INTO book_stock (code, fk_book_id) VALUES ($1, $2) RETURNING id;`
+// AddStock adiciona um exemplar ao estoque de um livro, registrando sua entrada no histórico do exemplar.
+func (br *bookRepository) AddStock(code, bookId int, branch, section string, actorId *int) (*model.BookStock, error) {
+	tx, err := br.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO book_stock (code, branch, section, fk_book_id)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+		RETURNING id;
+	`
 
 	var bookStockId int
-	err := br.db.QueryRow(query, code, bookId).Scan(&bookStockId)
+	err = tx.QueryRow(query, code, branch, section, bookId).Scan(&bookStockId)
 	if err != nil {
 		return nil, fmt.Errorf("error adding book with code '%d' to stock: %v", code, err)
 	}
+
+	err = insertStockHistory(tx, bookStockId, nil, string(model.BookStockAvailable), actorId, "added to stock", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	var bookStock model.BookStock
 	bookStock.Id = bookStockId
 	bookStock.Code = code
+	bookStock.Branch = branch
+	bookStock.Section = section
 	bookStock.BookId = bookId
-	bookStock.Status = "available"
+	bookStock.Status = model.BookStockAvailable
+	bookStock.Condition = model.BookStockConditionGood
 	return &bookStock, nil
 }
 
 func (br *bookRepository) GetStock(code *int, bookId int) (*[]model.BookStock, error) {
-	query := `SELECT id, status, code FROM book_stock WHERE 1=1 AND fk_book_id = $1`
+	query := `
+	SELECT id, status, condition, code, COALESCE(branch, ''), COALESCE(section, '')
+	FROM book_stock
+	WHERE 1=1 AND fk_book_id = $1`
 
 	var args []interface{}
 	args = append(args, bookId)
@@ -326,7 +371,7 @@ func (br *bookRepository) GetStock(code *int, bookId int) (*[]model.BookStock, e
 	for rows.Next() {
 		var bookStock model.BookStock
 
-		err := rows.Scan(&bookStock.Id, &bookStock.Status, &bookStock.Code)
+		err := rows.Scan(&bookStock.Id, &bookStock.Status, &bookStock.Condition, &bookStock.Code, &bookStock.Branch, &bookStock.Section)
 		if err != nil {
 			return nil, err
 		}
@@ -338,9 +383,20 @@ func (br *bookRepository) GetStock(code *int, bookId int) (*[]model.BookStock, e
 }
 
 func (br *bookRepository) GetStockById(id int) (*model.BookStock, error) {
-	query := `SELECT id, status, code FROM book_stock WHERE id = $1`
+	query := `
+	SELECT id, status, condition, code, COALESCE(branch, ''), COALESCE(section, ''), fk_book_id
+	FROM book_stock
+	WHERE id = $1`
 	var bookStock model.BookStock
-	err := br.db.QueryRow(query, id).Scan(&bookStock.Id, &bookStock.Status, &bookStock.Code)
+	err := br.db.QueryRow(query, id).Scan(
+		&bookStock.Id,
+		&bookStock.Status,
+		&bookStock.Condition,
+		&bookStock.Code,
+		&bookStock.Branch,
+		&bookStock.Section,
+		&bookStock.BookId,
+	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("book stock with id %d not found", id)
@@ -351,19 +407,93 @@ func (br *bookRepository) GetStockById(id int) (*model.BookStock, error) {
 	return &bookStock, nil
 }
 
-func (br *bookRepository) UpdateStockStatus(id int, status string) error {
+// GetStockByCode busca um exemplar pelo código da etiqueta, como o lido no balcão de empréstimos.
+func (br *bookRepository) GetStockByCode(code int) (*model.BookStock, error) {
+	query := `
+	SELECT id, status, condition, code, COALESCE(branch, ''), COALESCE(section, ''), fk_book_id
+	FROM book_stock
+	WHERE code = $1`
+	var bookStock model.BookStock
+	err := br.db.QueryRow(query, code).Scan(
+		&bookStock.Id,
+		&bookStock.Status,
+		&bookStock.Condition,
+		&bookStock.Code,
+		&bookStock.Branch,
+		&bookStock.Section,
+		&bookStock.BookId,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &NotFoundError{Message: fmt.Sprintf("book stock with code %d not found", code)}
+		}
+		return nil, err
+	}
+	return &bookStock, nil
+}
+
+// UpdateStockStatus atualiza o status de um exemplar e registra a mudança no seu histórico, com quem a fez,
+// o motivo e o empréstimo relacionado, quando houver.
+func (br *bookRepository) UpdateStockStatus(id int, status string, actorId *int, reason string, loanId *int) error {
+	tx, err := br.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateStockStatus(tx, id, status, actorId, reason, loanId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateStockStatus atualiza o status de um exemplar e registra a mudança no seu histórico dentro de uma transação.
+func updateStockStatus(tx *sql.Tx, id int, status string, actorId *int, reason string, loanId *int) error {
+	var oldStatus string
+	err := tx.QueryRow(`SELECT status FROM book_stock WHERE id = $1 FOR UPDATE;`, id).Scan(&oldStatus)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("book stock with id %d not found", id)
+		}
+		return fmt.Errorf("error updating stock status: %v", err)
+	}
+
 	query := `
 		UPDATE book_stock 
 		SET status = $1 
-		WHERE id = $2
-		RETURNING id;
+		WHERE id = $2;
 	`
 
-	err := br.db.QueryRow(query, status, id).Scan(&id)
+	_, err = tx.Exec(query, status, id)
 	if err != nil {
 		return fmt.Errorf("error updating stock status: %v", err)
 	}
 
+	if oldStatus != status {
+		return insertStockHistory(tx, id, &oldStatus, status, actorId, reason, loanId)
+	}
+	return nil
+}
+
+// sqlExecutor é implementado por *sql.DB e *sql.Tx, para que uma mesma consulta possa ser feita dentro ou fora de
+// uma transação.
+type sqlExecutor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// insertStockHistory adiciona um registro ao histórico de status de um exemplar dentro de uma transação.
+func insertStockHistory(tx *sql.Tx, bookStockId int, oldStatus *string, newStatus string, actorId *int, reason string, loanId *int) error {
+	query := `
+		INSERT INTO book_stock_history (old_status, new_status, reason, fk_book_stock_id, fk_actor_id, fk_loan_id)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6);
+	`
+
+	_, err := tx.Exec(query, oldStatus, newStatus, reason, bookStockId, actorId, loanId)
+	if err != nil {
+		return fmt.Errorf("error recording stock history: %v", err)
+	}
 	return nil
 }
 
@@ -395,3 +525,213 @@ func (br *bookRepository) RemoveStock(id int, bookId *int) error {
 
 	return nil
 }
+
+// UpdateStockCondition atualiza o grau de conservação de um exemplar.
+func (br *bookRepository) UpdateStockCondition(id int, condition string) error {
+	return updateStockCondition(br.db, id, condition)
+}
+
+func updateStockCondition(q sqlExecutor, id int, condition string) error {
+	query := `
+		UPDATE book_stock
+		SET condition = $1
+		WHERE id = $2
+		RETURNING id;
+	`
+
+	err := q.QueryRow(query, condition, id).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("book stock with id %d not found", id)
+		}
+		return fmt.Errorf("error updating stock condition: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateStockLocation atualiza a filial e a seção onde um exemplar fica guardado.
+func (br *bookRepository) UpdateStockLocation(id int, branch, section string) error {
+	query := `
+		UPDATE book_stock
+		SET branch = NULLIF($1, ''), section = NULLIF($2, '')
+		WHERE id = $3
+		RETURNING id;
+	`
+
+	err := br.db.QueryRow(query, branch, section, id).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("book stock with id %d not found", id)
+		}
+		return fmt.Errorf("error updating stock location: %v", err)
+	}
+
+	return nil
+}
+
+// CreateDamageReport registra um dano encontrado em um exemplar e o retorna.
+func (br *bookRepository) CreateDamageReport(bookStockId int, loanId *int, reporterId int, condition, notes string) (*model.DamageReport, error) {
+	return createDamageReport(br.db, bookStockId, loanId, reporterId, condition, notes)
+}
+
+func createDamageReport(q sqlExecutor, bookStockId int, loanId *int, reporterId int, condition, notes string) (*model.DamageReport, error) {
+	query := `
+		INSERT INTO damage_report (condition, notes, fk_book_stock_id, fk_loan_id, fk_reporter_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, reported_at;
+	`
+
+	report := model.DamageReport{
+		Condition:   model.BookStockCondition(condition),
+		Notes:       notes,
+		BookStockId: bookStockId,
+		LoanId:      loanId,
+		Reporter:    &user.Account{Id: reporterId},
+	}
+	err := q.QueryRow(query, condition, notes, bookStockId, loanId, reporterId).Scan(&report.Id, &report.ReportedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating damage report: %v", err)
+	}
+
+	return &report, nil
+}
+
+// GetDamageReports retorna os danos registrados para um exemplar, do mais recente ao mais antigo.
+func (br *bookRepository) GetDamageReports(bookStockId int) (*[]model.DamageReport, error) {
+	query := `
+	SELECT dr.id            AS damage_report_id,
+	       dr.condition,
+	       dr.notes,
+	       dr.reported_at,
+	       dr.fk_loan_id    AS loan_id,
+	       u.id             AS reporter_id,
+	       u.name           AS reporter_name,
+	       f.id             AS fine_id,
+	       f.amount         AS fine_amount,
+	       f.issued_at      AS fine_issued_at,
+	       f.paid_at        AS fine_paid_at,
+	       f.fk_user_id     AS fine_user_id
+	FROM 
+	       damage_report dr
+	LEFT JOIN
+	       user_account u ON dr.fk_reporter_id = u.id
+	LEFT JOIN
+	       fine f ON f.fk_damage_report_id = dr.id
+	WHERE 
+	       dr.fk_book_stock_id = $1
+	ORDER BY
+	       dr.reported_at DESC
+	`
+
+	rows, err := br.db.Query(query, bookStockId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching damage reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]model.DamageReport, 0)
+	for rows.Next() {
+		var report model.DamageReport
+		var reporterId, fineId, fineUserId *int
+		var reporterName *string
+		var fineAmount *float64
+		var fineIssuedAt, finePaidAt *time.Time
+
+		if err := rows.Scan(
+			&report.Id,
+			&report.Condition,
+			&report.Notes,
+			&report.ReportedAt,
+			&report.LoanId,
+			&reporterId,
+			&reporterName,
+			&fineId,
+			&fineAmount,
+			&fineIssuedAt,
+			&finePaidAt,
+			&fineUserId,
+		); err != nil {
+			return nil, err
+		}
+		report.BookStockId = bookStockId
+
+		if reporterId != nil {
+			report.Reporter = &user.Account{Id: *reporterId, Name: *reporterName}
+		}
+
+		if fineId != nil {
+			report.Fine = &model.Fine{
+				Id:             *fineId,
+				Amount:         *fineAmount,
+				Reason:         model.FineDamage,
+				IssuedAt:       *fineIssuedAt,
+				PaidAt:         finePaidAt,
+				LoanId:         report.LoanId,
+				DamageReportId: &report.Id,
+			}
+			if fineUserId != nil {
+				report.Fine.UserId = *fineUserId
+			}
+		}
+
+		reports = append(reports, report)
+	}
+	return &reports, nil
+}
+
+// GetStockHistory retorna o histórico de status de um exemplar, do registro mais recente ao mais antigo.
+func (br *bookRepository) GetStockHistory(bookStockId int) (*[]model.BookStockHistory, error) {
+	query := `
+	SELECT h.id,
+	       h.old_status,
+	       h.new_status,
+	       COALESCE(h.reason, '') AS reason,
+	       h.changed_at,
+	       u.id                   AS actor_id,
+	       u.name                 AS actor_name,
+	       h.fk_loan_id           AS loan_id
+	FROM 
+	       book_stock_history h
+	LEFT JOIN
+	       user_account u ON h.fk_actor_id = u.id
+	WHERE 
+	       h.fk_book_stock_id = $1
+	ORDER BY
+	       h.changed_at DESC, h.id DESC
+	`
+
+	rows, err := br.db.Query(query, bookStockId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching stock history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]model.BookStockHistory, 0)
+	for rows.Next() {
+		var entry model.BookStockHistory
+		var actorId *int
+		var actorName *string
+
+		if err := rows.Scan(
+			&entry.Id,
+			&entry.OldStatus,
+			&entry.NewStatus,
+			&entry.Reason,
+			&entry.ChangedAt,
+			&actorId,
+			&actorName,
+			&entry.LoanId,
+		); err != nil {
+			return nil, err
+		}
+		entry.BookStockId = bookStockId
+
+		if actorId != nil {
+			entry.Actor = &user.Account{Id: *actorId, Name: *actorName}
+		}
+
+		history = append(history, entry)
+	}
+	return &history, nil
+}
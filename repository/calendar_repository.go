@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model"
+	"strconv"
+	"time"
+)
+
+type CalendarRepository interface {
+	GetOpeningHours() (*[]model.OpeningHours, error)
+	SetOpeningHours(hours []model.OpeningHours) error
+	GetClosedDates(from, to string) (*[]model.ClosedDate, error)
+	CreateClosedDate(closedDate model.ClosedDate) error
+	DeleteClosedDate(date time.Time) error
+	ImportClosedDates(closedDates []model.ClosedDate) (int, error)
+}
+
+type calendarRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) CalendarRepository {
+	return &calendarRepository{db: db}
+}
+
+func (cr *calendarRepository) GetOpeningHours() (*[]model.OpeningHours, error) {
+	query := `
+	SELECT weekday, TO_CHAR(opens_at, 'HH24:MI'), TO_CHAR(closes_at, 'HH24:MI')
+	FROM opening_hours
+	ORDER BY weekday
+	`
+	rows, err := cr.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting opening hours: %v", err)
+	}
+	defer rows.Close()
+
+	hours := make([]model.OpeningHours, 0)
+	for rows.Next() {
+		var h model.OpeningHours
+		if err := rows.Scan(&h.Weekday, &h.OpensAt, &h.ClosesAt); err != nil {
+			return nil, fmt.Errorf("error scanning opening hours: %v", err)
+		}
+		hours = append(hours, h)
+	}
+	return &hours, nil
+}
+
+// SetOpeningHours substitui todo o horário semanal. Os dias da semana ausentes passam a ser dias fechados.
+func (cr *calendarRepository) SetOpeningHours(hours []model.OpeningHours) error {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM opening_hours`); err != nil {
+		return fmt.Errorf("error clearing opening hours: %v", err)
+	}
+	for _, h := range hours {
+		_, err := tx.Exec(`INSERT INTO opening_hours (weekday, opens_at, closes_at) VALUES ($1, $2, $3)`,
+			h.Weekday, h.OpensAt, h.ClosesAt)
+		if err != nil {
+			return fmt.Errorf("error setting opening hours: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+func (cr *calendarRepository) GetClosedDates(from, to string) (*[]model.ClosedDate, error) {
+	query := `SELECT date, reason FROM closed_date WHERE 1=1`
+
+	var args []interface{}
+	if from != "" {
+		query += ` AND date >= $` + strconv.Itoa(len(args)+1)
+		args = append(args, from)
+	}
+	if to != "" {
+		query += ` AND date <= $` + strconv.Itoa(len(args)+1)
+		args = append(args, to)
+	}
+	query += ` ORDER BY date`
+
+	rows, err := cr.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting closed dates: %v", err)
+	}
+	defer rows.Close()
+
+	closedDates := make([]model.ClosedDate, 0)
+	for rows.Next() {
+		var closedDate model.ClosedDate
+		if err := rows.Scan(&closedDate.Date, &closedDate.Reason); err != nil {
+			return nil, fmt.Errorf("error scanning closed date: %v", err)
+		}
+		closedDates = append(closedDates, closedDate)
+	}
+	return &closedDates, nil
+}
+
+func (cr *calendarRepository) CreateClosedDate(closedDate model.ClosedDate) error {
+	_, err := cr.db.Exec(`INSERT INTO closed_date (date, reason) VALUES ($1, $2)`,
+		closedDate.Date.Format(time.DateOnly), closedDate.Reason)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s is already a closed date", closedDate.Date.Format(time.DateOnly))
+		}
+		return fmt.Errorf("error creating closed date: %v", err)
+	}
+	return nil
+}
+
+func (cr *calendarRepository) DeleteClosedDate(date time.Time) error {
+	var deleted time.Time
+	err := cr.db.QueryRow(`DELETE FROM closed_date WHERE date = $1 RETURNING date`,
+		date.Format(time.DateOnly)).Scan(&deleted)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("closed date %s not found", date.Format(time.DateOnly))
+		}
+		return fmt.Errorf("error deleting closed date: %v", err)
+	}
+	return nil
+}
+
+// ImportClosedDates cadastra as datas informadas, mantendo as já existentes, e retorna quantas foram adicionadas.
+func (cr *calendarRepository) ImportClosedDates(closedDates []model.ClosedDate) (int, error) {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	imported := 0
+	for _, closedDate := range closedDates {
+		result, err := tx.Exec(`INSERT INTO closed_date (date, reason) VALUES ($1, $2) ON CONFLICT (date) DO NOTHING`,
+			closedDate.Date.Format(time.DateOnly), closedDate.Reason)
+		if err != nil {
+			return 0, fmt.Errorf("error importing closed date: %v", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("error importing closed date: %v", err)
+		}
+		imported += int(affected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return imported, nil
+}
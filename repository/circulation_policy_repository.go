@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+)
+
+type CirculationPolicyRepository interface {
+	GetCirculationPolicy() (*model.CirculationPolicy, error)
+	UpdateCirculationPolicy(policy model.CirculationPolicy, adminId int) error
+}
+
+type circulationPolicyRepository struct {
+	db *sql.DB
+}
+
+func NewCirculationPolicyRepository(db *sql.DB) CirculationPolicyRepository {
+	return &circulationPolicyRepository{db: db}
+}
+
+func (cr *circulationPolicyRepository) GetCirculationPolicy() (*model.CirculationPolicy, error) {
+	query := `
+	SELECT reservation_window_hours, block_on_overdue_loans, max_unpaid_fines, require_valid_membership,
+	       renewal_blocked_by_reservations, updated_at, fk_updated_by
+	FROM circulation_policy
+	WHERE id = 1
+	`
+	var policy model.CirculationPolicy
+	err := cr.db.QueryRow(query).Scan(&policy.ReservationWindowHours, &policy.BlockOnOverdueLoans,
+		&policy.MaxUnpaidFines, &policy.RequireValidMembership, &policy.RenewalBlockedByReservations,
+		&policy.UpdatedAt, &policy.UpdatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("error getting circulation policy: %v", err)
+	}
+	return &policy, nil
+}
+
+func (cr *circulationPolicyRepository) UpdateCirculationPolicy(policy model.CirculationPolicy, adminId int) error {
+	query := `
+	UPDATE circulation_policy
+	SET reservation_window_hours = $1, block_on_overdue_loans = $2, max_unpaid_fines = $3,
+	    require_valid_membership = $4, renewal_blocked_by_reservations = $5, updated_at = CURRENT_TIMESTAMP,
+	    fk_updated_by = $6
+	WHERE id = 1
+	`
+	_, err := cr.db.Exec(query, policy.ReservationWindowHours, policy.BlockOnOverdueLoans, policy.MaxUnpaidFines,
+		policy.RequireValidMembership, policy.RenewalBlockedByReservations, adminId)
+	if err != nil {
+		return fmt.Errorf("error updating circulation policy: %v", err)
+	}
+	return nil
+}
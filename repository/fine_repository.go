@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"fmt"
+	"go-api/model"
+)
+
+// createFine registra uma cobrança para o usuário e a retorna. As multas são cobradas na devolução, dentro da
+// transação que finaliza o empréstimo.
+func createFine(q sqlExecutor, userId int, loanId, damageReportId *int, amount float64, reason model.FineReason) (*model.Fine, error) {
+	query := `
+		INSERT INTO fine (amount, reason, fk_user_id, fk_loan_id, fk_damage_report_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, issued_at;
+	`
+
+	fine := model.Fine{
+		Amount:         amount,
+		Reason:         reason,
+		UserId:         userId,
+		LoanId:         loanId,
+		DamageReportId: damageReportId,
+	}
+	err := q.QueryRow(query, amount, string(reason), userId, loanId, damageReportId).Scan(&fine.Id, &fine.IssuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating fine: %v", err)
+	}
+
+	return &fine, nil
+}
@@ -0,0 +1,423 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model"
+	"go-api/model/user"
+)
+
+type InventoryRepository interface {
+	CreateSession(branch, section string, openedBy int) (*model.InventorySession, error)
+	GetSessions(status model.InventorySessionStatus) (*[]model.InventorySession, error)
+	GetSessionById(id int) (*model.InventorySession, error)
+	AddScans(sessionId int, codes []int) (int, error)
+	CloseSession(id, closedBy int) error
+	MarkSessionApplied(id int) error
+	GetExpectedNotScanned(sessionId int) ([]model.InventoryItem, error)
+	GetScannedByStatus(sessionId int, status model.BookStockStatus) ([]model.InventoryItem, error)
+	GetUnknownCodes(sessionId int) ([]int, error)
+	GetDiscrepancies(sessionId int, kind model.InventoryDiscrepancyKind) ([]model.InventoryItem, error)
+	GetUnknownCodeDiscrepancies(sessionId int) ([]int, error)
+}
+
+type inventoryRepository struct {
+	db *sql.DB
+}
+
+func NewInventoryRepository(db *sql.DB) InventoryRepository {
+	return &inventoryRepository{db: db}
+}
+
+const inventorySessionQuery = `
+	SELECT s.id,
+	       s.branch,
+	       COALESCE(s.section, '') AS section,
+	       s.status,
+	       s.opened_at,
+	       s.closed_at,
+	       s.applied_at,
+	       (SELECT COUNT(*) FROM inventory_scan sc WHERE sc.fk_session_id = s.id) AS scan_count,
+	       o.id                    AS opened_by_id,
+	       o.name                  AS opened_by_name,
+	       c.id                    AS closed_by_id,
+	       c.name                  AS closed_by_name
+	FROM 
+	       inventory_session s
+	LEFT JOIN
+	       user_account o ON s.fk_opened_by = o.id
+	LEFT JOIN
+	       user_account c ON s.fk_closed_by = c.id
+`
+
+// scanInventorySession lê uma linha de inventorySessionQuery.
+func scanInventorySession(row interface{ Scan(dest ...any) error }) (*model.InventorySession, error) {
+	var session model.InventorySession
+	var openedById, closedById *int
+	var openedByName, closedByName *string
+
+	err := row.Scan(
+		&session.Id,
+		&session.Branch,
+		&session.Section,
+		&session.Status,
+		&session.OpenedAt,
+		&session.ClosedAt,
+		&session.AppliedAt,
+		&session.ScanCount,
+		&openedById,
+		&openedByName,
+		&closedById,
+		&closedByName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if openedById != nil {
+		session.OpenedBy = &user.Account{Id: *openedById, Name: *openedByName}
+	}
+	if closedById != nil {
+		session.ClosedBy = &user.Account{Id: *closedById, Name: *closedByName}
+	}
+	return &session, nil
+}
+
+// CreateSession abre uma nova sessão de inventário para uma filial e, opcionalmente, uma seção.
+func (ir *inventoryRepository) CreateSession(branch, section string, openedBy int) (*model.InventorySession, error) {
+	query := `
+		INSERT INTO inventory_session (branch, section, fk_opened_by)
+		VALUES ($1, NULLIF($2, ''), $3)
+		RETURNING id;
+	`
+
+	var sessionId int
+	err := ir.db.QueryRow(query, branch, section, openedBy).Scan(&sessionId)
+	if err != nil {
+		return nil, fmt.Errorf("error opening inventory session: %v", err)
+	}
+
+	return ir.GetSessionById(sessionId)
+}
+
+// GetSessions retorna as sessões de inventário, filtradas por status se não for uma string vazia.
+func (ir *inventoryRepository) GetSessions(status model.InventorySessionStatus) (*[]model.InventorySession, error) {
+	query := inventorySessionQuery + ` WHERE 1=1`
+
+	var args []interface{}
+
+	if status != "" {
+		query += ` AND s.status = $1`
+		args = append(args, string(status))
+	}
+
+	query += ` ORDER BY s.opened_at DESC`
+
+	rows, err := ir.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching inventory sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]model.InventorySession, 0)
+	for rows.Next() {
+		session, err := scanInventorySession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return &sessions, nil
+}
+
+func (ir *inventoryRepository) GetSessionById(id int) (*model.InventorySession, error) {
+	query := inventorySessionQuery + ` WHERE s.id = $1`
+
+	session, err := scanInventorySession(ir.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("inventory session with id %d not found", id)
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// AddScans registra os códigos lidos em uma sessão, ignorando códigos já lidos, e retorna quantos foram adicionados.
+func (ir *inventoryRepository) AddScans(sessionId int, codes []int) (int, error) {
+	tx, err := ir.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO inventory_scan (code, fk_session_id)
+		VALUES ($1, $2)
+		ON CONFLICT (fk_session_id, code) DO NOTHING;
+	`
+
+	var added int
+	for _, code := range codes {
+		result, err := tx.Exec(query, code, sessionId)
+		if err != nil {
+			return 0, fmt.Errorf("error recording scanned code '%d': %v", code, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		added += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// CloseSession encerra uma sessão aberta e grava a sua reconciliação em inventory_discrepancy, na mesma transação.
+// Mudanças posteriores nos exemplares não alteram o relatório nem o que é aplicado a partir dele.
+func (ir *inventoryRepository) CloseSession(id, closedBy int) error {
+	tx, err := ir.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE inventory_session
+		SET status = 'closed', closed_at = CURRENT_TIMESTAMP, fk_closed_by = $1
+		WHERE id = $2 AND status = 'open'
+		RETURNING id;
+	`
+
+	err = tx.QueryRow(query, closedBy, id).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("inventory session is not open")
+		}
+		return fmt.Errorf("error closing inventory session: %v", err)
+	}
+
+	discrepancyQueries := []string{
+		// Exemplares disponíveis na filial/seção da sessão cujo código não foi lido
+		`
+		INSERT INTO inventory_discrepancy (kind, code, stock_status, fk_session_id, fk_book_stock_id)
+		SELECT 'not_scanned', bs.code, bs.status, s.id, bs.id
+		FROM 
+		       inventory_session s
+		JOIN
+		       book_stock bs ON bs.branch = s.branch AND (s.section IS NULL OR bs.section = s.section)
+		WHERE 
+		       s.id = $1
+		  AND  bs.status = 'available'
+		  AND  NOT EXISTS (SELECT 1 FROM inventory_scan sc WHERE sc.fk_session_id = s.id AND sc.code = bs.code)
+		`,
+		// Exemplares lidos que constam como extraviados ou emprestados
+		`
+		INSERT INTO inventory_discrepancy (kind, code, stock_status, fk_session_id, fk_book_stock_id)
+		SELECT CASE bs.status WHEN 'missing' THEN 'scanned_missing' ELSE 'scanned_borrowed' END::inventory_discrepancy_kind,
+		       bs.code, bs.status, sc.fk_session_id, bs.id
+		FROM 
+		       inventory_scan sc
+		JOIN
+		       book_stock bs ON sc.code = bs.code
+		WHERE 
+		       sc.fk_session_id = $1
+		  AND  bs.status IN ('missing', 'borrowed')
+		`,
+		// Códigos lidos que não pertencem a nenhum exemplar
+		`
+		INSERT INTO inventory_discrepancy (kind, code, fk_session_id)
+		SELECT 'unknown_code', sc.code, sc.fk_session_id
+		FROM 
+		       inventory_scan sc
+		LEFT JOIN
+		       book_stock bs ON sc.code = bs.code
+		WHERE 
+		       sc.fk_session_id = $1
+		  AND  bs.id IS NULL
+		`,
+	}
+	for _, query := range discrepancyQueries {
+		if _, err := tx.Exec(query, id); err != nil {
+			return fmt.Errorf("error recording inventory reconciliation: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error closing inventory session: %v", err)
+	}
+	return nil
+}
+
+// MarkSessionApplied reserva a aplicação do resultado de uma sessão encerrada. Apenas uma chamada consegue marcar a
+// sessão; as demais, mesmo simultâneas, recebem um erro.
+func (ir *inventoryRepository) MarkSessionApplied(id int) error {
+	query := `
+		UPDATE inventory_session
+		SET applied_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'closed' AND applied_at IS NULL
+		RETURNING id;
+	`
+	err := ir.db.QueryRow(query, id).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("inventory session result has already been applied")
+		}
+		return fmt.Errorf("error marking inventory session as applied: %v", err)
+	}
+	return nil
+}
+
+// GetExpectedNotScanned retorna os exemplares disponíveis na filial/seção da sessão cujo código não foi lido.
+func (ir *inventoryRepository) GetExpectedNotScanned(sessionId int) ([]model.InventoryItem, error) {
+	query := `
+	SELECT bs.id,
+	       bs.code,
+	       bs.status,
+	       COALESCE(bs.branch, ''),
+	       COALESCE(bs.section, ''),
+	       b.id,
+	       b.title
+	FROM 
+	       inventory_session s
+	JOIN
+	       book_stock bs ON bs.branch = s.branch AND (s.section IS NULL OR bs.section = s.section)
+	JOIN
+	       book b ON bs.fk_book_id = b.id
+	WHERE 
+	       s.id = $1
+	  AND  bs.status = 'available'
+	  AND  NOT EXISTS (SELECT 1 FROM inventory_scan sc WHERE sc.fk_session_id = s.id AND sc.code = bs.code)
+	ORDER BY
+	       bs.code
+	`
+	return ir.queryInventoryItems(query, sessionId)
+}
+
+// GetScannedByStatus retorna os exemplares lidos na sessão que estão com o status informado.
+func (ir *inventoryRepository) GetScannedByStatus(sessionId int, status model.BookStockStatus) ([]model.InventoryItem, error) {
+	query := `
+	SELECT bs.id,
+	       bs.code,
+	       bs.status,
+	       COALESCE(bs.branch, ''),
+	       COALESCE(bs.section, ''),
+	       b.id,
+	       b.title
+	FROM 
+	       inventory_scan sc
+	JOIN
+	       book_stock bs ON sc.code = bs.code
+	JOIN
+	       book b ON bs.fk_book_id = b.id
+	WHERE 
+	       sc.fk_session_id = $1
+	  AND  bs.status = $2
+	ORDER BY
+	       bs.code
+	`
+	return ir.queryInventoryItems(query, sessionId, string(status))
+}
+
+func (ir *inventoryRepository) queryInventoryItems(query string, args ...interface{}) ([]model.InventoryItem, error) {
+	rows, err := ir.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching inventory items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.InventoryItem, 0)
+	for rows.Next() {
+		var item model.InventoryItem
+		if err := rows.Scan(
+			&item.BookStockId,
+			&item.Code,
+			&item.Status,
+			&item.Branch,
+			&item.Section,
+			&item.BookId,
+			&item.BookTitle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetDiscrepancies retorna os exemplares de um tipo de divergência gravado no encerramento da sessão, com o status
+// que tinham naquele momento.
+func (ir *inventoryRepository) GetDiscrepancies(sessionId int, kind model.InventoryDiscrepancyKind) ([]model.InventoryItem, error) {
+	query := `
+	SELECT bs.id,
+	       d.code,
+	       d.stock_status,
+	       COALESCE(bs.branch, ''),
+	       COALESCE(bs.section, ''),
+	       b.id,
+	       b.title
+	FROM 
+	       inventory_discrepancy d
+	JOIN
+	       book_stock bs ON d.fk_book_stock_id = bs.id
+	JOIN
+	       book b ON bs.fk_book_id = b.id
+	WHERE 
+	       d.fk_session_id = $1
+	  AND  d.kind = $2
+	ORDER BY
+	       d.code
+	`
+	return ir.queryInventoryItems(query, sessionId, string(kind))
+}
+
+// GetUnknownCodeDiscrepancies retorna os códigos desconhecidos gravados no encerramento da sessão.
+func (ir *inventoryRepository) GetUnknownCodeDiscrepancies(sessionId int) ([]int, error) {
+	query := `
+	SELECT code
+	FROM inventory_discrepancy
+	WHERE fk_session_id = $1 AND kind = 'unknown_code'
+	ORDER BY code
+	`
+	return ir.queryCodes(query, sessionId)
+}
+
+// GetUnknownCodes retorna os códigos lidos na sessão que não pertencem a nenhum exemplar.
+func (ir *inventoryRepository) GetUnknownCodes(sessionId int) ([]int, error) {
+	query := `
+	SELECT sc.code
+	FROM 
+	       inventory_scan sc
+	LEFT JOIN
+	       book_stock bs ON sc.code = bs.code
+	WHERE 
+	       sc.fk_session_id = $1
+	  AND  bs.id IS NULL
+	ORDER BY
+	       sc.code
+	`
+
+	return ir.queryCodes(query, sessionId)
+}
+
+func (ir *inventoryRepository) queryCodes(query string, args ...interface{}) ([]int, error) {
+	rows, err := ir.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching unknown codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := make([]int, 0)
+	for rows.Next() {
+		var code int
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
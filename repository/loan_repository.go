@@ -2,17 +2,28 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"go-api/model"
 	"go-api/model/user"
 	"strconv"
 )
 
+// ErrLoanNotBorrowed é retornado ao devolver um empréstimo que não está mais ativo.
+var ErrLoanNotBorrowed = errors.New("loan is not borrowed")
+
+// ErrLoanNotRenewable é retornado quando o empréstimo deixou de poder ser renovado entre a checagem das regras e a
+// renovação, como em duas renovações simultâneas.
+var ErrLoanNotRenewable = errors.New("loan can no longer be renewed")
+
 type LoanRepository interface {
 	CreateLoan(reservationId, bookStockId, borrowedDays int) (*model.Loan, error)
+	CreateWalkInLoan(userId, bookStockId, borrowedDays, adminId int) (*model.Loan, error)
 	GetLoansByFilters(userName string, status model.LoanStatus, loanedAt string) (*[]model.Loan, error)
 	GetLoanById(id int) (*model.Loan, error)
-	FinishLoan(id, adminId int) error
+	GetActiveLoanByStockId(bookStockId int) (*model.Loan, error)
+	ReturnLoan(id, adminId int, dailyFineRate float64, report *model.LoanReturnRequest) ([]model.Fine, error)
+	RenewLoan(id, maxRenewals int) (*model.Loan, error)
 }
 
 type loanRepository struct {
@@ -23,10 +34,11 @@ func NewLoanRepository(db *sql.DB) LoanRepository {
 	return &loanRepository{db: db}
 }
 
+// CreateLoan cria o empréstimo com o prazo de devolução adiado para o próximo dia em que a biblioteca abre.
 func (lr *loanRepository) CreateLoan(reservationId, bookStockId, borrowedDays int) (*model.Loan, error) {
 	query := `
 	INSERT INTO loan (fk_reservation_id, fk_book_stock_id, return_by) 
-	VALUES ($1, $2, CURRENT_TIMESTAMP + ($3 || ' days')::INTERVAL)
+	VALUES ($1, $2, next_open_day((CURRENT_TIMESTAMP + ($3 || ' days')::INTERVAL)::TIMESTAMP))
 	RETURNING id, loaned_at, return_by
 	`
 
@@ -42,6 +54,63 @@ func (lr *loanRepository) CreateLoan(reservationId, bookStockId, borrowedDays in
 	return &loan, nil
 }
 
+// CreateWalkInLoan empresta um exemplar a um leitor atendido no balcão, sem reserva prévia. A reserva já retirada,
+// o empréstimo e a mudança de status do exemplar são gravados na mesma transação.
+func (lr *loanRepository) CreateWalkInLoan(userId, bookStockId, borrowedDays, adminId int) (*model.Loan, error) {
+	tx, err := lr.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var oldStatus string
+	var bookId int
+	err = tx.QueryRow(`SELECT status, fk_book_id FROM book_stock WHERE id = $1 FOR UPDATE`, bookStockId).
+		Scan(&oldStatus, &bookId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("book stock with id %d not found", bookStockId)
+		}
+		return nil, fmt.Errorf("error fetching book stock: %v", err)
+	}
+	if oldStatus != string(model.BookStockAvailable) {
+		return nil, fmt.Errorf("book stock is not available")
+	}
+
+	var reservationId int
+	err = tx.QueryRow(`
+	INSERT INTO reservation (borrowed_days, status, fk_user_id, fk_admin_id, fk_book_id, expires_at)
+	VALUES ($1, 'collected', $2, $3, $4, CURRENT_TIMESTAMP)
+	RETURNING id`, borrowedDays, userId, adminId, bookId).Scan(&reservationId)
+	if err != nil {
+		return nil, fmt.Errorf("error creating reservation: %v", err)
+	}
+
+	loan := model.Loan{Status: model.LoanBorrowed, ReservationId: reservationId}
+	err = tx.QueryRow(`
+	INSERT INTO loan (fk_reservation_id, fk_book_stock_id, return_by) 
+	VALUES ($1, $2, next_open_day((CURRENT_TIMESTAMP + ($3 || ' days')::INTERVAL)::TIMESTAMP))
+	RETURNING id, loaned_at, return_by`, reservationId, bookStockId, borrowedDays).
+		Scan(&loan.Id, &loan.LoanedAt, &loan.ReturnBy)
+	if err != nil {
+		return nil, fmt.Errorf("error creating loan: %v", err)
+	}
+
+	_, err = tx.Exec(`UPDATE book_stock SET status = $1 WHERE id = $2`, string(model.BookStockBorrowed), bookStockId)
+	if err != nil {
+		return nil, fmt.Errorf("error updating stock status: %v", err)
+	}
+	err = insertStockHistory(tx, bookStockId, &oldStatus, string(model.BookStockBorrowed), &adminId, "loan created", &loan.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return &loan, nil
+}
+
 func (lr *loanRepository) GetLoansByFilters(userName string, status model.LoanStatus, loanedAt string) (*[]model.Loan, error) {
 	query := `
 	SELECT 
@@ -134,12 +203,14 @@ func (lr *loanRepository) GetLoanById(id int) (*model.Loan, error) {
 	    l.return_by,
 	    l.returned_at,
 	    l.status            AS loan_status,
+	    l.renewal_count,
 	    u.id                AS user_account_id,
 	    u.name              AS user_account_name,
 	    a.id                AS admin_account_id,
 	    a.name              AS admin_account_name,
 	    bs.id               AS book_stock_id,
 	    bs.code             AS book_stock_code,
+	    bs.fk_book_id       AS book_id,
 	    l.fk_reservation_id AS reservation_id
 	FROM 
 	    loan l
@@ -166,15 +237,20 @@ func (lr *loanRepository) GetLoanById(id int) (*model.Loan, error) {
 		&loan.ReturnBy,
 		&loan.ReturnedAt,
 		&loan.Status,
+		&loan.RenewalCount,
 		&loan.UserAccount.Id,
 		&loan.UserAccount.Name,
 		&adminId,
 		&adminName,
 		&loan.BookStock.Id,
 		&loan.BookStock.Code,
+		&loan.BookStock.BookId,
 		&loan.ReservationId,
 	)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &NotFoundError{Message: fmt.Sprintf("loan with id %d not found", id)}
+		}
 		return nil, err
 	}
 
@@ -185,11 +261,142 @@ func (lr *loanRepository) GetLoanById(id int) (*model.Loan, error) {
 	return &loan, nil
 }
 
-func (lr *loanRepository) FinishLoan(id, adminId int) error {
-	query := `UPDATE loan SET returned_at = CURRENT_TIMESTAMP, status = 'returned', fk_admin_id = $1 WHERE id = $2`
-	_, err := lr.db.Exec(query, adminId, id)
+// GetActiveLoanByStockId retorna o empréstimo em andamento de um exemplar.
+func (lr *loanRepository) GetActiveLoanByStockId(bookStockId int) (*model.Loan, error) {
+	var loanId int
+	err := lr.db.QueryRow(`SELECT id FROM loan WHERE fk_book_stock_id = $1 AND status = 'borrowed'`, bookStockId).
+		Scan(&loanId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &NotFoundError{Message: fmt.Sprintf("book stock with id %d has no active loan", bookStockId)}
+		}
+		return nil, fmt.Errorf("error fetching active loan: %v", err)
+	}
+	return lr.GetLoanById(loanId)
+}
+
+// ReturnLoan registra a devolução de um empréstimo ativo em uma única transação: finaliza o empréstimo, cobra a
+// multa por atraso à taxa diária informada e, se houver relatório de devolução, atualiza a condição do exemplar e,
+// havendo dano, registra o dano, retira o exemplar de circulação e cobra o valor do dano. Se qualquer passo falhar,
+// nada é gravado e a devolução pode ser refeita.
+//
+// Os dias de atraso contam dias iniciados e ignoram os dias em que a biblioteca estava fechada; o cálculo é feito no
+// banco para evitar diferenças de fuso horário. Só empréstimos ainda ativos são finalizados, para que duas
+// devoluções simultâneas não cobrem a multa duas vezes.
+func (lr *loanRepository) ReturnLoan(id, adminId int, dailyFineRate float64, report *model.LoanReturnRequest) ([]model.Fine, error) {
+	tx, err := lr.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+	UPDATE loan 
+	SET returned_at = CURRENT_TIMESTAMP, status = 'returned', fk_admin_id = $1 
+	WHERE id = $2 AND status = 'borrowed'
+	RETURNING (SELECT COUNT(*)
+	           FROM generate_series(return_by, returned_at, INTERVAL '1 day') AS late(day_start)
+	           WHERE late.day_start < returned_at AND is_open_day(late.day_start::DATE))::INTEGER,
+	          (SELECT fk_user_id FROM reservation WHERE id = fk_reservation_id),
+	          fk_book_stock_id
+	`
+	var daysLate, userId, bookStockId int
+	err = tx.QueryRow(query, adminId, id).Scan(&daysLate, &userId, &bookStockId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLoanNotBorrowed
+		}
+		return nil, fmt.Errorf("failed to finish loan: %w", err)
+	}
+
+	fines := make([]model.Fine, 0)
+	if amount := float64(daysLate) * dailyFineRate; amount > 0 {
+		fine, err := createFine(tx, userId, &id, nil, amount, model.FineOverdue)
+		if err != nil {
+			return nil, err
+		}
+		fines = append(fines, *fine)
+	}
+
+	if report != nil {
+		fine, err := registerReturnCondition(tx, id, userId, bookStockId, adminId, report)
+		if err != nil {
+			return nil, err
+		}
+		if fine != nil {
+			fines = append(fines, *fine)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return fines, nil
+}
+
+// registerReturnCondition aplica o relatório de devolução ao exemplar do empréstimo e retorna a cobrança pelo dano,
+// quando houver.
+func registerReturnCondition(tx *sql.Tx, loanId, userId, bookStockId, adminId int, report *model.LoanReturnRequest) (*model.Fine, error) {
+	condition := string(report.Condition)
+	if condition != "" {
+		if err := updateStockCondition(tx, bookStockId, condition); err != nil {
+			return nil, fmt.Errorf("failed to update book stock condition: %w", err)
+		}
+	}
+
+	if report.DamageNotes == "" {
+		return nil, nil
+	}
+
+	// Sem uma nova condição informada, o dano é registrado com a condição atual do exemplar
+	if condition == "" {
+		err := tx.QueryRow(`SELECT condition FROM book_stock WHERE id = $1`, bookStockId).Scan(&condition)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching book stock: %w", err)
+		}
+	}
+
+	damageReport, err := createDamageReport(tx, bookStockId, &loanId, adminId, condition, report.DamageNotes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateStockStatus(tx, bookStockId, string(model.BookStockDamaged), &adminId, "damage reported", &loanId)
 	if err != nil {
-		return fmt.Errorf("failed to finish loan: %w", err)
+		return nil, fmt.Errorf("failed to update book stock status: %w", err)
 	}
-	return nil
+
+	if report.DamageCharge > 0 {
+		return createFine(tx, userId, &loanId, &damageReport.Id, report.DamageCharge, model.FineDamage)
+	}
+
+	return nil, nil
+}
+
+// RenewLoan estende o prazo do empréstimo pela mesma duração da reserva original, adiando-o para o próximo dia em
+// que a biblioteca abre. A atualização só acontece se o empréstimo ainda estiver ativo, dentro do prazo e abaixo do
+// limite de renovações, evitando renovações concorrentes além do limite.
+func (lr *loanRepository) RenewLoan(id, maxRenewals int) (*model.Loan, error) {
+	query := `
+	UPDATE loan l
+	SET return_by = next_open_day(l.return_by + MAKE_INTERVAL(days => r.borrowed_days)),
+	    renewal_count = l.renewal_count + 1
+	FROM reservation r
+	WHERE l.fk_reservation_id = r.id
+	  AND l.id = $1
+	  AND l.status = 'borrowed'
+	  AND l.renewal_count < $2
+	  AND l.return_by > CURRENT_TIMESTAMP
+	RETURNING l.return_by, l.renewal_count
+	`
+	var loan model.Loan
+	err := lr.db.QueryRow(query, id, maxRenewals).Scan(&loan.ReturnBy, &loan.RenewalCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLoanNotRenewable
+		}
+		return nil, fmt.Errorf("error renewing loan: %v", err)
+	}
+	loan.Id = id
+	return &loan, nil
 }
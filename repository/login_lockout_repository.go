@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model/user"
+	"strconv"
+	"time"
+)
+
+type LoginLockoutRepository interface {
+	GetLockRemaining(scope user.LoginLockoutScope, subject string) (time.Duration, error)
+	RecordFailure(scope user.LoginLockoutScope, subject string, window time.Duration) (int, error)
+	Lock(scope user.LoginLockoutScope, subject string, duration time.Duration) error
+	Clear(scope user.LoginLockoutScope, subject string) error
+	GetLockouts(scope user.LoginLockoutScope, lockedOnly bool) (*[]user.LoginLockout, error)
+	GetLockoutById(id int) (*user.LoginLockout, error)
+	DeleteLockout(id int) error
+}
+
+type loginLockoutRepository struct {
+	db *sql.DB
+}
+
+func NewLoginLockoutRepository(db *sql.DB) LoginLockoutRepository {
+	return &loginLockoutRepository{db: db}
+}
+
+// GetLockRemaining retorna quanto tempo falta para o bloqueio terminar, ou zero se não houver bloqueio.
+func (lr *loginLockoutRepository) GetLockRemaining(scope user.LoginLockoutScope, subject string) (time.Duration, error) {
+	query := `
+		SELECT EXTRACT(EPOCH FROM locked_until - CURRENT_TIMESTAMP)
+		FROM login_lockout
+		WHERE scope = $1 AND subject = $2 AND locked_until > CURRENT_TIMESTAMP;
+	`
+
+	var seconds float64
+	err := lr.db.QueryRow(query, scope, subject).Scan(&seconds)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("error checking login lockout: %v", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// RecordFailure conta uma tentativa malsucedida e retorna o total de falhas. A contagem recomeça quando a última
+// falha é mais antiga que window.
+func (lr *loginLockoutRepository) RecordFailure(scope user.LoginLockoutScope, subject string, window time.Duration) (int, error) {
+	query := `
+		INSERT INTO login_lockout (scope, subject, failed_count)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (scope, subject) DO UPDATE
+		    SET failed_count   = CASE
+		                             WHEN login_lockout.last_failed_at < CURRENT_TIMESTAMP - MAKE_INTERVAL(secs => $3)
+		                                 THEN 1
+		                             ELSE login_lockout.failed_count + 1
+		                         END,
+		        last_failed_at = CURRENT_TIMESTAMP
+		RETURNING failed_count;
+	`
+
+	var failedCount int
+	err := lr.db.QueryRow(query, scope, subject, window.Seconds()).Scan(&failedCount)
+	if err != nil {
+		return 0, fmt.Errorf("error recording failed login: %v", err)
+	}
+	return failedCount, nil
+}
+
+func (lr *loginLockoutRepository) Lock(scope user.LoginLockoutScope, subject string, duration time.Duration) error {
+	query := `
+		UPDATE login_lockout
+		SET locked_until = CURRENT_TIMESTAMP + MAKE_INTERVAL(secs => $3)
+		WHERE scope = $1 AND subject = $2;
+	`
+
+	_, err := lr.db.Exec(query, scope, subject, duration.Seconds())
+	if err != nil {
+		return fmt.Errorf("error locking login: %v", err)
+	}
+	return nil
+}
+
+func (lr *loginLockoutRepository) Clear(scope user.LoginLockoutScope, subject string) error {
+	_, err := lr.db.Exec(`DELETE FROM login_lockout WHERE scope = $1 AND subject = $2`, scope, subject)
+	if err != nil {
+		return fmt.Errorf("error clearing login lockout: %v", err)
+	}
+	return nil
+}
+
+func (lr *loginLockoutRepository) GetLockouts(scope user.LoginLockoutScope, lockedOnly bool) (*[]user.LoginLockout, error) {
+	query := `
+		SELECT id,
+		       scope,
+		       subject,
+		       failed_count,
+		       last_failed_at,
+		       locked_until,
+		       COALESCE(locked_until > CURRENT_TIMESTAMP, FALSE) AS is_locked
+		FROM login_lockout
+		WHERE 1=1`
+
+	var args []interface{}
+
+	if scope != "" {
+		query += " AND scope = $" + strconv.Itoa(len(args)+1)
+		args = append(args, scope)
+	}
+
+	if lockedOnly {
+		query += " AND locked_until > CURRENT_TIMESTAMP"
+	}
+
+	query += " ORDER BY last_failed_at DESC"
+
+	rows, err := lr.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting login lockouts: %v", err)
+	}
+	defer rows.Close()
+
+	lockouts := make([]user.LoginLockout, 0)
+	for rows.Next() {
+		var lockout user.LoginLockout
+		err := rows.Scan(
+			&lockout.Id,
+			&lockout.Scope,
+			&lockout.Subject,
+			&lockout.FailedCount,
+			&lockout.LastFailedAt,
+			&lockout.LockedUntil,
+			&lockout.IsLocked,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning login lockout: %v", err)
+		}
+		lockouts = append(lockouts, lockout)
+	}
+	return &lockouts, nil
+}
+
+func (lr *loginLockoutRepository) GetLockoutById(id int) (*user.LoginLockout, error) {
+	query := `
+		SELECT id,
+		       scope,
+		       subject,
+		       failed_count,
+		       last_failed_at,
+		       locked_until,
+		       COALESCE(locked_until > CURRENT_TIMESTAMP, FALSE) AS is_locked
+		FROM login_lockout
+		WHERE id = $1`
+
+	var lockout user.LoginLockout
+	err := lr.db.QueryRow(query, id).Scan(
+		&lockout.Id,
+		&lockout.Scope,
+		&lockout.Subject,
+		&lockout.FailedCount,
+		&lockout.LastFailedAt,
+		&lockout.LockedUntil,
+		&lockout.IsLocked,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("login lockout with id %d not found", id)
+		}
+		return nil, fmt.Errorf("error getting login lockout: %v", err)
+	}
+	return &lockout, nil
+}
+
+func (lr *loginLockoutRepository) DeleteLockout(id int) error {
+	query := `
+		DELETE FROM login_lockout
+		WHERE id = $1
+		RETURNING id
+	`
+	var lockoutId int
+	err := lr.db.QueryRow(query, id).Scan(&lockoutId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("login lockout with id %d not found", id)
+		}
+		return fmt.Errorf("error deleting login lockout: %v", err)
+	}
+	return nil
+}
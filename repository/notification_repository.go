@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model"
+	"strconv"
+	"time"
+)
+
+type NotificationRepository interface {
+	GetPreferences(userId int) (*model.NotificationPreferences, error)
+	UpdatePreferences(userId int, preferences model.NotificationPreferences) error
+	CreateNotification(userId int, event model.NotificationEvent, title, body string) (*model.Notification, error)
+	GetNotifications(userId int, unreadOnly bool) (*[]model.Notification, error)
+	CountUnreadNotifications(userId int) (int, error)
+	MarkNotificationRead(id, userId int) error
+	MarkAllNotificationsRead(userId int) (int, error)
+	CreateDelivery(delivery model.NotificationDelivery, dedupeKey string, lease time.Duration) (*int, error)
+	MarkDeliverySent(id int) error
+	MarkDeliveryFailed(id int, deliveryErr string, maxAttempts int) error
+	ClaimDeliveriesToRetry(lease time.Duration) (*[]model.NotificationDelivery, error)
+	GetDeliveries(status model.NotificationDeliveryStatus, userId int) (*[]model.NotificationDelivery, error)
+	GetLoanDueReminders(days int) (*[]model.NotificationReminder, error)
+	GetReservationExpiringReminders(hours int) (*[]model.NotificationReminder, error)
+	GetHoldPositions(bookId int, after time.Time) (*[]model.HoldPosition, error)
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// GetPreferences retorna as preferências de notificação do usuário, ou as padrão se ele nunca as alterou.
+func (nr *notificationRepository) GetPreferences(userId int) (*model.NotificationPreferences, error) {
+	query := `
+	SELECT COALESCE(np.locale, 'pt-BR'), COALESCE(np.email_enabled, TRUE), COALESCE(np.inbox_enabled, TRUE),
+	       np.webhook_url
+	FROM user_account ua
+	LEFT JOIN notification_preference np ON np.fk_user_id = ua.id
+	WHERE ua.id = $1
+	`
+	var preferences model.NotificationPreferences
+	err := nr.db.QueryRow(query, userId).Scan(&preferences.Locale, &preferences.EmailEnabled,
+		&preferences.InboxEnabled, &preferences.WebhookUrl)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user with id %d not found", userId)
+		}
+		return nil, fmt.Errorf("error getting notification preferences: %v", err)
+	}
+	return &preferences, nil
+}
+
+func (nr *notificationRepository) UpdatePreferences(userId int, preferences model.NotificationPreferences) error {
+	query := `
+	INSERT INTO notification_preference (fk_user_id, locale, email_enabled, inbox_enabled, webhook_url)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (fk_user_id) DO UPDATE
+	SET locale = EXCLUDED.locale, email_enabled = EXCLUDED.email_enabled, inbox_enabled = EXCLUDED.inbox_enabled,
+	    webhook_url = EXCLUDED.webhook_url
+	`
+	_, err := nr.db.Exec(query, userId, preferences.Locale, preferences.EmailEnabled, preferences.InboxEnabled,
+		preferences.WebhookUrl)
+	if err != nil {
+		return fmt.Errorf("error updating notification preferences: %v", err)
+	}
+	return nil
+}
+
+// CreateNotification grava uma mensagem na caixa de entrada do usuário.
+func (nr *notificationRepository) CreateNotification(userId int, event model.NotificationEvent, title, body string) (*model.Notification, error) {
+	query := `
+	INSERT INTO notification (event, title, body, fk_user_id)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at
+	`
+	n := model.Notification{Event: event, Title: title, Body: body}
+	if err := nr.db.QueryRow(query, event, title, body, userId).Scan(&n.Id, &n.CreatedAt); err != nil {
+		return nil, fmt.Errorf("error creating notification: %v", err)
+	}
+	return &n, nil
+}
+
+// GetNotifications retorna a caixa de entrada do usuário, da mensagem mais recente à mais antiga.
+func (nr *notificationRepository) GetNotifications(userId int, unreadOnly bool) (*[]model.Notification, error) {
+	query := `
+	SELECT id, event, title, body, created_at, read_at
+	FROM notification
+	WHERE fk_user_id = $1 AND ($2 = FALSE OR read_at IS NULL)
+	ORDER BY created_at DESC, id DESC
+	`
+	rows, err := nr.db.Query(query, userId, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("error getting notifications: %v", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]model.Notification, 0)
+	for rows.Next() {
+		var n model.Notification
+		if err := rows.Scan(&n.Id, &n.Event, &n.Title, &n.Body, &n.CreatedAt, &n.ReadAt); err != nil {
+			return nil, fmt.Errorf("error scanning notification: %v", err)
+		}
+		notifications = append(notifications, n)
+	}
+	return &notifications, nil
+}
+
+func (nr *notificationRepository) CountUnreadNotifications(userId int) (int, error) {
+	var count int
+	err := nr.db.QueryRow(`SELECT COUNT(*) FROM notification WHERE fk_user_id = $1 AND read_at IS NULL`, userId).
+		Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting unread notifications: %v", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationRead marca uma mensagem do usuário como lida. Mensagens já lidas mantêm a data da primeira leitura.
+func (nr *notificationRepository) MarkNotificationRead(id, userId int) error {
+	query := `
+	UPDATE notification
+	SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+	WHERE id = $1 AND fk_user_id = $2
+	`
+	result, err := nr.db.Exec(query, id, userId)
+	if err != nil {
+		return fmt.Errorf("error marking notification as read: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("notification with id %d not found", id)
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead marca todas as mensagens não lidas do usuário como lidas e retorna quantas foram marcadas.
+func (nr *notificationRepository) MarkAllNotificationsRead(userId int) (int, error) {
+	query := `UPDATE notification SET read_at = CURRENT_TIMESTAMP WHERE fk_user_id = $1 AND read_at IS NULL`
+	result, err := nr.db.Exec(query, userId)
+	if err != nil {
+		return 0, fmt.Errorf("error marking notifications as read: %v", err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// CreateDelivery registra uma entrega pendente e retorna o seu id. Retorna nil se a mesma notificação já foi
+// registrada para o canal.
+func (nr *notificationRepository) CreateDelivery(delivery model.NotificationDelivery, dedupeKey string, lease time.Duration) (*int, error) {
+	query := `
+	INSERT INTO notification_delivery (event, channel, dedupe_key, recipient, subject, body, next_attempt_at,
+	                                   fk_user_id)
+	VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP + MAKE_INTERVAL(secs => $7), $8)
+	ON CONFLICT (dedupe_key, channel) DO NOTHING
+	RETURNING id
+	`
+	var id int
+	err := nr.db.QueryRow(query, delivery.Event, delivery.Channel, dedupeKey, delivery.Recipient, delivery.Subject,
+		delivery.Body, lease.Seconds(), delivery.UserId).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error creating notification delivery: %v", err)
+	}
+	return &id, nil
+}
+
+func (nr *notificationRepository) MarkDeliverySent(id int) error {
+	query := `
+	UPDATE notification_delivery
+	SET status = 'sent', attempts = attempts + 1, sent_at = CURRENT_TIMESTAMP, last_error = NULL,
+	    next_attempt_at = NULL
+	WHERE id = $1
+	`
+	if _, err := nr.db.Exec(query, id); err != nil {
+		return fmt.Errorf("error updating notification delivery: %v", err)
+	}
+	return nil
+}
+
+// MarkDeliveryFailed registra uma tentativa sem sucesso. A próxima tentativa é agendada com espera crescente
+// (1, 4, 9... minutos) até maxAttempts, quando a entrega é dada como falha.
+func (nr *notificationRepository) MarkDeliveryFailed(id int, deliveryErr string, maxAttempts int) error {
+	query := `
+	UPDATE notification_delivery
+	SET attempts        = attempts + 1,
+	    last_error      = $2,
+	    status          = CASE WHEN attempts + 1 >= $3 THEN 'failed' ELSE 'pending' END::notification_delivery_status,
+	    next_attempt_at = CASE
+	                          WHEN attempts + 1 >= $3 THEN NULL
+	                          ELSE CURRENT_TIMESTAMP + MAKE_INTERVAL(mins => (attempts + 1) * (attempts + 1)) END
+	WHERE id = $1
+	`
+	if _, err := nr.db.Exec(query, id, deliveryErr, maxAttempts); err != nil {
+		return fmt.Errorf("error updating notification delivery: %v", err)
+	}
+	return nil
+}
+
+const notificationDeliveryQuery = `
+	SELECT id, event, channel, fk_user_id, recipient, subject, body, status, attempts, last_error, next_attempt_at,
+	       created_at, sent_at
+	FROM notification_delivery
+`
+
+func scanNotificationDeliveries(rows *sql.Rows) (*[]model.NotificationDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]model.NotificationDelivery, 0)
+	for rows.Next() {
+		var d model.NotificationDelivery
+		err := rows.Scan(&d.Id, &d.Event, &d.Channel, &d.UserId, &d.Recipient, &d.Subject, &d.Body, &d.Status,
+			&d.Attempts, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.SentAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning notification delivery: %v", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return &deliveries, nil
+}
+
+// ClaimDeliveriesToRetry reserva por lease as entregas pendentes cuja próxima tentativa já pode ser feita: as que
+// falharam e as que não foram enviadas dentro do lease do registro, como quando a API é encerrada antes do envio.
+// Entregas reservadas por outra instância da API são ignoradas.
+func (nr *notificationRepository) ClaimDeliveriesToRetry(lease time.Duration) (*[]model.NotificationDelivery, error) {
+	query := `
+	UPDATE notification_delivery
+	SET next_attempt_at = CURRENT_TIMESTAMP + MAKE_INTERVAL(secs => $1)
+	WHERE id IN (SELECT id
+	             FROM notification_delivery
+	             WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+	             ORDER BY next_attempt_at
+	             LIMIT 100 FOR UPDATE SKIP LOCKED)
+	RETURNING id, event, channel, fk_user_id, recipient, subject, body, status, attempts, last_error, next_attempt_at,
+	          created_at, sent_at
+	`
+	rows, err := nr.db.Query(query, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming notification deliveries: %v", err)
+	}
+	return scanNotificationDeliveries(rows)
+}
+
+func (nr *notificationRepository) GetDeliveries(status model.NotificationDeliveryStatus, userId int) (*[]model.NotificationDelivery, error) {
+	query := notificationDeliveryQuery + ` WHERE 1=1`
+
+	var args []interface{}
+	if status != "" {
+		query += ` AND status = $` + strconv.Itoa(len(args)+1)
+		args = append(args, status)
+	}
+	if userId != 0 {
+		query += ` AND fk_user_id = $` + strconv.Itoa(len(args)+1)
+		args = append(args, userId)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := nr.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting notification deliveries: %v", err)
+	}
+	return scanNotificationDeliveries(rows)
+}
+
+// GetLoanDueReminders retorna os empréstimos que vencem nos próximos dias e ainda não tiveram lembrete. A chave
+// inclui o prazo, para que um empréstimo renovado receba um novo lembrete.
+func (nr *notificationRepository) GetLoanDueReminders(days int) (*[]model.NotificationReminder, error) {
+	query := `
+	SELECT r.fk_user_id, b.title, l.return_by, 'loan_due:' || l.id || ':' || l.return_by::DATE AS dedupe_key
+	FROM loan l
+	JOIN reservation r ON l.fk_reservation_id = r.id
+	JOIN book b ON r.fk_book_id = b.id
+	WHERE l.status = 'borrowed'
+	  AND l.return_by > CURRENT_TIMESTAMP
+	  AND l.return_by <= CURRENT_TIMESTAMP + MAKE_INTERVAL(days => $1)
+	  AND NOT EXISTS (SELECT 1
+	                  FROM notification_delivery d
+	                  WHERE d.dedupe_key = 'loan_due:' || l.id || ':' || l.return_by::DATE)
+	`
+	return nr.getReminders(model.EventLoanDue, query, days)
+}
+
+// GetReservationExpiringReminders retorna as reservas pendentes que expiram nas próximas horas e ainda não tiveram
+// lembrete.
+func (nr *notificationRepository) GetReservationExpiringReminders(hours int) (*[]model.NotificationReminder, error) {
+	query := `
+	SELECT r.fk_user_id, b.title, r.expires_at, 'reservation_expiring:' || r.id AS dedupe_key
+	FROM reservation r
+	JOIN book b ON r.fk_book_id = b.id
+	WHERE r.status = 'pending'
+	  AND r.expires_at > CURRENT_TIMESTAMP
+	  AND r.expires_at <= CURRENT_TIMESTAMP + MAKE_INTERVAL(hours => $1)
+	  AND NOT EXISTS (SELECT 1 FROM notification_delivery d WHERE d.dedupe_key = 'reservation_expiring:' || r.id)
+	`
+	return nr.getReminders(model.EventReservationExpiring, query, hours)
+}
+
+func (nr *notificationRepository) getReminders(event model.NotificationEvent, query string, args ...any) (*[]model.NotificationReminder, error) {
+	rows, err := nr.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s reminders: %v", event, err)
+	}
+	defer rows.Close()
+
+	reminders := make([]model.NotificationReminder, 0)
+	for rows.Next() {
+		reminder := model.NotificationReminder{Event: event}
+		if err := rows.Scan(&reminder.UserId, &reminder.BookTitle, &reminder.Due, &reminder.DedupeKey); err != nil {
+			return nil, fmt.Errorf("error scanning %s reminder: %v", event, err)
+		}
+		reminders = append(reminders, reminder)
+	}
+	return &reminders, nil
+}
+
+// GetHoldPositions retorna a posição na fila das reservas pendentes do livro feitas depois de after, que são as que
+// avançam quando uma reserva anterior sai da fila.
+func (nr *notificationRepository) GetHoldPositions(bookId int, after time.Time) (*[]model.HoldPosition, error) {
+	query := `
+	SELECT id, fk_user_id, title, position
+	FROM (SELECT r.id, r.fk_user_id, r.reserved_at, b.title,
+	             ROW_NUMBER() OVER (ORDER BY r.reserved_at, r.id) AS position
+	      FROM reservation r
+	      JOIN book b ON r.fk_book_id = b.id
+	      WHERE r.fk_book_id = $1 AND r.status = 'pending' AND r.expires_at > CURRENT_TIMESTAMP) queue
+	WHERE reserved_at > $2
+	ORDER BY position
+	`
+	rows, err := nr.db.Query(query, bookId, after)
+	if err != nil {
+		return nil, fmt.Errorf("error getting hold positions: %v", err)
+	}
+	defer rows.Close()
+
+	positions := make([]model.HoldPosition, 0)
+	for rows.Next() {
+		var p model.HoldPosition
+		if err := rows.Scan(&p.ReservationId, &p.UserId, &p.BookTitle, &p.Position); err != nil {
+			return nil, fmt.Errorf("error scanning hold position: %v", err)
+		}
+		positions = append(positions, p)
+	}
+	return &positions, nil
+}
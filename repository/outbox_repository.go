@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+	"time"
+)
+
+type OutboxRepository interface {
+	ClaimEvents(limit int, lease time.Duration) (*[]model.OutboxEvent, error)
+	MarkEventPublished(id int64) error
+	MarkEventFailed(id int64, publishErr string) error
+}
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// ClaimEvents reserva por lease os eventos ainda não publicados cuja próxima tentativa já pode ser feita. Eventos
+// reservados por outra instância da API são ignorados.
+func (ob *outboxRepository) ClaimEvents(limit int, lease time.Duration) (*[]model.OutboxEvent, error) {
+	query := `
+	UPDATE outbox_event
+	SET next_attempt_at = CURRENT_TIMESTAMP + MAKE_INTERVAL(secs => $2)
+	WHERE id IN (SELECT id
+	             FROM outbox_event
+	             WHERE published_at IS NULL AND next_attempt_at <= CURRENT_TIMESTAMP
+	             ORDER BY id
+	             LIMIT $1 FOR UPDATE SKIP LOCKED)
+	RETURNING id, event_id, event, payload, created_at, attempts
+	`
+	rows, err := ob.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming outbox events: %v", err)
+	}
+	defer rows.Close()
+
+	events := make([]model.OutboxEvent, 0)
+	for rows.Next() {
+		var e model.OutboxEvent
+		if err := rows.Scan(&e.Id, &e.EventId, &e.Event, &e.Payload, &e.OccurredAt, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("error scanning outbox event: %v", err)
+		}
+		events = append(events, e)
+	}
+	return &events, nil
+}
+
+func (ob *outboxRepository) MarkEventPublished(id int64) error {
+	query := `UPDATE outbox_event SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1 WHERE id = $1`
+	if _, err := ob.db.Exec(query, id); err != nil {
+		return fmt.Errorf("error updating outbox event: %v", err)
+	}
+	return nil
+}
+
+// MarkEventFailed registra uma publicação sem sucesso. A próxima tentativa é agendada com espera exponencial, de
+// no máximo uma hora; eventos nunca são descartados.
+func (ob *outboxRepository) MarkEventFailed(id int64, publishErr string) error {
+	query := `
+	UPDATE outbox_event
+	SET attempts        = attempts + 1,
+	    last_error      = $2,
+	    next_attempt_at = CURRENT_TIMESTAMP + MAKE_INTERVAL(mins => LEAST(POWER(2, LEAST(attempts, 6)), 60)::INTEGER)
+	WHERE id = $1
+	`
+	if _, err := ob.db.Exec(query, id, publishErr); err != nil {
+		return fmt.Errorf("error updating outbox event: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model/user"
+
+	"github.com/lib/pq"
+)
+
+type PatronCategoryRepository interface {
+	GetPatronCategories() (*[]user.PatronCategory, error)
+	GetPatronCategoryById(id int) (*user.PatronCategory, error)
+	CreatePatronCategory(category user.PatronCategory) (*user.PatronCategory, error)
+	UpdatePatronCategory(category user.PatronCategory) error
+	DeletePatronCategory(id int) error
+}
+
+type patronCategoryRepository struct {
+	db *sql.DB
+}
+
+func NewPatronCategoryRepository(db *sql.DB) PatronCategoryRepository {
+	return &patronCategoryRepository{db: db}
+}
+
+const patronCategoryQuery = `
+	SELECT id, name, max_items, loan_days, max_renewals, daily_fine_rate
+	FROM patron_category
+`
+
+func scanPatronCategory(row interface{ Scan(dest ...any) error }) (*user.PatronCategory, error) {
+	var category user.PatronCategory
+	err := row.Scan(&category.Id, &category.Name, &category.MaxItems, pq.Array(&category.LoanDays),
+		&category.MaxRenewals, &category.DailyFineRate)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (pr *patronCategoryRepository) GetPatronCategories() (*[]user.PatronCategory, error) {
+	rows, err := pr.db.Query(patronCategoryQuery + ` ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting patron categories: %v", err)
+	}
+	defer rows.Close()
+
+	categories := make([]user.PatronCategory, 0)
+	for rows.Next() {
+		category, err := scanPatronCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning patron category: %v", err)
+		}
+		categories = append(categories, *category)
+	}
+	return &categories, nil
+}
+
+func (pr *patronCategoryRepository) GetPatronCategoryById(id int) (*user.PatronCategory, error) {
+	category, err := scanPatronCategory(pr.db.QueryRow(patronCategoryQuery+` WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("patron category with id %d not found", id)
+		}
+		return nil, fmt.Errorf("error getting patron category: %v", err)
+	}
+	return category, nil
+}
+
+func (pr *patronCategoryRepository) CreatePatronCategory(category user.PatronCategory) (*user.PatronCategory, error) {
+	query := `
+		INSERT INTO patron_category (name, max_items, loan_days, max_renewals, daily_fine_rate)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := pr.db.QueryRow(query, category.Name, category.MaxItems, pq.Array(category.LoanDays), category.MaxRenewals,
+		category.DailyFineRate).Scan(&category.Id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("patron category '%s' already exists", category.Name)
+		}
+		return nil, fmt.Errorf("error creating patron category: %v", err)
+	}
+	return &category, nil
+}
+
+func (pr *patronCategoryRepository) UpdatePatronCategory(category user.PatronCategory) error {
+	query := `
+		UPDATE patron_category
+		SET name = $2, max_items = $3, loan_days = $4, max_renewals = $5, daily_fine_rate = $6
+		WHERE id = $1
+		RETURNING id
+	`
+	var categoryId int
+	err := pr.db.QueryRow(query, category.Id, category.Name, category.MaxItems, pq.Array(category.LoanDays),
+		category.MaxRenewals, category.DailyFineRate).Scan(&categoryId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("patron category with id %d not found", category.Id)
+		}
+		if isUniqueViolation(err) {
+			return fmt.Errorf("patron category '%s' already exists", category.Name)
+		}
+		return fmt.Errorf("error updating patron category: %v", err)
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// DeletePatronCategory remove uma categoria sem usuários vinculados.
+func (pr *patronCategoryRepository) DeletePatronCategory(id int) error {
+	var categoryId int
+	err := pr.db.QueryRow(`DELETE FROM patron_category WHERE id = $1 RETURNING id`, id).Scan(&categoryId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("patron category with id %d not found", id)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return fmt.Errorf("patron category with id %d is assigned to users", id)
+		}
+		return fmt.Errorf("error deleting patron category: %v", err)
+	}
+	return nil
+}
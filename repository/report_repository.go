@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+)
+
+type ReportRepository interface {
+	GetUnusedCopies(months int) (*[]model.UnusedCopyReport, error)
+	GetUnusedTitles(months int) (*[]model.UnusedTitleReport, error)
+	GetTitleCirculation(months, limit int, ascending bool) (*[]model.TitleCirculationReport, error)
+	GetGenreCirculation(months, limit int, ascending bool) (*[]model.GenreCirculationReport, error)
+	GetDamagedCopies(minReports int) (*[]model.DamagedCopyReport, error)
+	GetWaitlistPressure(minRatio float64) (*[]model.WaitlistReport, error)
+	GetExpiringMemberships(days int) (*[]model.ExpiringMembershipReport, error)
+}
+
+type reportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (rr *reportRepository) GetUnusedCopies(months int) (*[]model.UnusedCopyReport, error) {
+	// Exemplares incluídos no acervo dentro do período ainda não tiveram chance de circular
+	query := `
+		SELECT bs.id,
+		       bs.code,
+		       bs.status,
+		       COALESCE(bs.branch, '')  AS branch,
+		       COALESCE(bs.section, '') AS section,
+		       b.id,
+		       b.title,
+		       MAX(l.loaned_at)         AS last_loaned_at
+		FROM book_stock bs
+		         JOIN book b ON bs.fk_book_id = b.id
+		         LEFT JOIN loan l ON l.fk_book_stock_id = bs.id
+		WHERE bs.status != 'withdrawn'
+		  AND bs.created_at < CURRENT_TIMESTAMP - make_interval(months => $1)
+		GROUP BY bs.id, b.id
+		HAVING MAX(l.loaned_at) IS NULL
+		    OR MAX(l.loaned_at) < CURRENT_TIMESTAMP - make_interval(months => $1)
+		ORDER BY last_loaned_at NULLS FIRST, bs.code;
+	`
+
+	rows, err := rr.db.Query(query, months)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unused copies: %v", err)
+	}
+	defer rows.Close()
+
+	var reports []model.UnusedCopyReport
+	for rows.Next() {
+		var report model.UnusedCopyReport
+		var lastLoanedAt sql.NullTime
+		err = rows.Scan(&report.BookStockId, &report.Code, &report.Status, &report.Branch, &report.Section,
+			&report.BookId, &report.BookTitle, &lastLoanedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning unused copy: %v", err)
+		}
+		if lastLoanedAt.Valid {
+			report.LastLoanedAt = &lastLoanedAt.Time
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unused copies: %v", err)
+	}
+	return &reports, nil
+}
+
+func (rr *reportRepository) GetUnusedTitles(months int) (*[]model.UnusedTitleReport, error) {
+	query := `
+		SELECT b.id,
+		       b.title,
+		       COALESCE(a.name, '')   AS author_name,
+		       COUNT(DISTINCT bs.id) AS copy_count,
+		       MAX(l.loaned_at)       AS last_loaned_at
+		FROM book b
+		         JOIN book_stock bs ON bs.fk_book_id = b.id AND bs.status != 'withdrawn'
+		         LEFT JOIN author a ON b.fk_author_id = a.id
+		         LEFT JOIN loan l ON l.fk_book_stock_id = bs.id
+		WHERE b.created_at < CURRENT_TIMESTAMP - make_interval(months => $1)
+		GROUP BY b.id, a.name
+		HAVING MAX(l.loaned_at) IS NULL
+		    OR MAX(l.loaned_at) < CURRENT_TIMESTAMP - make_interval(months => $1)
+		ORDER BY last_loaned_at NULLS FIRST, b.title;
+	`
+
+	rows, err := rr.db.Query(query, months)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unused titles: %v", err)
+	}
+	defer rows.Close()
+
+	var reports []model.UnusedTitleReport
+	for rows.Next() {
+		var report model.UnusedTitleReport
+		var lastLoanedAt sql.NullTime
+		err = rows.Scan(&report.BookId, &report.BookTitle, &report.AuthorName, &report.CopyCount, &lastLoanedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning unused title: %v", err)
+		}
+		if lastLoanedAt.Valid {
+			report.LastLoanedAt = &lastLoanedAt.Time
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unused titles: %v", err)
+	}
+	return &reports, nil
+}
+
+func (rr *reportRepository) GetTitleCirculation(months, limit int, ascending bool) (*[]model.TitleCirculationReport, error) {
+	// months = 0 considera todo o histórico de empréstimos
+	query := `
+		SELECT b.id,
+		       b.title,
+		       COUNT(l.id) AS loan_count
+		FROM book b
+		         LEFT JOIN book_stock bs ON bs.fk_book_id = b.id
+		         LEFT JOIN loan l ON l.fk_book_stock_id = bs.id
+		    AND ($1 = 0 OR l.loaned_at >= CURRENT_TIMESTAMP - make_interval(months => $1))
+		GROUP BY b.id
+		ORDER BY loan_count ` + sortDirection(ascending) + `, b.title
+		LIMIT $2;
+	`
+
+	rows, err := rr.db.Query(query, months, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting title circulation: %v", err)
+	}
+	defer rows.Close()
+
+	var reports []model.TitleCirculationReport
+	for rows.Next() {
+		var report model.TitleCirculationReport
+		if err = rows.Scan(&report.BookId, &report.BookTitle, &report.LoanCount); err != nil {
+			return nil, fmt.Errorf("error scanning title circulation: %v", err)
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating title circulation: %v", err)
+	}
+	return &reports, nil
+}
+
+func (rr *reportRepository) GetGenreCirculation(months, limit int, ascending bool) (*[]model.GenreCirculationReport, error) {
+	query := `
+		SELECT g.id,
+		       g.name,
+		       COUNT(l.id) AS loan_count
+		FROM genre g
+		         LEFT JOIN book_genre bg ON bg.fk_genre_id = g.id
+		         LEFT JOIN book_stock bs ON bs.fk_book_id = bg.fk_book_id
+		         LEFT JOIN loan l ON l.fk_book_stock_id = bs.id
+		    AND ($1 = 0 OR l.loaned_at >= CURRENT_TIMESTAMP - make_interval(months => $1))
+		GROUP BY g.id
+		ORDER BY loan_count ` + sortDirection(ascending) + `, g.name
+		LIMIT $2;
+	`
+
+	rows, err := rr.db.Query(query, months, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting genre circulation: %v", err)
+	}
+	defer rows.Close()
+
+	var reports []model.GenreCirculationReport
+	for rows.Next() {
+		var report model.GenreCirculationReport
+		if err = rows.Scan(&report.GenreId, &report.GenreName, &report.LoanCount); err != nil {
+			return nil, fmt.Errorf("error scanning genre circulation: %v", err)
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating genre circulation: %v", err)
+	}
+	return &reports, nil
+}
+
+func (rr *reportRepository) GetDamagedCopies(minReports int) (*[]model.DamagedCopyReport, error) {
+	query := `
+		SELECT bs.id,
+		       bs.code,
+		       bs.status,
+		       bs.condition,
+		       b.id,
+		       b.title,
+		       COUNT(dr.id)        AS damage_count,
+		       MAX(dr.reported_at) AS last_reported_at
+		FROM damage_report dr
+		         JOIN book_stock bs ON dr.fk_book_stock_id = bs.id
+		         JOIN book b ON bs.fk_book_id = b.id
+		WHERE bs.status != 'withdrawn'
+		GROUP BY bs.id, b.id
+		HAVING COUNT(dr.id) >= $1
+		ORDER BY damage_count DESC, last_reported_at DESC;
+	`
+
+	rows, err := rr.db.Query(query, minReports)
+	if err != nil {
+		return nil, fmt.Errorf("error getting damaged copies: %v", err)
+	}
+	defer rows.Close()
+
+	var reports []model.DamagedCopyReport
+	for rows.Next() {
+		var report model.DamagedCopyReport
+		err = rows.Scan(&report.BookStockId, &report.Code, &report.Status, &report.Condition,
+			&report.BookId, &report.BookTitle, &report.DamageCount, &report.LastReportedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning damaged copy: %v", err)
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating damaged copies: %v", err)
+	}
+	return &reports, nil
+}
+
+func (rr *reportRepository) GetWaitlistPressure(minRatio float64) (*[]model.WaitlistReport, error) {
+	// Livros sem exemplares em circulação e com reservas pendentes sempre entram no relatório
+	query := `
+		SELECT id, title, copy_count, pending_reservations,
+		       pending_reservations::NUMERIC / NULLIF(copy_count, 0) AS ratio
+		FROM (SELECT b.id,
+		             b.title,
+		             (SELECT COUNT(*)
+		              FROM book_stock bs
+		              WHERE bs.fk_book_id = b.id
+		                AND bs.status IN ('available', 'borrowed')) AS copy_count,
+		             (SELECT COUNT(*)
+		              FROM reservation r
+		              WHERE r.fk_book_id = b.id
+		                AND r.status = 'pending'
+		                AND r.expires_at > CURRENT_TIMESTAMP)     AS pending_reservations
+		      FROM book b) t
+		WHERE pending_reservations > 0
+		  AND (copy_count = 0 OR pending_reservations::NUMERIC / copy_count >= $1)
+		ORDER BY ratio DESC NULLS FIRST, pending_reservations DESC, title;
+	`
+
+	rows, err := rr.db.Query(query, minRatio)
+	if err != nil {
+		return nil, fmt.Errorf("error getting waitlist pressure: %v", err)
+	}
+	defer rows.Close()
+
+	var reports []model.WaitlistReport
+	for rows.Next() {
+		var report model.WaitlistReport
+		var ratio sql.NullFloat64
+		err = rows.Scan(&report.BookId, &report.BookTitle, &report.CopyCount, &report.PendingReservations, &ratio)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning waitlist pressure: %v", err)
+		}
+		if ratio.Valid {
+			report.Ratio = &ratio.Float64
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating waitlist pressure: %v", err)
+	}
+	return &reports, nil
+}
+
+func (rr *reportRepository) GetExpiringMemberships(days int) (*[]model.ExpiringMembershipReport, error) {
+	query := `
+		SELECT id, name, email, phone, membership_expires_on, membership_expires_on - CURRENT_DATE AS days_left
+		FROM user_account
+		WHERE is_active
+		  AND anonymized_at IS NULL
+		  AND membership_expires_on BETWEEN CURRENT_DATE AND CURRENT_DATE + $1::INTEGER
+		ORDER BY membership_expires_on, name;
+	`
+
+	rows, err := rr.db.Query(query, days)
+	if err != nil {
+		return nil, fmt.Errorf("error getting expiring memberships: %v", err)
+	}
+	defer rows.Close()
+
+	var reports []model.ExpiringMembershipReport
+	for rows.Next() {
+		var report model.ExpiringMembershipReport
+		err = rows.Scan(&report.UserId, &report.Name, &report.Email, &report.Phone, &report.ExpiresOn, &report.DaysLeft)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning expiring membership: %v", err)
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expiring memberships: %v", err)
+	}
+	return &reports, nil
+}
+
+func sortDirection(ascending bool) string {
+	if ascending {
+		return "ASC"
+	}
+	return "DESC"
+}
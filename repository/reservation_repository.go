@@ -10,7 +10,7 @@ import (
 )
 
 type ReservationRepository interface {
-	CreateReservation(borrowedDays, userId, bookId int) (*model.Reservation, error)
+	CreateReservation(borrowedDays, userId, bookId int, adminId *int) (*model.Reservation, error)
 	GetReservationsByFilters(userName string, status model.ReservationStatus, reservedAt string) (*[]model.Reservation, error)
 	GetReservationsByBookId(id int, status string) (*[]model.Reservation, error)
 	GetReservationById(id int) (*model.Reservation, error)
@@ -26,14 +26,17 @@ func NewReservationRepository(db *sql.DB) ReservationRepository {
 	return &reservationRepository{db}
 }
 
-func (rr *reservationRepository) CreateReservation(borrowedDays, userId, bookId int) (*model.Reservation, error) {
+// CreateReservation cria uma reserva pendente para o usuário. adminId é informado quando a reserva é feita por um
+// funcionário em nome do leitor.
+func (rr *reservationRepository) CreateReservation(borrowedDays, userId, bookId int, adminId *int) (*model.Reservation, error) {
 	query := `
-		INSERT INTO reservation (borrowed_days, fk_user_id, fk_book_id)
-		VALUES ($1, $2, $3)
+		INSERT INTO reservation (borrowed_days, fk_user_id, fk_book_id, fk_admin_id, expires_at)
+		SELECT $1, $2, $3, $4, add_open_hours(CURRENT_TIMESTAMP::TIMESTAMP, p.reservation_window_hours)
+		FROM circulation_policy p
 		RETURNING id, reserved_at, expires_at`
 
 	var res model.Reservation
-	err := rr.db.QueryRow(query, borrowedDays, userId, bookId).Scan(&res.Id, &res.ReservedAt, &res.ExpiresAt)
+	err := rr.db.QueryRow(query, borrowedDays, userId, bookId, adminId).Scan(&res.Id, &res.ReservedAt, &res.ExpiresAt)
 	if err != nil {
 		return nil, err
 	}
@@ -232,6 +235,10 @@ func (rr *reservationRepository) GetReservationsByBookId(id int, status string)
 		query += "AND status = $2"
 		args = append(args, status)
 	}
+	// Uma reserva vencida continua 'pending' no banco até ser marcada como expirada, mas já não está mais na fila
+	if status == string(model.ReservationPending) {
+		query += " AND r.expires_at > CURRENT_TIMESTAMP"
+	}
 
 	rows, err := rr.db.Query(query, args...)
 	if err != nil {
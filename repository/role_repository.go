@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model/user"
+)
+
+type RoleRepository interface {
+	GetUserPermissions(userId int) ([]string, error)
+	GetPermissions() (*[]user.Permission, error)
+	GetRoles() (*[]user.AccountRole, error)
+	GetRoleById(id int) (*user.AccountRole, error)
+	CreateRole(name string, requiresTwoFactor bool, permissions []string) (*user.AccountRole, error)
+	UpdateRole(id int, name string, requiresTwoFactor bool) error
+	SetRolePermissions(id int, permissions []string) error
+	DeleteRole(id int) error
+}
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// GetUserPermissions retorna as permissões da role atual do usuário.
+func (rr *roleRepository) GetUserPermissions(userId int) ([]string, error) {
+	query := `
+		SELECT p.name
+		FROM user_account ua
+		         JOIN role_permission rp ON rp.fk_role_id = ua.fk_account_role
+		         JOIN permission p ON rp.fk_permission_id = p.id
+		WHERE ua.id = $1;
+	`
+
+	rows, err := rr.db.Query(query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user permissions: %v", err)
+	}
+	defer rows.Close()
+
+	permissions := make([]string, 0)
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, fmt.Errorf("error scanning user permission: %v", err)
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
+}
+
+func (rr *roleRepository) GetPermissions() (*[]user.Permission, error) {
+	rows, err := rr.db.Query(`SELECT id, name, description FROM permission ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting permissions: %v", err)
+	}
+	defer rows.Close()
+
+	permissions := make([]user.Permission, 0)
+	for rows.Next() {
+		var permission user.Permission
+		if err := rows.Scan(&permission.Id, &permission.Name, &permission.Description); err != nil {
+			return nil, fmt.Errorf("error scanning permission: %v", err)
+		}
+		permissions = append(permissions, permission)
+	}
+	return &permissions, nil
+}
+
+const accountRoleQuery = `
+	SELECT r.id,
+	       r.name,
+	       r.requires_2fa,
+	       p.name AS permission_name
+	FROM account_role r
+	         LEFT JOIN role_permission rp ON rp.fk_role_id = r.id
+	         LEFT JOIN permission p ON rp.fk_permission_id = p.id
+`
+
+// scanAccountRoles agrupa as linhas de accountRoleQuery por role, mantendo a ordem das roles.
+func scanAccountRoles(rows *sql.Rows) ([]user.AccountRole, error) {
+	roles := make([]user.AccountRole, 0)
+	index := make(map[int]int)
+
+	for rows.Next() {
+		var role user.AccountRole
+		var permission *string
+		if err := rows.Scan(&role.Id, &role.Name, &role.RequiresTwoFactor, &permission); err != nil {
+			return nil, fmt.Errorf("error scanning account role: %v", err)
+		}
+
+		i, exists := index[role.Id]
+		if !exists {
+			role.Permissions = make([]string, 0)
+			roles = append(roles, role)
+			i = len(roles) - 1
+			index[role.Id] = i
+		}
+		if permission != nil {
+			roles[i].Permissions = append(roles[i].Permissions, *permission)
+		}
+	}
+	return roles, nil
+}
+
+func (rr *roleRepository) GetRoles() (*[]user.AccountRole, error) {
+	rows, err := rr.db.Query(accountRoleQuery + ` ORDER BY r.id, p.name`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting account roles: %v", err)
+	}
+	defer rows.Close()
+
+	roles, err := scanAccountRoles(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &roles, nil
+}
+
+func (rr *roleRepository) GetRoleById(id int) (*user.AccountRole, error) {
+	rows, err := rr.db.Query(accountRoleQuery+` WHERE r.id = $1 ORDER BY p.name`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting account role: %v", err)
+	}
+	defer rows.Close()
+
+	roles, err := scanAccountRoles(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("account role with id %d not found", id)
+	}
+	return &roles[0], nil
+}
+
+func (rr *roleRepository) CreateRole(name string, requiresTwoFactor bool, permissions []string) (*user.AccountRole, error) {
+	tx, err := rr.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	role := user.AccountRole{Name: name, RequiresTwoFactor: requiresTwoFactor, Permissions: permissions}
+	err = tx.QueryRow(`
+		INSERT INTO account_role (name, requires_2fa)
+		VALUES ($1, $2)
+		RETURNING id
+	`, name, requiresTwoFactor).Scan(&role.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error creating account role: %v", err)
+	}
+
+	if err := insertRolePermissions(tx, role.Id, permissions); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return &role, nil
+}
+
+func (rr *roleRepository) UpdateRole(id int, name string, requiresTwoFactor bool) error {
+	query := `
+		UPDATE account_role
+		SET name = $1, requires_2fa = $2
+		WHERE id = $3
+		RETURNING id
+	`
+	var roleId int
+	err := rr.db.QueryRow(query, name, requiresTwoFactor, id).Scan(&roleId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("account role with id %d not found", id)
+		}
+		return fmt.Errorf("error updating account role: %v", err)
+	}
+	return nil
+}
+
+// SetRolePermissions substitui todas as permissões da role.
+func (rr *roleRepository) SetRolePermissions(id int, permissions []string) error {
+	tx, err := rr.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM role_permission WHERE fk_role_id = $1`, id); err != nil {
+		return fmt.Errorf("error removing role permissions: %v", err)
+	}
+
+	if err := insertRolePermissions(tx, id, permissions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertRolePermissions(tx *sql.Tx, roleId int, permissions []string) error {
+	for _, permission := range permissions {
+		result, err := tx.Exec(`
+			INSERT INTO role_permission (fk_role_id, fk_permission_id)
+			SELECT $1, id
+			FROM permission
+			WHERE name = $2
+			ON CONFLICT DO NOTHING
+		`, roleId, permission)
+		if err != nil {
+			return fmt.Errorf("error granting permission '%s': %v", permission, err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			var exists bool
+			if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM permission WHERE name = $1)`, permission).Scan(&exists); err != nil {
+				return fmt.Errorf("error checking permission '%s': %v", permission, err)
+			}
+			if !exists {
+				return fmt.Errorf("permission '%s' not found", permission)
+			}
+		}
+	}
+	return nil
+}
+
+func (rr *roleRepository) DeleteRole(id int) error {
+	query := `
+		DELETE FROM account_role
+		WHERE id = $1
+		RETURNING id
+	`
+	var roleId int
+	err := rr.db.QueryRow(query, id).Scan(&roleId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("account role with id %d not found", id)
+		}
+		return fmt.Errorf("error deleting account role: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model/user"
+	"time"
+)
+
+type SessionRepository interface {
+	CreateSession(userId int, refreshTokenHash, ipAddress, userAgent string, ttl time.Duration) (int, error)
+	RotateRefreshToken(refreshTokenHash, newRefreshTokenHash string) (*user.Session, error)
+	IsSessionActive(id, userId int) (bool, error)
+	RevokeSession(id, userId int) error
+	RevokeUserSessions(userId int) error
+	GetUserSessions(userId int) (*[]user.Session, error)
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (sr *sessionRepository) CreateSession(userId int, refreshTokenHash, ipAddress, userAgent string, ttl time.Duration) (int, error) {
+	query := `
+		INSERT INTO user_session (refresh_token_hash, ip_address, user_agent, expires_at, fk_user_id)
+		VALUES ($1, NULLIF($2, ''), NULLIF(LEFT($3, 255), ''), CURRENT_TIMESTAMP + MAKE_INTERVAL(secs => $4), $5)
+		RETURNING id;
+	`
+
+	var id int
+	err := sr.db.QueryRow(query, refreshTokenHash, ipAddress, userAgent, ttl.Seconds(), userId).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error creating session: %v", err)
+	}
+	return id, nil
+}
+
+// RotateRefreshToken troca o token de atualização de uma sessão ativa. Se o token informado já tiver sido trocado
+// antes, ele foi reutilizado (possivelmente roubado) e a sessão é revogada.
+func (sr *sessionRepository) RotateRefreshToken(refreshTokenHash, newRefreshTokenHash string) (*user.Session, error) {
+	tx, err := sr.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var session user.Session
+	var isCurrent, isActive bool
+	err = tx.QueryRow(`
+		SELECT s.id,
+		       s.fk_user_id,
+		       s.refresh_token_hash = $1                                                  AS is_current,
+		       s.revoked_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP AND ua.is_active AS is_active
+		FROM user_session s
+		         JOIN user_account ua ON s.fk_user_id = ua.id
+		WHERE s.refresh_token_hash = $1
+		   OR s.previous_token_hash = $1
+		FOR UPDATE OF s
+	`, refreshTokenHash).Scan(&session.Id, &session.UserId, &isCurrent, &isActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, fmt.Errorf("error getting session: %v", err)
+	}
+
+	if !isCurrent {
+		_, err = tx.Exec(`UPDATE user_session SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`, session.Id)
+		if err != nil {
+			return nil, fmt.Errorf("error revoking session: %v", err)
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, fmt.Errorf("error committing transaction: %v", err)
+		}
+		return nil, errors.New("invalid refresh token")
+	}
+
+	if !isActive {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	err = tx.QueryRow(`
+		UPDATE user_session
+		SET previous_token_hash = refresh_token_hash,
+		    refresh_token_hash  = $1,
+		    last_used_at        = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING created_at, last_used_at, expires_at
+	`, newRefreshTokenHash, session.Id).Scan(&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error rotating refresh token: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return &session, nil
+}
+
+// IsSessionActive informa se a sessão do token de acesso não foi revogada nem expirou e se o usuário ainda está
+// ativo.
+func (sr *sessionRepository) IsSessionActive(id, userId int) (bool, error) {
+	query := `
+		SELECT s.revoked_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP AND ua.is_active
+		FROM user_session s
+		         JOIN user_account ua ON s.fk_user_id = ua.id
+		WHERE s.id = $1 AND s.fk_user_id = $2;
+	`
+
+	var active bool
+	err := sr.db.QueryRow(query, id, userId).Scan(&active)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking session: %v", err)
+	}
+	return active, nil
+}
+
+func (sr *sessionRepository) RevokeSession(id, userId int) error {
+	query := `
+		UPDATE user_session
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND fk_user_id = $2 AND revoked_at IS NULL;
+	`
+
+	_, err := sr.db.Exec(query, id, userId)
+	if err != nil {
+		return fmt.Errorf("error revoking session: %v", err)
+	}
+	return nil
+}
+
+func (sr *sessionRepository) RevokeUserSessions(userId int) error {
+	_, err := sr.db.Exec(revokeUserSessionsQuery, userId, nil)
+	if err != nil {
+		return fmt.Errorf("error revoking user sessions: %v", err)
+	}
+	return nil
+}
+
+// GetUserSessions retorna todas as sessões do usuário, inclusive as revogadas e expiradas.
+func (sr *sessionRepository) GetUserSessions(userId int) (*[]user.Session, error) {
+	query := `
+		SELECT id, fk_user_id, COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at, last_used_at,
+		       expires_at, revoked_at
+		FROM user_session
+		WHERE fk_user_id = $1
+		ORDER BY created_at DESC;
+	`
+
+	rows, err := sr.db.Query(query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user sessions: %v", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]user.Session, 0)
+	for rows.Next() {
+		var session user.Session
+		if err := rows.Scan(
+			&session.Id,
+			&session.UserId,
+			&session.IpAddress,
+			&session.UserAgent,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+			&session.RevokedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning user session: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return &sessions, nil
+}
+
+// revokeUserSessionsQuery revoga todas as sessões ativas do usuário ($1), exceto a sessão $2 quando informada.
+const revokeUserSessionsQuery = `
+	UPDATE user_session
+	SET revoked_at = CURRENT_TIMESTAMP
+	WHERE fk_user_id = $1 AND revoked_at IS NULL AND ($2::INTEGER IS NULL OR id != $2);
+`
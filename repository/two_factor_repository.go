@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model/user"
+)
+
+type TwoFactorRepository interface {
+	GetTOTP(userId int) (*user.TOTP, error)
+	SaveTOTPSecret(userId int, secret string) error
+	ConfirmTOTP(userId int, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(userId int, step int64) (bool, error)
+	UseRecoveryCode(userId int, codeHash string) (bool, error)
+	DeleteTOTP(userId int) error
+}
+
+type twoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// GetTOTP retorna o segredo TOTP do usuário, ou nil se ele nunca iniciou o cadastro do autenticador.
+func (tr *twoFactorRepository) GetTOTP(userId int) (*user.TOTP, error) {
+	query := `
+		SELECT fk_user_id, secret, confirmed_at, last_used_step, created_at
+		FROM user_totp
+		WHERE fk_user_id = $1;
+	`
+
+	var totp user.TOTP
+	err := tr.db.QueryRow(query, userId).Scan(&totp.UserId, &totp.Secret, &totp.ConfirmedAt, &totp.LastUsedStep, &totp.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting user totp: %v", err)
+	}
+	return &totp, nil
+}
+
+// SaveTOTPSecret grava um novo segredo ainda não confirmado, substituindo um cadastro anterior não confirmado.
+func (tr *twoFactorRepository) SaveTOTPSecret(userId int, secret string) error {
+	query := `
+		INSERT INTO user_totp (fk_user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (fk_user_id) DO UPDATE
+		    SET secret         = EXCLUDED.secret,
+		        confirmed_at   = NULL,
+		        last_used_step = NULL,
+		        created_at     = CURRENT_TIMESTAMP
+		WHERE user_totp.confirmed_at IS NULL
+		RETURNING fk_user_id;
+	`
+
+	var id int
+	err := tr.db.QueryRow(query, userId, secret).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("two-factor authentication is already enabled")
+		}
+		return fmt.Errorf("error saving user totp: %v", err)
+	}
+	return nil
+}
+
+// ConfirmTOTP ativa o segredo do usuário e substitui os códigos de recuperação.
+func (tr *twoFactorRepository) ConfirmTOTP(userId int, step int64, recoveryCodeHashes []string) error {
+	tx, err := tr.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE user_totp
+		SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $2
+		WHERE fk_user_id = $1 AND confirmed_at IS NULL
+	`, userId, step)
+	if err != nil {
+		return fmt.Errorf("error confirming user totp: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.New("no pending two-factor enrollment found")
+	}
+
+	if _, err = tx.Exec(`DELETE FROM totp_recovery_code WHERE fk_user_id = $1`, userId); err != nil {
+		return fmt.Errorf("error deleting recovery codes: %v", err)
+	}
+
+	for _, codeHash := range recoveryCodeHashes {
+		_, err = tx.Exec(`INSERT INTO totp_recovery_code (code_hash, fk_user_id) VALUES ($1, $2)`, codeHash, userId)
+		if err != nil {
+			return fmt.Errorf("error creating recovery code: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep registra o uso de um código. Retorna false se um código do mesmo intervalo (ou posterior) já foi usado.
+func (tr *twoFactorRepository) UseTOTPStep(userId int, step int64) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE fk_user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2);
+	`
+
+	result, err := tr.db.Exec(query, userId, step)
+	if err != nil {
+		return false, fmt.Errorf("error using totp code: %v", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// UseRecoveryCode consome um código de recuperação. Retorna false se o código não existir ou já tiver sido usado.
+func (tr *twoFactorRepository) UseRecoveryCode(userId int, codeHash string) (bool, error) {
+	query := `
+		UPDATE totp_recovery_code
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE fk_user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+	`
+
+	result, err := tr.db.Exec(query, userId, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("error using recovery code: %v", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// DeleteTOTP remove o autenticador e os códigos de recuperação do usuário.
+func (tr *twoFactorRepository) DeleteTOTP(userId int) error {
+	tx, err := tr.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM totp_recovery_code WHERE fk_user_id = $1`, userId); err != nil {
+		return fmt.Errorf("error deleting recovery codes: %v", err)
+	}
+	if _, err = tx.Exec(`DELETE FROM user_totp WHERE fk_user_id = $1`, userId); err != nil {
+		return fmt.Errorf("error deleting user totp: %v", err)
+	}
+
+	return tx.Commit()
+}
@@ -7,10 +7,22 @@ import (
 	"go-api/model"
 	"go-api/model/user"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
-	CreateUser(name, cpf, phone, email, passwordHash string, fkAccountRole int) (*int, error)
+	CreateUser(name, cpf, phone, email, passwordHash string, fkAccountRole int, patronCategoryId *int, status user.AccountStatus) (*int, error)
+	GetRoleByName(name string) (*user.AccountRole, error)
+	VerifyUser(id int) error
+	UpdateProfile(id int, name, phone string, pendingEmail *string) error
+	ConfirmEmailChange(id int, email string) error
+	GetUserPasswordHash(id int) (string, error)
+	UpdatePassword(id int, passwordHash string, keepSessionId *int) error
+	CreatePasswordResetToken(id int, tokenHash string, ttl time.Duration) error
+	ResetPassword(tokenHash, passwordHash string) error
 	GetUserByEmail(email string) (*user.Account, error)
 	GetUsersByFilters(name, email string) (*[]user.Account, error)
 	GetUserById(id int) (*user.Account, error)
@@ -19,8 +31,13 @@ type UserRepository interface {
 	ActivateUser(id int) error
 	DeactivateUser(id int) error
 	DeleteUser(id int) error
+	HasCirculationHistory(id int) (bool, error)
+	AnonymizeUser(id int) error
+	RenewMembership(id, months int) error
+	SetPatronCategory(id, patronCategoryId int) error
 	GetUserReservationById(id, reservationId int) (*model.Reservation, error)
 	CancelUserReservation(id, reservationId int, adminId *int) error
+	GetUserFines(id int) (*[]model.Fine, error)
 }
 
 type userRepository struct {
@@ -31,20 +48,125 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db}
 }
 
-func (ur *userRepository) CreateUser(name, cpf, phone, email, passwordHash string, fkAccountRole int) (*int, error) {
+// UserConflictError indica que o cpf, telefone ou email informado já pertence a outra conta.
+type UserConflictError struct {
+	Field string
+}
+
+func (e *UserConflictError) Error() string {
+	return fmt.Sprintf("a user with this %s already exists", e.Field)
+}
+
+// userConflictError converte uma violação das restrições UNIQUE de user_account em *UserConflictError. Retorna nil
+// para qualquer outro erro.
+func userConflictError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		field := strings.TrimSuffix(strings.TrimPrefix(pqErr.Constraint, "user_account_"), "_key")
+		return &UserConflictError{Field: field}
+	}
+	return nil
+}
+
+// CreateUser cria a conta e retorna o seu Id. Sem uma categoria de leitor informada, a conta recebe a categoria
+// padrão (user.DefaultPatronCategory).
+func (ur *userRepository) CreateUser(name, cpf, phone, email, passwordHash string, fkAccountRole int, patronCategoryId *int, status user.AccountStatus) (*int, error) {
 	query := `
-        INSERT INTO user_account (name, cpf, phone, email, password_hash, fk_account_role)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO user_account (name, cpf, phone, email, password_hash, fk_account_role, status, fk_patron_category)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, (SELECT id FROM patron_category WHERE name = $9)))
         RETURNING id
     `
 	var userId int
-	err := ur.db.QueryRow(query, name, cpf, phone, email, passwordHash, fkAccountRole).Scan(&userId)
+	err := ur.db.QueryRow(query, name, cpf, phone, email, passwordHash, fkAccountRole, status, patronCategoryId,
+		user.DefaultPatronCategory).Scan(&userId)
 	if err != nil {
+		if conflictErr := userConflictError(err); conflictErr != nil {
+			return nil, conflictErr
+		}
 		return nil, fmt.Errorf("error creating user: %v", err)
 	}
 	return &userId, nil
 }
 
+func (ur *userRepository) GetRoleByName(name string) (*user.AccountRole, error) {
+	query := `SELECT id, name FROM account_role WHERE name = $1`
+
+	var role user.AccountRole
+	err := ur.db.QueryRow(query, name).Scan(&role.Id, &role.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("account role '%s' not found", name)
+		}
+		return nil, fmt.Errorf("error getting account role: %v", err)
+	}
+	return &role, nil
+}
+
+// VerifyUser marca o email da conta como verificado.
+func (ur *userRepository) VerifyUser(id int) error {
+	query := `
+	UPDATE user_account
+	SET status = 'verified'
+	WHERE id = $1
+	RETURNING id
+	`
+	var userId int
+	err := ur.db.QueryRow(query, id).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user with id %d not found", id)
+		}
+		return fmt.Errorf("error verifying user: %v", err)
+	}
+	return nil
+}
+
+// UpdateProfile atualiza os dados de contato do usuário. O novo email fica pendente até ser confirmado; um
+// pendingEmail nulo descarta uma troca de email em andamento.
+func (ur *userRepository) UpdateProfile(id int, name, phone string, pendingEmail *string) error {
+	query := `
+	UPDATE user_account
+	SET name = $2, phone = $3, pending_email = $4
+	WHERE id = $1
+	RETURNING id
+	`
+	var userId int
+	err := ur.db.QueryRow(query, id, name, phone, pendingEmail).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user with id %d not found", id)
+		}
+		if conflictErr := userConflictError(err); conflictErr != nil {
+			return conflictErr
+		}
+		return fmt.Errorf("error updating user profile: %v", err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange substitui o email da conta pelo email pendente, desde que ele ainda seja o informado.
+// Confirmar o novo email também verifica a conta.
+func (ur *userRepository) ConfirmEmailChange(id int, email string) error {
+	query := `
+	UPDATE user_account
+	SET email = pending_email, pending_email = NULL, status = 'verified'
+	WHERE id = $1 AND pending_email = $2
+	RETURNING id
+	`
+	var userId int
+	err := ur.db.QueryRow(query, id, email).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no pending email change found for user with id %d", id)
+		}
+		if conflictErr := userConflictError(err); conflictErr != nil {
+			return conflictErr
+		}
+		return fmt.Errorf("error confirming email change: %v", err)
+	}
+	return nil
+}
+
 func (ur *userRepository) GetUserByEmail(email string) (*user.Account, error) {
 	query := `
 	SELECT 
@@ -54,9 +176,11 @@ func (ur *userRepository) GetUserByEmail(email string) (*user.Account, error) {
 	       ua.phone          AS user_phone,
 	       ua.email          AS user_email,
 	       ua.password_hash  AS user_password_hash,
+	       ua.status         AS user_status,
 	       ua.is_active      AS user_is_active,
 	       ar.id             AS account_role_id,
-	       ar.name           AS account_role_name
+	       ar.name           AS account_role_name,
+	       ar.requires_2fa   AS account_role_requires_2fa
 	FROM 
 	       user_account ua
 	JOIN
@@ -72,9 +196,11 @@ func (ur *userRepository) GetUserByEmail(email string) (*user.Account, error) {
 		&userAccount.Phone,
 		&userAccount.Email,
 		&userAccount.PasswordHash,
+		&userAccount.Status,
 		&userAccount.IsActive,
 		&userAccount.AccountRole.Id,
 		&userAccount.AccountRole.Name,
+		&userAccount.AccountRole.RequiresTwoFactor,
 	)
 
 	if err != nil {
@@ -95,13 +221,27 @@ func (ur *userRepository) GetUsersByFilters(name, email string) (*[]user.Account
 	       ua.cpf            AS user_cpf,
 	       ua.phone          AS user_phone,
 	       ua.email          AS user_email,
+	       ua.status         AS user_status,
 	       ua.is_active      AS user_is_active,
+	       ua.anonymized_at  AS user_anonymized_at,
+	       ua.membership_started_on,
+	       ua.membership_expires_on,
+	       ua.membership_expires_on < CURRENT_DATE AS membership_expired,
 	       ar.id             AS account_role_id,
-	       ar.name           AS account_role_name
+	       ar.name           AS account_role_name,
+	       ar.requires_2fa   AS account_role_requires_2fa,
+	       pc.id             AS patron_category_id,
+	       pc.name           AS patron_category_name,
+	       pc.max_items      AS patron_category_max_items,
+	       pc.loan_days      AS patron_category_loan_days,
+	       pc.max_renewals   AS patron_category_max_renewals,
+	       pc.daily_fine_rate AS patron_category_daily_fine_rate
 	FROM 
 	       user_account ua
 	JOIN
 	       account_role ar ON ua.fk_account_role = ar.id
+	JOIN
+	       patron_category pc ON ua.fk_patron_category = pc.id
 	WHERE 1=1`
 
 	var args []interface{}
@@ -125,19 +265,33 @@ func (ur *userRepository) GetUsersByFilters(name, email string) (*[]user.Account
 	userAccountList := make([]user.Account, 0)
 	for rows.Next() {
 		var userAccount user.Account
+		var patronCategory user.PatronCategory
 		err := rows.Scan(
 			&userAccount.Id,
 			&userAccount.Name,
 			&userAccount.Cpf,
 			&userAccount.Phone,
 			&userAccount.Email,
+			&userAccount.Status,
 			&userAccount.IsActive,
+			&userAccount.AnonymizedAt,
+			&userAccount.Membership.StartedOn,
+			&userAccount.Membership.ExpiresOn,
+			&userAccount.Membership.Expired,
 			&userAccount.AccountRole.Id,
 			&userAccount.AccountRole.Name,
+			&userAccount.AccountRole.RequiresTwoFactor,
+			&patronCategory.Id,
+			&patronCategory.Name,
+			&patronCategory.MaxItems,
+			pq.Array(&patronCategory.LoanDays),
+			&patronCategory.MaxRenewals,
+			&patronCategory.DailyFineRate,
 		)
 		if err != nil {
 			return nil, err
 		}
+		userAccount.PatronCategory = &patronCategory
 		userAccountList = append(userAccountList, userAccount)
 	}
 	return &userAccountList, nil
@@ -151,16 +305,32 @@ func (ur *userRepository) GetUserById(id int) (*user.Account, error) {
 	       ua.cpf            AS user_cpf,
 	       ua.phone          AS user_phone,
 	       ua.email          AS user_email,
+	       ua.pending_email  AS user_pending_email,
+	       ua.status         AS user_status,
 	       ua.is_active      AS user_is_active,
+	       ua.anonymized_at  AS user_anonymized_at,
+	       ua.membership_started_on,
+	       ua.membership_expires_on,
+	       ua.membership_expires_on < CURRENT_DATE AS membership_expired,
 	       ar.id             AS account_role_id,
-	       ar.name           AS account_role_name
+	       ar.name           AS account_role_name,
+	       ar.requires_2fa   AS account_role_requires_2fa,
+	       pc.id             AS patron_category_id,
+	       pc.name           AS patron_category_name,
+	       pc.max_items      AS patron_category_max_items,
+	       pc.loan_days      AS patron_category_loan_days,
+	       pc.max_renewals   AS patron_category_max_renewals,
+	       pc.daily_fine_rate AS patron_category_daily_fine_rate
 	FROM 
 	       user_account ua
 	JOIN
 	       account_role ar ON ua.fk_account_role = ar.id
+	JOIN
+	       patron_category pc ON ua.fk_patron_category = pc.id
 	WHERE ua.id = $1`
 
 	var userAccount user.Account
+	var patronCategory user.PatronCategory
 
 	err := ur.db.QueryRow(query, id).Scan(
 		&userAccount.Id,
@@ -168,9 +338,22 @@ func (ur *userRepository) GetUserById(id int) (*user.Account, error) {
 		&userAccount.Cpf,
 		&userAccount.Phone,
 		&userAccount.Email,
+		&userAccount.PendingEmail,
+		&userAccount.Status,
 		&userAccount.IsActive,
+		&userAccount.AnonymizedAt,
+		&userAccount.Membership.StartedOn,
+		&userAccount.Membership.ExpiresOn,
+		&userAccount.Membership.Expired,
 		&userAccount.AccountRole.Id,
 		&userAccount.AccountRole.Name,
+		&userAccount.AccountRole.RequiresTwoFactor,
+		&patronCategory.Id,
+		&patronCategory.Name,
+		&patronCategory.MaxItems,
+		pq.Array(&patronCategory.LoanDays),
+		&patronCategory.MaxRenewals,
+		&patronCategory.DailyFineRate,
 	)
 
 	if err != nil {
@@ -179,6 +362,7 @@ func (ur *userRepository) GetUserById(id int) (*user.Account, error) {
 		}
 		return nil, err // Return other errors if they occur
 	}
+	userAccount.PatronCategory = &patronCategory
 
 	return &userAccount, nil
 }
@@ -302,7 +486,14 @@ func (ur *userRepository) DeactivateUser(id int) error {
 	return nil
 }
 
+// toggleUser ativa ou desativa o usuário. Ao desativar, todas as sessões do usuário são revogadas.
 func (ur *userRepository) toggleUser(id int, status bool) error {
+	tx, err := ur.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 	UPDATE user_account 
 	SET is_active = $1 
@@ -310,14 +501,21 @@ func (ur *userRepository) toggleUser(id int, status bool) error {
 	RETURNING id
 	`
 	var userId int
-	err := ur.db.QueryRow(query, status, id).Scan(&userId)
+	err = tx.QueryRow(query, status, id).Scan(&userId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return fmt.Errorf("user with id %d not found", id)
 		}
 		return err
 	}
-	return nil
+
+	if !status {
+		if _, err = tx.Exec(revokeUserSessionsQuery, id, nil); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (ur *userRepository) DeleteUser(id int) error {
@@ -334,6 +532,137 @@ func (ur *userRepository) DeleteUser(id int) error {
 	return nil
 }
 
+// RenewMembership estende a validade da carteirinha em months meses, contados a partir do vencimento atual ou, se
+// ela já estiver vencida, a partir de hoje.
+func (ur *userRepository) RenewMembership(id, months int) error {
+	query := `
+	UPDATE user_account
+	SET membership_expires_on = (GREATEST(membership_expires_on, CURRENT_DATE) + MAKE_INTERVAL(months => $2))::DATE
+	WHERE id = $1 AND anonymized_at IS NULL
+	RETURNING id
+	`
+	var userId int
+	err := ur.db.QueryRow(query, id, months).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user with id %d not found", id)
+		}
+		return fmt.Errorf("error renewing membership: %v", err)
+	}
+	return nil
+}
+
+func (ur *userRepository) SetPatronCategory(id, patronCategoryId int) error {
+	query := `
+	UPDATE user_account
+	SET fk_patron_category = $2
+	WHERE id = $1
+	RETURNING id
+	`
+	var userId int
+	err := ur.db.QueryRow(query, id, patronCategoryId).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user with id %d not found", id)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return fmt.Errorf("patron category with id %d not found", patronCategoryId)
+		}
+		return fmt.Errorf("error setting user patron category: %v", err)
+	}
+	return nil
+}
+
+// HasCirculationHistory informa se o usuário tem reservas ou multas, que seriam apagadas junto com a conta.
+func (ur *userRepository) HasCirculationHistory(id int) (bool, error) {
+	query := `
+	SELECT EXISTS (SELECT 1 FROM reservation WHERE fk_user_id = $1)
+	    OR EXISTS (SELECT 1 FROM fine WHERE fk_user_id = $1)
+	`
+	var hasHistory bool
+	if err := ur.db.QueryRow(query, id).Scan(&hasHistory); err != nil {
+		return false, fmt.Errorf("error checking user circulation history: %v", err)
+	}
+	return hasHistory, nil
+}
+
+// AnonymizeUser apaga os dados pessoais do usuário mantendo a conta, para que as reservas, empréstimos e multas
+// continuem nas estatísticas. Reservas pendentes são canceladas e sessões, tokens, o 2FA e as notificações são
+// removidos. Falha se o usuário tiver empréstimos não devolvidos ou multas não pagas.
+func (ur *userRepository) AnonymizeUser(id int) error {
+	tx, err := ur.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Bloqueia a conta para que nada seja alterado durante a anonimização
+	var email string
+	err = tx.QueryRow(`SELECT email FROM user_account WHERE id = $1 AND anonymized_at IS NULL FOR UPDATE`, id).Scan(&email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user with id %d not found or already anonymized", id)
+		}
+		return fmt.Errorf("error getting user: %v", err)
+	}
+
+	var openLoans, unpaidFines int
+	err = tx.QueryRow(`
+	SELECT (SELECT COUNT(*)
+	        FROM loan l
+	                 JOIN reservation r ON l.fk_reservation_id = r.id
+	        WHERE r.fk_user_id = $1 AND l.status = 'borrowed'),
+	       (SELECT COUNT(*) FROM fine WHERE fk_user_id = $1 AND paid_at IS NULL)
+	`, id).Scan(&openLoans, &unpaidFines)
+	if err != nil {
+		return fmt.Errorf("error checking user pending items: %v", err)
+	}
+	if openLoans > 0 {
+		return fmt.Errorf("user with id %d has %d loan(s) that have not been returned", id, openLoans)
+	}
+	if unpaidFines > 0 {
+		return fmt.Errorf("user with id %d has %d unpaid fine(s)", id, unpaidFines)
+	}
+
+	queries := []string{
+		`UPDATE reservation SET status = 'cancelled' WHERE fk_user_id = $1 AND status = 'pending'`,
+		`UPDATE purchase_suggestion SET notes = NULL, fk_user_id = NULL WHERE fk_user_id = $1`,
+		`DELETE FROM user_session WHERE fk_user_id = $1`,
+		`DELETE FROM password_reset_token WHERE fk_user_id = $1`,
+		`DELETE FROM totp_recovery_code WHERE fk_user_id = $1`,
+		`DELETE FROM user_totp WHERE fk_user_id = $1`,
+		// As notificações guardam email, telefone e o texto das mensagens enviadas ao usuário
+		`DELETE FROM notification_delivery WHERE fk_user_id = $1`,
+		`DELETE FROM notification WHERE fk_user_id = $1`,
+		`DELETE FROM notification_preference WHERE fk_user_id = $1`,
+		// Os valores fictícios respeitam as restrições UNIQUE NOT NULL; o cpf começa com uma letra para nunca
+		// coincidir com um cpf real
+		`UPDATE user_account
+		 SET name          = 'Anonymized user',
+		     cpf           = 'X' || LPAD(id::TEXT, 10, '0'),
+		     phone         = 'anonymized-' || id,
+		     email         = 'anonymized-' || id || '@invalid',
+		     pending_email = NULL,
+		     password_hash = '',
+		     is_active     = FALSE,
+		     anonymized_at = CURRENT_TIMESTAMP
+		 WHERE id = $1`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, id); err != nil {
+			return fmt.Errorf("error anonymizing user: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(`DELETE FROM login_lockout WHERE scope = 'account' AND subject = LOWER(TRIM($1))`, email)
+	if err != nil {
+		return fmt.Errorf("error anonymizing user: %v", err)
+	}
+
+	return tx.Commit()
+}
+
 func (ur *userRepository) GetUserReservationById(id, reservationId int) (*model.Reservation, error) {
 	query := `
 	SELECT r.id            AS reservation_id,
@@ -409,3 +738,164 @@ func (ur *userRepository) CancelUserReservation(id, reservationId int, adminId *
 	}
 	return nil
 }
+
+// GetUserFines retorna todas as cobranças feitas ao usuário, da mais recente à mais antiga.
+func (ur *userRepository) GetUserFines(id int) (*[]model.Fine, error) {
+	query := `
+	SELECT 
+	    f.id,
+	    f.amount,
+	    f.reason,
+	    f.issued_at,
+	    f.paid_at,
+	    f.fk_loan_id          AS loan_id,
+	    f.fk_damage_report_id AS damage_report_id
+	FROM 
+	    fine f
+	WHERE 
+	    f.fk_user_id = $1
+	ORDER BY
+	    f.issued_at DESC;`
+
+	rows, err := ur.db.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user fines: %w", err)
+	}
+	defer rows.Close()
+
+	fines := make([]model.Fine, 0)
+	for rows.Next() {
+		var fine model.Fine
+		if err := rows.Scan(
+			&fine.Id,
+			&fine.Amount,
+			&fine.Reason,
+			&fine.IssuedAt,
+			&fine.PaidAt,
+			&fine.LoanId,
+			&fine.DamageReportId,
+		); err != nil {
+			return nil, err
+		}
+		fine.UserId = id
+		fines = append(fines, fine)
+	}
+
+	return &fines, nil
+}
+
+func (ur *userRepository) GetUserPasswordHash(id int) (string, error) {
+	query := `SELECT password_hash FROM user_account WHERE id = $1`
+
+	var passwordHash string
+	err := ur.db.QueryRow(query, id).Scan(&passwordHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("user with id %d not found", id)
+		}
+		return "", fmt.Errorf("error getting user password: %v", err)
+	}
+	return passwordHash, nil
+}
+
+// UpdatePassword altera a senha do usuário e revoga todas as suas sessões, exceto keepSessionId quando informada.
+func (ur *userRepository) UpdatePassword(id int, passwordHash string, keepSessionId *int) error {
+	tx, err := ur.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var userId int
+	err = tx.QueryRow(`
+		UPDATE user_account
+		SET password_hash = $1
+		WHERE id = $2
+		RETURNING id
+	`, passwordHash, id).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user with id %d not found", id)
+		}
+		return fmt.Errorf("error updating user password: %v", err)
+	}
+
+	if _, err = tx.Exec(revokeUserSessionsQuery, id, keepSessionId); err != nil {
+		return fmt.Errorf("error revoking user sessions: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// CreatePasswordResetToken registra um novo token de redefinição de senha. Tokens anteriores ainda não usados
+// deixam de valer.
+func (ur *userRepository) CreatePasswordResetToken(id int, tokenHash string, ttl time.Duration) error {
+	tx, err := ur.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE password_reset_token
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE fk_user_id = $1 AND used_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("error invalidating password reset tokens: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO password_reset_token (token_hash, expires_at, fk_user_id)
+		VALUES ($1, CURRENT_TIMESTAMP + MAKE_INTERVAL(secs => $2), $3)
+	`, tokenHash, ttl.Seconds(), id)
+	if err != nil {
+		return fmt.Errorf("error creating password reset token: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword consome o token de redefinição e altera a senha do usuário. Como o token foi recebido por email,
+// a conta também é marcada como verificada. Todas as sessões do usuário são revogadas.
+func (ur *userRepository) ResetPassword(tokenHash, passwordHash string) error {
+	tx, err := ur.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var tokenId, userId int
+	err = tx.QueryRow(`
+		SELECT id, fk_user_id
+		FROM password_reset_token
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		FOR UPDATE
+	`, tokenHash).Scan(&tokenId, &userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("invalid or expired password reset token")
+		}
+		return fmt.Errorf("error getting password reset token: %v", err)
+	}
+
+	_, err = tx.Exec(`UPDATE password_reset_token SET used_at = CURRENT_TIMESTAMP WHERE id = $1`, tokenId)
+	if err != nil {
+		return fmt.Errorf("error using password reset token: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE user_account
+		SET password_hash = $1, status = 'verified'
+		WHERE id = $2
+	`, passwordHash, userId)
+	if err != nil {
+		return fmt.Errorf("error updating user password: %v", err)
+	}
+
+	if _, err = tx.Exec(revokeUserSessionsQuery, userId, nil); err != nil {
+		return fmt.Errorf("error revoking user sessions: %v", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/model"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepository interface {
+	GetEndpoints() (*[]model.WebhookEndpoint, error)
+	GetEndpointById(id int) (*model.WebhookEndpoint, error)
+	GetEndpointsByEvent(event model.WebhookEvent) (*[]model.WebhookEndpoint, error)
+	CreateEndpoint(endpoint model.WebhookEndpoint, adminId int) (*model.WebhookEndpoint, error)
+	UpdateEndpoint(endpoint model.WebhookEndpoint) error
+	DeleteEndpoint(id int) error
+	CreateDelivery(endpointId int, event model.OutboxEvent, body []byte, lease time.Duration) (*model.WebhookDelivery, error)
+	ClaimDeliveriesToRetry(lease time.Duration) (*[]model.WebhookDelivery, error)
+	GetDeliveries(endpointId int, status model.WebhookDeliveryStatus, event model.WebhookEvent) (*[]model.WebhookDelivery, error)
+	GetDeliveryById(id int) (*model.WebhookDelivery, error)
+	MarkDeliveryDelivered(id int, responseStatus *int) error
+	MarkDeliveryFailed(id int, responseStatus *int, deliveryErr string, maxAttempts int) error
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookEndpointQuery = `
+	SELECT id, url, COALESCE(description, ''), events, is_active, secret, created_at
+	FROM webhook_endpoint
+`
+
+func scanWebhookEndpoint(row interface{ Scan(dest ...any) error }) (*model.WebhookEndpoint, error) {
+	var endpoint model.WebhookEndpoint
+	var events []string
+	err := row.Scan(&endpoint.Id, &endpoint.Url, &endpoint.Description, pq.Array(&events), &endpoint.IsActive,
+		&endpoint.Secret, &endpoint.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		endpoint.Events = append(endpoint.Events, model.WebhookEvent(event))
+	}
+	return &endpoint, nil
+}
+
+func (wr *webhookRepository) queryEndpoints(query string, args ...any) (*[]model.WebhookEndpoint, error) {
+	rows, err := wr.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting webhook endpoints: %v", err)
+	}
+	defer rows.Close()
+
+	endpoints := make([]model.WebhookEndpoint, 0)
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook endpoint: %v", err)
+		}
+		endpoints = append(endpoints, *endpoint)
+	}
+	return &endpoints, nil
+}
+
+func (wr *webhookRepository) GetEndpoints() (*[]model.WebhookEndpoint, error) {
+	return wr.queryEndpoints(webhookEndpointQuery + ` ORDER BY id`)
+}
+
+func (wr *webhookRepository) GetEndpointById(id int) (*model.WebhookEndpoint, error) {
+	endpoint, err := scanWebhookEndpoint(wr.db.QueryRow(webhookEndpointQuery+` WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("webhook endpoint with id %d not found", id)
+		}
+		return nil, fmt.Errorf("error getting webhook endpoint: %v", err)
+	}
+	return endpoint, nil
+}
+
+// GetEndpointsByEvent retorna os webhooks ativos inscritos no evento.
+func (wr *webhookRepository) GetEndpointsByEvent(event model.WebhookEvent) (*[]model.WebhookEndpoint, error) {
+	return wr.queryEndpoints(webhookEndpointQuery+` WHERE is_active AND $1 = ANY(events) ORDER BY id`, event)
+}
+
+func eventNames(events []model.WebhookEvent) []string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = string(event)
+	}
+	return names
+}
+
+func (wr *webhookRepository) CreateEndpoint(endpoint model.WebhookEndpoint, adminId int) (*model.WebhookEndpoint, error) {
+	query := `
+	INSERT INTO webhook_endpoint (url, description, events, secret, is_active, fk_admin_id)
+	VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6)
+	RETURNING id, created_at
+	`
+	err := wr.db.QueryRow(query, endpoint.Url, endpoint.Description, pq.Array(eventNames(endpoint.Events)),
+		endpoint.Secret, endpoint.IsActive, adminId).Scan(&endpoint.Id, &endpoint.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook endpoint: %v", err)
+	}
+	return &endpoint, nil
+}
+
+// UpdateEndpoint altera a URL, a descrição, os eventos e a situação de um webhook. O segredo não é alterado.
+func (wr *webhookRepository) UpdateEndpoint(endpoint model.WebhookEndpoint) error {
+	query := `
+	UPDATE webhook_endpoint
+	SET url = $2, description = NULLIF($3, ''), events = $4, is_active = $5
+	WHERE id = $1
+	`
+	result, err := wr.db.Exec(query, endpoint.Id, endpoint.Url, endpoint.Description,
+		pq.Array(eventNames(endpoint.Events)), endpoint.IsActive)
+	if err != nil {
+		return fmt.Errorf("error updating webhook endpoint: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("webhook endpoint with id %d not found", endpoint.Id)
+	}
+	return nil
+}
+
+// DeleteEndpoint remove um webhook e o seu registro de entregas.
+func (wr *webhookRepository) DeleteEndpoint(id int) error {
+	result, err := wr.db.Exec(`DELETE FROM webhook_endpoint WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook endpoint: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("webhook endpoint with id %d not found", id)
+	}
+	return nil
+}
+
+const webhookDeliveryColumns = `
+	id, fk_endpoint_id, event_id, event, payload, status, attempts, response_status, last_error, next_attempt_at,
+	created_at, delivered_at
+`
+
+func scanWebhookDelivery(row interface{ Scan(dest ...any) error }) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	err := row.Scan(&d.Id, &d.EndpointId, &d.EventId, &d.Event, &d.Payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) (*[]model.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]model.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %v", err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return &deliveries, nil
+}
+
+// CreateDelivery registra a entrega de um evento a um webhook. A primeira tentativa é reservada por lease a quem a
+// criou; se ela não for concluída nesse prazo, a entrega é retomada pelo reenvio. Retorna nil se o evento já foi
+// registrado para o webhook.
+func (wr *webhookRepository) CreateDelivery(endpointId int, event model.OutboxEvent, body []byte, lease time.Duration) (*model.WebhookDelivery, error) {
+	query := `
+	INSERT INTO webhook_delivery (event_id, event, payload, next_attempt_at, fk_endpoint_id)
+	VALUES ($1, $2, $3, CURRENT_TIMESTAMP + MAKE_INTERVAL(secs => $4), $5)
+	ON CONFLICT (event_id, fk_endpoint_id) DO NOTHING
+	RETURNING` + webhookDeliveryColumns
+
+	delivery, err := scanWebhookDelivery(wr.db.QueryRow(query, event.EventId, event.Event, string(body), lease.Seconds(),
+		endpointId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error creating webhook delivery: %v", err)
+	}
+	return delivery, nil
+}
+
+// ClaimDeliveriesToRetry reserva por lease as entregas pendentes cuja próxima tentativa já pode ser feita. Entregas
+// reservadas por outra instância da API são ignoradas.
+func (wr *webhookRepository) ClaimDeliveriesToRetry(lease time.Duration) (*[]model.WebhookDelivery, error) {
+	query := `
+	UPDATE webhook_delivery
+	SET next_attempt_at = CURRENT_TIMESTAMP + MAKE_INTERVAL(secs => $1)
+	WHERE id IN (SELECT id
+	             FROM webhook_delivery
+	             WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+	             ORDER BY next_attempt_at
+	             LIMIT 100 FOR UPDATE SKIP LOCKED)
+	RETURNING` + webhookDeliveryColumns
+
+	rows, err := wr.db.Query(query, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %v", err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+func (wr *webhookRepository) GetDeliveries(endpointId int, status model.WebhookDeliveryStatus, event model.WebhookEvent) (*[]model.WebhookDelivery, error) {
+	query := `SELECT` + webhookDeliveryColumns + `FROM webhook_delivery WHERE 1=1`
+
+	var args []interface{}
+	if endpointId != 0 {
+		query += ` AND fk_endpoint_id = $` + strconv.Itoa(len(args)+1)
+		args = append(args, endpointId)
+	}
+	if status != "" {
+		query += ` AND status = $` + strconv.Itoa(len(args)+1)
+		args = append(args, status)
+	}
+	if event != "" {
+		query += ` AND event = $` + strconv.Itoa(len(args)+1)
+		args = append(args, event)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	rows, err := wr.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting webhook deliveries: %v", err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+func (wr *webhookRepository) GetDeliveryById(id int) (*model.WebhookDelivery, error) {
+	query := `SELECT` + webhookDeliveryColumns + `FROM webhook_delivery WHERE id = $1`
+	delivery, err := scanWebhookDelivery(wr.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("webhook delivery with id %d not found", id)
+		}
+		return nil, fmt.Errorf("error getting webhook delivery: %v", err)
+	}
+	return delivery, nil
+}
+
+func (wr *webhookRepository) MarkDeliveryDelivered(id int, responseStatus *int) error {
+	query := `
+	UPDATE webhook_delivery
+	SET status = 'delivered', attempts = attempts + 1, response_status = $2, last_error = NULL,
+	    next_attempt_at = NULL, delivered_at = CURRENT_TIMESTAMP
+	WHERE id = $1
+	`
+	if _, err := wr.db.Exec(query, id, responseStatus); err != nil {
+		return fmt.Errorf("error updating webhook delivery: %v", err)
+	}
+	return nil
+}
+
+// MarkDeliveryFailed registra uma tentativa sem sucesso. A próxima tentativa é agendada com espera exponencial
+// (1, 2, 4, 8... minutos) até maxAttempts, quando a entrega é dada como falha.
+func (wr *webhookRepository) MarkDeliveryFailed(id int, responseStatus *int, deliveryErr string, maxAttempts int) error {
+	query := `
+	UPDATE webhook_delivery
+	SET attempts        = attempts + 1,
+	    response_status = $2,
+	    last_error      = $3,
+	    status          = CASE WHEN attempts + 1 >= $4 THEN 'failed' ELSE 'pending' END::webhook_delivery_status,
+	    next_attempt_at = CASE
+	                          WHEN attempts + 1 >= $4 THEN NULL
+	                          ELSE CURRENT_TIMESTAMP + MAKE_INTERVAL(mins => POWER(2, attempts)::INTEGER) END
+	WHERE id = $1
+	`
+	if _, err := wr.db.Exec(query, id, responseStatus, deliveryErr, maxAttempts); err != nil {
+		return fmt.Errorf("error updating webhook delivery: %v", err)
+	}
+	return nil
+}
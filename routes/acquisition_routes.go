@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
+	"go-api/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AcquisitionRoutes registra todas as rotas de aquisição de livros.
+func AcquisitionRoutes(rg *gin.RouterGroup) {
+	acquisitionRepository := repository.NewAcquisitionRepository(initializers.DB)
+	bookRepository := repository.NewBookRepository(initializers.DB)
+	acquisitionUseCase := usecase.NewAcquisitionUseCase(acquisitionRepository, bookRepository)
+	acquisitionController := controller.NewAcquisitionController(acquisitionUseCase)
+
+	suggestionTarget := middleware.AuditTarget{Type: "purchase_suggestion", Param: "id",
+		Load: func(id int) (any, error) {
+			return acquisitionRepository.GetSuggestionById(id)
+		}}
+	orderTarget := middleware.AuditTarget{Type: "purchase_order", Param: "id", Load: func(id int) (any, error) {
+		return acquisitionRepository.GetOrderById(id)
+	}}
+
+	acquisitions := rg.Group("/acquisitions", middleware.JWTAuthMiddleware)
+	{
+		suggestions := acquisitions.Group("/suggestions")
+		{
+			suggestions.POST("/create", acquisitionController.CreateSuggestion)
+			suggestions.GET("/mine", acquisitionController.GetLoggedUserSuggestions)
+			suggestions.GET("/", middleware.PermissionRequired(user.PermAcquisitionsManage), acquisitionController.GetSuggestions)
+			suggestions.PUT("/reject/:id", middleware.PermissionRequired(user.PermAcquisitionsManage),
+				middleware.Audit("suggestion.reject", suggestionTarget), acquisitionController.RejectSuggestion)
+		}
+
+		orders := acquisitions.Group("/orders", middleware.PermissionRequired(user.PermAcquisitionsManage))
+		{
+			orders.POST("/create", middleware.Audit("order.create", orderTarget.IdFromHandler()),
+				acquisitionController.CreateOrder)
+			orders.GET("/", acquisitionController.GetOrders)
+			orders.GET("/:id", acquisitionController.GetOrderById)
+			orders.PUT("/cancel/:id", middleware.Audit("order.cancel", orderTarget), acquisitionController.CancelOrder)
+			orders.POST("/receive/:id", middleware.Audit("order.receive", orderTarget),
+				acquisitionController.ReceiveOrder)
+		}
+
+		acquisitions.GET("/budget", middleware.PermissionRequired(user.PermAcquisitionsManage), acquisitionController.GetBudget)
+	}
+}
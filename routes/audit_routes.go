@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
+	"go-api/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditRoutes registra as rotas de consulta e verificação da trilha de auditoria das ações administrativas.
+func AuditRoutes(rg *gin.RouterGroup) {
+	auditRepository := repository.NewAuditRepository(initializers.DB)
+	auditUseCase := usecase.NewAuditUseCase(auditRepository)
+	auditController := controller.NewAuditController(auditUseCase)
+
+	audit := rg.Group("/audit", middleware.JWTAuthMiddleware, middleware.PermissionRequired(user.PermAuditRead))
+	{
+		audit.GET("/", auditController.GetEntries)
+		audit.GET("/verify", auditController.VerifyChain)
+	}
+}
@@ -0,0 +1,163 @@
+package routes
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// staffRoutes são as rotas sem permissão na rota que o handler libera para funcionários agindo em nome de um leitor,
+// e auditedReads as leituras de dados pessoais. Ambas precisam de middleware.Audit como as escritas administrativas.
+var (
+	staffRoutes  = []string{"POST /reservations/create", "PUT /loans/renew/:id"}
+	auditedReads = []string{"GET /users/:id/export"}
+)
+
+// TestStaffRoutesAreAudited lê o código das rotas e lista as rotas de escrita protegidas por
+// middleware.PermissionRequired, na própria rota ou no grupo, que não passam por middleware.Audit.
+func TestStaffRoutesAreAudited(t *testing.T) {
+	files, err := filepath.Glob("*_routes.go")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no route files found: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	found := map[string]bool{}
+	for _, file := range files {
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", file, err)
+		}
+		for _, decl := range parsed.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				for _, route := range registeredRoutes(fset, fn) {
+					found[route.name] = true
+					if route.audited {
+						continue
+					}
+					mustAudit := route.privileged && route.method != "GET"
+					for _, name := range append(staffRoutes, auditedReads...) {
+						mustAudit = mustAudit || route.name == name
+					}
+					if mustAudit {
+						t.Errorf("%s: %s is a staff route without middleware.Audit", route.pos, route.name)
+					}
+				}
+			}
+		}
+	}
+
+	for _, name := range append(staffRoutes, auditedReads...) {
+		if !found[name] {
+			t.Errorf("route %s not found", name)
+		}
+	}
+}
+
+type registeredRoute struct {
+	pos        token.Position
+	method     string
+	name       string
+	privileged bool
+	audited    bool
+}
+
+// registeredRoutes retorna as rotas registradas na função, com o caminho completo a partir dos grupos. Um grupo ou
+// uma variável atribuídos a partir de middleware.PermissionRequired tornam privilegiadas as rotas que os usam.
+func registeredRoutes(fset *token.FileSet, fn *ast.FuncDecl) []registeredRoute {
+	prefixes := map[string]string{}
+	privileged := map[string]bool{}
+	var routes []registeredRoute
+
+	usesPermission := func(args []ast.Expr) bool {
+		for _, arg := range args {
+			if isMiddlewareCall(arg, "PermissionRequired") {
+				return true
+			}
+			if ident, ok := arg.(*ast.Ident); ok && privileged[ident.Name] {
+				return true
+			}
+		}
+		return false
+	}
+
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+				return true
+			}
+			name, ok := node.Lhs[0].(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if isMiddlewareCall(node.Rhs[0], "PermissionRequired") {
+				privileged[name.Name] = true
+			} else if parent, method, args, ok := methodCall(node.Rhs[0]); ok && method == "Group" && len(args) > 0 {
+				prefixes[name.Name] = prefixes[parent] + stringLiteral(args[0])
+				privileged[name.Name] = privileged[parent] || usesPermission(args[1:])
+			}
+		case *ast.CallExpr:
+			parent, method, args, ok := methodCall(node)
+			if !ok || len(args) == 0 {
+				return true
+			}
+			switch method {
+			case "GET", "POST", "PUT", "PATCH", "DELETE":
+			default:
+				return true
+			}
+			route := registeredRoute{
+				pos:        fset.Position(node.Pos()),
+				method:     method,
+				name:       method + " " + prefixes[parent] + stringLiteral(args[0]),
+				privileged: privileged[parent] || usesPermission(args[1:]),
+			}
+			for _, arg := range args[1:] {
+				route.audited = route.audited || isMiddlewareCall(arg, "Audit")
+			}
+			routes = append(routes, route)
+		}
+		return true
+	})
+	return routes
+}
+
+// methodCall decompõe uma chamada como books.POST(...) no receptor, no método e nos argumentos.
+func methodCall(expr ast.Expr) (string, string, []ast.Expr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return "", "", nil, false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", nil, false
+	}
+	receiver, ok := selector.X.(*ast.Ident)
+	if !ok {
+		return "", "", nil, false
+	}
+	return receiver.Name, selector.Sel.Name, call.Args, true
+}
+
+// isMiddlewareCall informa se a expressão é uma chamada a middleware.<name>.
+func isMiddlewareCall(expr ast.Expr, name string) bool {
+	receiver, method, _, ok := methodCall(expr)
+	return ok && receiver == "middleware" && method == name
+}
+
+func stringLiteral(expr ast.Expr) string {
+	literal, ok := expr.(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return ""
+	}
+	value, err := strconv.Unquote(literal.Value)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(value, "/")
+}
@@ -4,6 +4,7 @@ import (
 	"go-api/controller"
 	"go-api/initializers"
 	"go-api/middleware"
+	"go-api/model/user"
 	"go-api/repository"
 	"go-api/usecase"
 
@@ -17,21 +18,43 @@ func BookRoutes(rg *gin.RouterGroup) {
 	bookUseCase := usecase.NewBookUseCase(bookRepository, reservationRepository)
 	bookController := controller.NewBookController(bookUseCase)
 
-	// Cria um grupo de rotas para '/books' que requerem autorização JWT, algumas com autorização 'admin'
+	bookTarget := middleware.AuditTarget{Type: "book", Param: "id", Load: func(id int) (any, error) {
+		return bookRepository.GetBookById(id)
+	}}
+	stockTarget := middleware.AuditTarget{Type: "book_stock", Param: "stock-id", Load: func(id int) (any, error) {
+		return bookRepository.GetStockById(id)
+	}}
+
+	// Cria um grupo de rotas para '/books' que requerem autorização JWT, algumas com permissões específicas
 	books := rg.Group("/books", middleware.JWTAuthMiddleware)
 	{
-		books.POST("/create", middleware.RoleRequired("admin"), bookController.CreateBook)
+		books.POST("/create", middleware.PermissionRequired(user.PermBooksWrite),
+			middleware.Audit("book.create", bookTarget.IdFromHandler()), bookController.CreateBook)
 		books.GET("/", bookController.GetBooks)
 		books.GET("/:id", bookController.GetBookById)
-		books.PUT("/update/:id", middleware.RoleRequired("admin"), bookController.UpdateBook)
-		books.DELETE("/delete/:id", middleware.RoleRequired("admin"), bookController.DeleteBook)
+		books.PUT("/update/:id", middleware.PermissionRequired(user.PermBooksWrite),
+			middleware.Audit("book.update", bookTarget), bookController.UpdateBook)
+		books.DELETE("/delete/:id", middleware.PermissionRequired(user.PermBooksDelete),
+			middleware.Audit("book.delete", bookTarget), bookController.DeleteBook)
 
-		stock := books.Group("/:id/stock", middleware.RoleRequired("admin"))
+		stock := books.Group("/:id/stock")
 		{
-			stock.POST("/add", bookController.AddStock)
-			stock.GET("/", bookController.GetStock)
-			stock.PUT("/update-status/:stock-id", bookController.UpdateStockStatus)
-			stock.DELETE("/remove/:stock-id", bookController.RemoveStock)
+			canRead := middleware.PermissionRequired(user.PermStockRead)
+			canWrite := middleware.PermissionRequired(user.PermStockWrite)
+
+			stock.POST("/add", canWrite, middleware.Audit("stock.add", stockTarget.IdFromHandler()),
+				bookController.AddStock)
+			stock.GET("/", canRead, bookController.GetStock)
+			stock.PUT("/update-status/:stock-id", canWrite, middleware.Audit("stock.update_status", stockTarget),
+				bookController.UpdateStockStatus)
+			stock.DELETE("/remove/:stock-id", canWrite, middleware.Audit("stock.remove", stockTarget),
+				bookController.RemoveStock)
+			stock.PUT("/update-condition/:stock-id", canWrite, middleware.Audit("stock.update_condition", stockTarget),
+				bookController.UpdateStockCondition)
+			stock.PUT("/update-location/:stock-id", canWrite, middleware.Audit("stock.update_location", stockTarget),
+				bookController.UpdateStockLocation)
+			stock.GET("/:stock-id/damage-reports", canRead, bookController.GetDamageReports)
+			stock.GET("/:stock-id/history", canRead, bookController.GetStockHistory)
 		}
 	}
 }
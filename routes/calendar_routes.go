@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
+	"go-api/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarRoutes registra as rotas do calendário de funcionamento da biblioteca.
+func CalendarRoutes(rg *gin.RouterGroup) {
+	calendarRepository := repository.NewCalendarRepository(initializers.DB)
+	calendarUseCase := usecase.NewCalendarUseCase(calendarRepository)
+	calendarController := controller.NewCalendarController(calendarUseCase)
+
+	// O horário de funcionamento e os dias sem expediente são guardados inteiros nas fotografias, já que as datas não
+	// têm um id numérico
+	openingHoursTarget := middleware.AuditTarget{Type: "opening_hours", Load: func(int) (any, error) {
+		return calendarRepository.GetOpeningHours()
+	}}
+	closedDatesTarget := middleware.AuditTarget{Type: "closed_dates", Load: func(int) (any, error) {
+		return calendarRepository.GetClosedDates("", "")
+	}}
+
+	calendar := rg.Group("/calendar", middleware.JWTAuthMiddleware)
+	{
+		canManage := middleware.PermissionRequired(user.PermCalendarManage)
+
+		calendar.GET("/opening-hours", calendarController.GetOpeningHours)
+		calendar.PUT("/opening-hours/update", canManage,
+			middleware.Audit("calendar.set_opening_hours", openingHoursTarget), calendarController.SetOpeningHours)
+		calendar.GET("/closed-dates", calendarController.GetClosedDates)
+		calendar.POST("/closed-dates/create", canManage,
+			middleware.Audit("calendar.create_closed_date", closedDatesTarget), calendarController.CreateClosedDate)
+		calendar.POST("/closed-dates/import-holidays", canManage,
+			middleware.Audit("calendar.import_holidays", closedDatesTarget), calendarController.ImportNationalHolidays)
+		calendar.DELETE("/closed-dates/delete/:date", canManage,
+			middleware.Audit("calendar.delete_closed_date", closedDatesTarget), calendarController.DeleteClosedDate)
+	}
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
+	"go-api/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CirculationPolicyRoutes registra as rotas de consulta e edição das regras de circulação.
+func CirculationPolicyRoutes(rg *gin.RouterGroup) {
+	circulationPolicyRepository := repository.NewCirculationPolicyRepository(initializers.DB)
+	circulationPolicyUseCase := usecase.NewCirculationPolicyUseCase(circulationPolicyRepository)
+	circulationPolicyController := controller.NewCirculationPolicyController(circulationPolicyUseCase)
+
+	policyTarget := middleware.AuditTarget{Type: "circulation_policy", Load: func(int) (any, error) {
+		return circulationPolicyRepository.GetCirculationPolicy()
+	}}
+
+	policy := rg.Group("/circulation-policy", middleware.JWTAuthMiddleware)
+	{
+		policy.GET("/", circulationPolicyController.GetCirculationPolicy)
+		policy.PUT("/update",
+			middleware.PermissionRequired(user.PermCirculationPolicyManage),
+			middleware.Audit("circulation_policy.update", policyTarget),
+			circulationPolicyController.UpdateCirculationPolicy,
+		)
+	}
+}
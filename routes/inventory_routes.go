@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
+	"go-api/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryRoutes registra todas as rotas de inventário de estante.
+func InventoryRoutes(rg *gin.RouterGroup) {
+	inventoryRepository := repository.NewInventoryRepository(initializers.DB)
+	bookRepository := repository.NewBookRepository(initializers.DB)
+	inventoryUseCase := usecase.NewInventoryUseCase(inventoryRepository, bookRepository)
+	inventoryController := controller.NewInventoryController(inventoryUseCase)
+
+	sessionTarget := middleware.AuditTarget{Type: "inventory_session", Param: "id", Load: func(id int) (any, error) {
+		return inventoryRepository.GetSessionById(id)
+	}}
+
+	inventory := rg.Group("/inventory", middleware.JWTAuthMiddleware, middleware.PermissionRequired(user.PermInventoryManage))
+	{
+		inventory.POST("/open", middleware.Audit("inventory.open", sessionTarget.IdFromHandler()),
+			inventoryController.OpenSession)
+		inventory.GET("/", inventoryController.GetSessions)
+		inventory.GET("/:id", inventoryController.GetSessionById)
+		inventory.POST("/:id/scans", middleware.Audit("inventory.add_scans", sessionTarget),
+			inventoryController.AddScans)
+		inventory.PUT("/close/:id", middleware.Audit("inventory.close", sessionTarget), inventoryController.CloseSession)
+		inventory.GET("/:id/report", inventoryController.GetReport)
+		inventory.PUT("/apply/:id", middleware.Audit("inventory.apply", sessionTarget), inventoryController.ApplyReport)
+	}
+}
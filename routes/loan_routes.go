@@ -4,6 +4,8 @@ import (
 	"go-api/controller"
 	"go-api/initializers"
 	"go-api/middleware"
+	"go-api/model"
+	"go-api/model/user"
 	"go-api/repository"
 	"go-api/usecase"
 
@@ -16,16 +18,39 @@ func LoanRoutes(rg *gin.RouterGroup) {
 	bookStockRepository := repository.NewBookRepository(initializers.DB)
 	userRepository := repository.NewUserRepository(initializers.DB)
 	bookRepository := repository.NewBookRepository(initializers.DB)
-	reservationUseCase := usecase.NewReservationUseCase(reservationRepository, userRepository, bookRepository)
+	circulationPolicyRepository := repository.NewCirculationPolicyRepository(initializers.DB)
+	notificationUseCase := newNotificationUseCase()
+	reservationUseCase := usecase.NewReservationUseCase(reservationRepository, userRepository, bookRepository,
+		circulationPolicyRepository, notificationUseCase)
 
-	loanUseCase := usecase.NewLoanUseCase(loanRepository, reservationRepository, bookStockRepository)
+	loanUseCase := usecase.NewLoanUseCase(loanRepository, reservationRepository, bookStockRepository, userRepository,
+		circulationPolicyRepository, notificationUseCase)
 	loanController := controller.NewLoanController(loanUseCase, reservationUseCase)
 
+	loanTarget := middleware.AuditTarget{Type: "loan", Param: "id", Load: func(id int) (any, error) {
+		loan, err := loanRepository.GetLoanById(id)
+		if err != nil {
+			return nil, err
+		}
+		return model.NewLoanSnapshot(loan), nil
+	}}
+
 	loan := rg.Group("/loans", middleware.JWTAuthMiddleware)
 	{
-		loan.GET("/", middleware.RoleRequired("admin"), loanController.GetLoansByFilters)
-		loan.GET("/:id", middleware.RoleRequired("admin"), loanController.GetLoanById)
-		loan.POST("/create", middleware.RoleRequired("admin"), loanController.CreateLoan)
-		loan.PUT("/finish-loan/:id", middleware.RoleRequired("admin"), loanController.FinishLoan)
+		loan.GET("/", middleware.PermissionRequired(user.PermLoansRead), loanController.GetLoansByFilters)
+		loan.GET("/:id", middleware.PermissionRequired(user.PermLoansRead), loanController.GetLoanById)
+		loan.POST("/create", middleware.PermissionRequired(user.PermLoansCheckout),
+			middleware.Audit("loan.create", loanTarget.IdFromHandler()), loanController.CreateLoan)
+		loan.POST("/walk-in", middleware.PermissionRequired(user.PermLoansCheckout),
+			middleware.Audit("loan.walk_in", loanTarget.IdFromHandler()), loanController.CreateWalkInLoan)
+		loan.PUT("/finish-loan/:id", middleware.PermissionRequired(user.PermLoansReturn),
+			middleware.Audit("loan.finish", loanTarget), loanController.FinishLoan)
+		loan.PUT("/renew/:id", middleware.Audit("loan.renew", loanTarget), loanController.RenewLoan)
+	}
+
+	circulation := rg.Group("/circulation", middleware.JWTAuthMiddleware)
+	{
+		circulation.POST("/checkin", middleware.PermissionRequired(user.PermLoansReturn),
+			middleware.Audit("loan.checkin", loanTarget.IdFromHandler()), loanController.CheckIn)
 	}
 }
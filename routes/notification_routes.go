@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/model/user"
+	"go-api/notification"
+	"go-api/repository"
+	"go-api/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inboxHub repassa as novas mensagens da caixa de entrada aos streams abertos. É único para que mensagens criadas por
+// qualquer rota cheguem às conexões.
+var inboxHub = notification.NewHub()
+
+// newNotificationUseCase cria o caso de uso de notificações com todos os canais de entrega.
+func newNotificationUseCase() usecase.NotificationUseCase {
+	notificationRepository := repository.NewNotificationRepository(initializers.DB)
+	userRepository := repository.NewUserRepository(initializers.DB)
+	channels := map[model.NotificationChannel]notification.Channel{
+		model.ChannelEmail:   notification.NewEmailChannel(initializers.Mailer),
+		model.ChannelWebhook: notification.NewWebhookChannel(10 * time.Second),
+		model.ChannelInbox:   notification.NewInboxChannel(notificationRepository, inboxHub),
+	}
+	return usecase.NewNotificationUseCase(notificationRepository, userRepository, channels, inboxHub,
+		initializers.LoanReminderDays)
+}
+
+// NotificationRoutes registra as rotas da caixa de entrada, das preferências e do registro de entregas de notificações.
+func NotificationRoutes(rg *gin.RouterGroup) {
+	notificationController := controller.NewNotificationController(newNotificationUseCase())
+
+	inbox := rg.Group("/user/notifications", middleware.JWTAuthMiddleware)
+	{
+		inbox.GET("/", notificationController.GetInbox)
+		inbox.GET("/unread-count", notificationController.CountUnread)
+		inbox.GET("/stream", notificationController.StreamInbox)
+		inbox.PUT("/read/:id", notificationController.MarkRead)
+		inbox.PUT("/read-all", notificationController.MarkAllRead)
+	}
+
+	preferences := rg.Group("/user/notification-preferences", middleware.JWTAuthMiddleware)
+	{
+		preferences.GET("/", notificationController.GetPreferences)
+		preferences.PUT("/", notificationController.UpdatePreferences)
+	}
+
+	notifications := rg.Group("/notifications", middleware.JWTAuthMiddleware)
+	{
+		notifications.GET("/deliveries", middleware.PermissionRequired(user.PermNotificationsRead),
+			notificationController.GetDeliveries)
+	}
+}
+
+// StartNotificationScheduler inicia em segundo plano o envio de lembretes e o reenvio das entregas que falharam.
+func StartNotificationScheduler() *notification.Scheduler {
+	notificationUseCase := newNotificationUseCase()
+	scheduler := notification.NewScheduler(5*time.Minute,
+		notification.Job{Name: "reminders", Run: notificationUseCase.SendReminders},
+		notification.Job{Name: "retries", Run: notificationUseCase.RetryDeliveries},
+	)
+	scheduler.Start()
+	return scheduler
+}
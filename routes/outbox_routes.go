@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"go-api/initializers"
+	"go-api/outbox"
+	"go-api/repository"
+	"time"
+)
+
+// eventBus recebe os eventos do outbox no assunto "library.<evento>". Por padrão é o barramento em memória; uma
+// conexão NATS pode ser usada no lugar, já que implementa outbox.Publisher.
+var eventBus outbox.Publisher = outbox.NewBus()
+
+// StartOutboxDispatcher inicia em segundo plano a publicação dos eventos do outbox nos webhooks, no barramento de
+// eventos e, com OUTBOX_LOG_EVENTS, no log.
+func StartOutboxDispatcher() *outbox.Dispatcher {
+	sinks := map[string]outbox.Sink{
+		"webhook": newWebhookUseCase(),
+		"bus":     outbox.NewBusSink(eventBus, "library"),
+	}
+	if initializers.OutboxLogEvents {
+		sinks["log"] = outbox.NewLogSink()
+	}
+
+	dispatcher := outbox.NewDispatcher(repository.NewOutboxRepository(initializers.DB), sinks, time.Second)
+	dispatcher.Start()
+	return dispatcher
+}
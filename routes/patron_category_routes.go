@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
+	"go-api/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PatronCategoryRoutes registra as rotas de categorias de leitor e suas políticas de empréstimo.
+func PatronCategoryRoutes(rg *gin.RouterGroup) {
+	patronCategoryRepository := repository.NewPatronCategoryRepository(initializers.DB)
+	patronCategoryUseCase := usecase.NewPatronCategoryUseCase(patronCategoryRepository)
+	patronCategoryController := controller.NewPatronCategoryController(patronCategoryUseCase)
+
+	categoryTarget := middleware.AuditTarget{Type: "patron_category", Param: "id", Load: func(id int) (any, error) {
+		return patronCategoryRepository.GetPatronCategoryById(id)
+	}}
+
+	categories := rg.Group("/patron-categories", middleware.JWTAuthMiddleware)
+	{
+		canManage := middleware.PermissionRequired(user.PermPatronCategoriesManage)
+
+		categories.GET("/", patronCategoryController.GetPatronCategories)
+		categories.POST("/create", canManage, middleware.Audit("patron_category.create", categoryTarget.IdFromHandler()),
+			patronCategoryController.CreatePatronCategory)
+		categories.PUT("/update/:id", canManage, middleware.Audit("patron_category.update", categoryTarget),
+			patronCategoryController.UpdatePatronCategory)
+		categories.DELETE("/delete/:id", canManage, middleware.Audit("patron_category.delete", categoryTarget),
+			patronCategoryController.DeletePatronCategory)
+	}
+}
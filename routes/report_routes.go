@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
+	"go-api/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportRoutes registra as rotas de relatórios de análise do acervo.
+func ReportRoutes(rg *gin.RouterGroup) {
+	reportRepository := repository.NewReportRepository(initializers.DB)
+	reportUseCase := usecase.NewReportUseCase(reportRepository)
+	reportController := controller.NewReportController(reportUseCase)
+
+	reports := rg.Group("/reports", middleware.JWTAuthMiddleware, middleware.PermissionRequired(user.PermReportsRead))
+	{
+		reports.GET("/unused-copies", reportController.GetUnusedCopies)
+		reports.GET("/unused-titles", reportController.GetUnusedTitles)
+		reports.GET("/title-circulation", reportController.GetTitleCirculation)
+		reports.GET("/genre-circulation", reportController.GetGenreCirculation)
+		reports.GET("/damaged-copies", reportController.GetDamagedCopies)
+		reports.GET("/waitlist-pressure", reportController.GetWaitlistPressure)
+		reports.GET("/expiring-memberships", reportController.GetExpiringMemberships)
+	}
+}
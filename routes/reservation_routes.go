@@ -4,6 +4,8 @@ import (
 	"go-api/controller"
 	"go-api/initializers"
 	"go-api/middleware"
+	"go-api/model"
+	"go-api/model/user"
 	"go-api/repository"
 	"go-api/usecase"
 
@@ -16,12 +18,22 @@ func ReservationRoutes(rg *gin.RouterGroup) {
 	userRepository := repository.NewUserRepository(initializers.DB)
 	bookRepository := repository.NewBookRepository(initializers.DB)
 	reservationRepository := repository.NewReservationRepository(initializers.DB)
-	reservationUseCase := usecase.NewReservationUseCase(reservationRepository,userRepository,bookRepository)
+	circulationPolicyRepository := repository.NewCirculationPolicyRepository(initializers.DB)
+	reservationUseCase := usecase.NewReservationUseCase(reservationRepository,userRepository,bookRepository,circulationPolicyRepository,newNotificationUseCase())
 	reservationController := controller.NewReservationController(reservationUseCase)
 
+	reservationTarget := middleware.AuditTarget{Type: "reservation", Load: func(id int) (any, error) {
+		reservation, err := reservationRepository.GetReservationById(id)
+		if err != nil {
+			return nil, err
+		}
+		return model.NewReservationSnapshot(reservation), nil
+	}}
+
 	reservation := rg.Group("/reservations", middleware.JWTAuthMiddleware)
 	{
-		reservation.GET("/",middleware.RoleRequired("admin"), reservationController.GetReservationsByFilters)
-		reservation.POST("/create",reservationController.CreateReservation)
+		reservation.GET("/",middleware.PermissionRequired(user.PermReservationsRead), reservationController.GetReservationsByFilters)
+		reservation.POST("/create", middleware.Audit("reservation.create", reservationTarget.IdFromHandler()),
+			reservationController.CreateReservation)
 	}
 }
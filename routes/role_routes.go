@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/repository"
+	"go-api/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleRoutes registra as rotas de gerenciamento de roles e permissões.
+func RoleRoutes(rg *gin.RouterGroup) {
+	roleRepository := repository.NewRoleRepository(initializers.DB)
+	roleUseCase := usecase.NewRoleUseCase(roleRepository)
+	roleController := controller.NewRoleController(roleUseCase)
+
+	roleTarget := middleware.AuditTarget{Type: "role", Param: "id", Load: func(id int) (any, error) {
+		return roleRepository.GetRoleById(id)
+	}}
+
+	roles := rg.Group("/roles", middleware.JWTAuthMiddleware, middleware.PermissionRequired(user.PermRolesManage))
+	{
+		roles.GET("/", roleController.GetRoles)
+		roles.GET("/permissions", roleController.GetPermissions)
+		roles.POST("/create", middleware.Audit("role.create", roleTarget.IdFromHandler()), roleController.CreateRole)
+		roles.PUT("/update/:id", middleware.Audit("role.update", roleTarget), roleController.UpdateRole)
+		roles.PUT("/:id/permissions", middleware.Audit("role.set_permissions", roleTarget),
+			roleController.SetRolePermissions)
+		roles.DELETE("/delete/:id", middleware.Audit("role.delete", roleTarget), roleController.DeleteRole)
+	}
+}
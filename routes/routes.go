@@ -16,4 +16,14 @@ func Routes(r *gin.Engine) {
 	BookRoutes(api)
 	ReservationRoutes(api)
 	LoanRoutes(api)
+	InventoryRoutes(api)
+	AcquisitionRoutes(api)
+	ReportRoutes(api)
+	RoleRoutes(api)
+	PatronCategoryRoutes(api)
+	CirculationPolicyRoutes(api)
+	CalendarRoutes(api)
+	NotificationRoutes(api)
+	WebhookRoutes(api)
+	AuditRoutes(api)
 }
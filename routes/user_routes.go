@@ -4,6 +4,8 @@ import (
 	"go-api/controller"
 	"go-api/initializers"
 	"go-api/middleware"
+	"go-api/model"
+	"go-api/model/user"
 	"go-api/repository"
 	"go-api/usecase"
 
@@ -13,42 +15,117 @@ import (
 // UserRoutes registra todas as rotas de usuário.
 func UserRoutes(rg *gin.RouterGroup) {
 	userRepository := repository.NewUserRepository(initializers.DB)
-	userUseCase := usecase.NewUserUseCase(userRepository)
+	sessionRepository := repository.NewSessionRepository(initializers.DB)
+	loginLockoutRepository := repository.NewLoginLockoutRepository(initializers.DB)
+	twoFactorRepository := repository.NewTwoFactorRepository(initializers.DB)
+	acquisitionRepository := repository.NewAcquisitionRepository(initializers.DB)
+	reservationRepository := repository.NewReservationRepository(initializers.DB)
+	userUseCase := usecase.NewUserUseCase(userRepository, sessionRepository, loginLockoutRepository,
+		twoFactorRepository, acquisitionRepository, initializers.Mailer, newNotificationUseCase())
 	userController := controller.NewUserController(userUseCase)
 
+	userTarget := middleware.AuditTarget{Type: "user", Param: "id", Load: func(id int) (any, error) {
+		account, err := userRepository.GetUserById(id)
+		if err != nil {
+			return nil, err
+		}
+		return user.NewAccountSnapshot(account), nil
+	}}
+	reservationTarget := middleware.AuditTarget{Type: "reservation", Param: "reservation-id",
+		Load: func(id int) (any, error) {
+			reservation, err := reservationRepository.GetReservationById(id)
+			if err != nil {
+				return nil, err
+			}
+			return model.NewReservationSnapshot(reservation), nil
+		}}
+	lockoutTarget := middleware.AuditTarget{Type: "login_lockout", Param: "id", Load: func(id int) (any, error) {
+		lockout, err := loginLockoutRepository.GetLockoutById(id)
+		if err != nil {
+			return nil, err
+		}
+		return user.NewLoginLockoutSnapshot(lockout), nil
+	}}
+
 	rg.POST("/login", userController.Login)
+	rg.POST("/login/2fa", userController.VerifyLoginTwoFactor)
+	rg.POST("/login/2fa/enroll", userController.EnrollLoginTwoFactor)
+	rg.POST("/refresh", userController.RefreshSession)
+	rg.POST("/logout", middleware.JWTAuthMiddleware, userController.Logout)
+	rg.POST("/logout-all", middleware.JWTAuthMiddleware, userController.LogoutAll)
+	rg.POST("/signup", userController.SignUp)
+	rg.GET("/verify-email", userController.VerifyEmail)
+	rg.POST("/resend-verification", userController.ResendVerification)
+	rg.POST("/forgot-password", userController.ForgotPassword)
+	rg.POST("/reset-password", userController.ResetPassword)
 	rg.POST("/register",
 		middleware.JWTAuthMiddleware,
-		middleware.RoleRequired("admin"),
+		middleware.PermissionRequired(user.PermUsersWrite),
+		middleware.Audit("user.register", userTarget.IdFromHandler()),
 		userController.Register,
 	)
 
-	users := rg.Group("/users", middleware.JWTAuthMiddleware, middleware.RoleRequired("admin"))
+	users := rg.Group("/users", middleware.JWTAuthMiddleware)
 	{
-		users.POST("/register", userController.Register)
-		users.GET("/", userController.GetUsersByFilters)
-		users.GET("/:id", userController.GetUserById)
-		users.PUT("/activate/:id", userController.ToggleUser("activate"))
-		users.PUT("/deactivate/:id", userController.ToggleUser("deactivate"))
-		users.DELETE("/delete/:id", userController.DeleteUser)
+		canRead := middleware.PermissionRequired(user.PermUsersRead)
+		canWrite := middleware.PermissionRequired(user.PermUsersWrite)
+
+		users.POST("/register", canWrite, middleware.Audit("user.register", userTarget.IdFromHandler()),
+			userController.Register)
+		users.GET("/", canRead, userController.GetUsersByFilters)
+		users.GET("/:id", canRead, userController.GetUserById)
+		users.PUT("/activate/:id", canWrite, middleware.Audit("user.activate", userTarget),
+			userController.ToggleUser("activate"))
+		users.PUT("/deactivate/:id", canWrite, middleware.Audit("user.deactivate", userTarget),
+			userController.ToggleUser("deactivate"))
+		users.DELETE("/delete/:id", middleware.PermissionRequired(user.PermUsersDelete),
+			middleware.Audit("user.delete", userTarget), userController.DeleteUser)
+		users.GET("/:id/export", middleware.PermissionRequired(user.PermUsersPrivacy),
+			middleware.Audit("user.export", userTarget), userController.ExportUserData)
+		users.PUT("/anonymize/:id", middleware.PermissionRequired(user.PermUsersPrivacy),
+			middleware.Audit("user.anonymize", userTarget), userController.AnonymizeUser)
+		users.PUT("/reset-2fa/:id", canWrite, middleware.Audit("user.reset_2fa", userTarget),
+			userController.ResetTwoFactor)
+		users.PUT("/renew-membership/:id", canWrite, middleware.Audit("user.renew_membership", userTarget),
+			userController.RenewMembership)
+		users.PUT("/patron-category/:id", canWrite, middleware.Audit("user.set_patron_category", userTarget),
+			userController.SetPatronCategory)
+		users.GET("/lockouts", canRead, userController.GetLoginLockouts)
+		users.DELETE("/lockouts/delete/:id", canWrite, middleware.Audit("login_lockout.delete", lockoutTarget),
+			userController.ClearLoginLockout)
 
 		reservations := users.Group("/:id/reservations")
 		{
-			reservations.GET("/", userController.GetUserReservations)
-			reservations.PUT("/cancel/:reservation-id", userController.CancelUserReservation)
+			reservations.GET("/", canRead, userController.GetUserReservations)
+			reservations.PUT("/cancel/:reservation-id", canWrite,
+				middleware.Audit("reservation.cancel", reservationTarget), userController.CancelUserReservation)
 		}
 	}
 
-	user := rg.Group("/user")
+	loggedUser := rg.Group("/user")
 	{
-		user.POST("/login", userController.Login)
+		loggedUser.POST("/login", userController.Login)
+		loggedUser.POST("/login/2fa", userController.VerifyLoginTwoFactor)
+		loggedUser.POST("/login/2fa/enroll", userController.EnrollLoginTwoFactor)
 
-		reservations := user.Group("/reservations", middleware.JWTAuthMiddleware)
+		reservations := loggedUser.Group("/reservations", middleware.JWTAuthMiddleware)
 		{
 			reservations.GET("/", userController.GetLoggedUserReservations)
 			reservations.PUT("/cancel/:id", userController.CancelLoggedUserReservation)
 		}
 
-		user.GET("/loans", middleware.JWTAuthMiddleware, userController.GetUserLoans)
+		loggedUser.GET("/me", middleware.JWTAuthMiddleware, userController.GetProfile)
+		loggedUser.PUT("/me", middleware.JWTAuthMiddleware, userController.UpdateProfile)
+		loggedUser.GET("/me/export", middleware.JWTAuthMiddleware, userController.ExportLoggedUserData)
+		loggedUser.GET("/loans", middleware.JWTAuthMiddleware, userController.GetUserLoans)
+		loggedUser.GET("/fines", middleware.JWTAuthMiddleware, userController.GetUserFines)
+		loggedUser.PUT("/change-password", middleware.JWTAuthMiddleware, userController.ChangePassword)
+
+		twoFactor := loggedUser.Group("/2fa", middleware.JWTAuthMiddleware)
+		{
+			twoFactor.POST("/enroll", userController.EnrollTwoFactor)
+			twoFactor.POST("/confirm", userController.ConfirmTwoFactor)
+			twoFactor.POST("/disable", userController.DisableTwoFactor)
+		}
 	}
 }
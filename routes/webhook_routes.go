@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"go-api/controller"
+	"go-api/initializers"
+	"go-api/middleware"
+	"go-api/model/user"
+	"go-api/notification"
+	"go-api/repository"
+	"go-api/usecase"
+	"go-api/webhook"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newWebhookUseCase cria o caso de uso que publica os eventos da circulação nos webhooks cadastrados.
+func newWebhookUseCase() usecase.WebhookUseCase {
+	webhookRepository := repository.NewWebhookRepository(initializers.DB)
+	return usecase.NewWebhookUseCase(webhookRepository, webhook.NewSender(10*time.Second))
+}
+
+// WebhookRoutes registra as rotas de cadastro de webhooks e do seu registro de entregas.
+func WebhookRoutes(rg *gin.RouterGroup) {
+	webhookRepository := repository.NewWebhookRepository(initializers.DB)
+	webhookController := controller.NewWebhookController(newWebhookUseCase())
+
+	// O segredo dos webhooks e o corpo dos eventos ficam fora das fotografias da trilha de auditoria
+	endpointTarget := middleware.AuditTarget{Type: "webhook_endpoint", Param: "id", Load: func(id int) (any, error) {
+		endpoint, err := webhookRepository.GetEndpointById(id)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Secret = ""
+		return endpoint, nil
+	}}
+	deliveryTarget := middleware.AuditTarget{Type: "webhook_delivery", Param: "id", Load: func(id int) (any, error) {
+		delivery, err := webhookRepository.GetDeliveryById(id)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Payload = nil
+		return delivery, nil
+	}}
+
+	webhooks := rg.Group("/webhooks", middleware.JWTAuthMiddleware, middleware.PermissionRequired(user.PermWebhooksManage))
+	{
+		webhooks.GET("/endpoints", webhookController.GetEndpoints)
+		webhooks.POST("/endpoints/create", middleware.Audit("webhook.create", endpointTarget.IdFromHandler()),
+			webhookController.CreateEndpoint)
+		webhooks.PUT("/endpoints/update/:id", middleware.Audit("webhook.update", endpointTarget),
+			webhookController.UpdateEndpoint)
+		webhooks.DELETE("/endpoints/delete/:id", middleware.Audit("webhook.delete", endpointTarget),
+			webhookController.DeleteEndpoint)
+		webhooks.GET("/deliveries", webhookController.GetDeliveries)
+		webhooks.POST("/deliveries/replay/:id", middleware.Audit("webhook.replay_delivery", deliveryTarget),
+			webhookController.ReplayDelivery)
+	}
+}
+
+// StartWebhookScheduler inicia em segundo plano o reenvio das entregas de webhooks que falharam.
+func StartWebhookScheduler() *notification.Scheduler {
+	webhookUseCase := newWebhookUseCase()
+	scheduler := notification.NewScheduler(time.Minute,
+		notification.Job{Name: "webhook retries", Run: webhookUseCase.RetryDeliveries},
+	)
+	scheduler.Start()
+	return scheduler
+}
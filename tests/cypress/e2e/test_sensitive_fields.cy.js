@@ -0,0 +1,163 @@
+import 'cypress-plugin-api';
+import {buscarCamposSensiveis} from '../utils/validarCamposSensiveis';
+
+const baseUrl = 'http://localhost:8080/api/v1';
+const cpfMascarado = /^\*\*\*\.\d{3}\.\d{3}-\*\*$/;
+
+// Rotas de leitura que retornam contas de usuário, diretamente ou dentro de outros objetos
+const rotas = [
+    '/users',
+    '/user/me',
+    '/user/loans',
+    '/user/reservations',
+    '/user/fines',
+    '/loans',
+    '/reservations',
+    '/users/lockouts',
+    '/inventory',
+    '/acquisitions/suggestions',
+    '/acquisitions/orders',
+    '/roles',
+];
+
+let authToken;
+let librarianToken;
+describe('Sensitive fields tests', () => {
+    before(() => {
+        cy.api({
+            method: 'POST',
+            url: `${baseUrl}/login`,
+            body: {
+                "email": "lucas@admin.com",
+                "password": "123"
+            }
+        }).then((response) => {
+            expect(response.status).to.equal(200)
+            expect(buscarCamposSensiveis(response.body)).to.be.empty;
+            authToken = response.body.access_token;
+        });
+    });
+
+    rotas.forEach((rota) => {
+        it(`does not leak credentials from ${rota}`, () => {
+            cy.api({
+                method: 'GET',
+                url: `${baseUrl}${rota}`,
+                headers: {
+                    Authorization: `Bearer ${authToken}`
+                }
+            }).then((response) => {
+                expect(response.status).to.equal(200)
+                expect(buscarCamposSensiveis(response.body)).to.be.empty;
+            });
+        });
+    });
+
+    it('create librarian', () => {
+        cy.api({
+            method: 'GET',
+            url: `${baseUrl}/roles`,
+            headers: {
+                Authorization: `Bearer ${authToken}`
+            }
+        }).then((response) => {
+            const librarian = response.body.find((role) => role.name === 'librarian');
+            expect(librarian).to.not.be.undefined;
+            expect(librarian.permissions).to.not.include('users:read_cpf');
+
+            cy.api({
+                method: 'POST',
+                url: `${baseUrl}/register`,
+                headers: {
+                    Authorization: `Bearer ${authToken}`
+                },
+                body: {
+                    "name": "bibliotecário",
+                    "cpf": "52998224725",
+                    "phone": "(48)98484-7777",
+                    "email": "bibliotecario@gmail.com",
+                    "password": "123",
+                    "role_id": librarian.id
+                }
+            }).then((response) => {
+                expect(response.status).to.equal(201)
+                Cypress.env('librarianId', response.body.user_id);
+            });
+        });
+
+        cy.api({
+            method: 'POST',
+            url: `${baseUrl}/login`,
+            body: {
+                "email": "bibliotecario@gmail.com",
+                "password": "123"
+            }
+        }).then((response) => {
+            expect(response.status).to.equal(200)
+            librarianToken = response.body.access_token;
+        });
+    });
+
+    it('masks cpf without permission', () => {
+        cy.api({
+            method: 'GET',
+            url: `${baseUrl}/users`,
+            headers: {
+                Authorization: `Bearer ${librarianToken}`
+            }
+        }).then((response) => {
+            expect(response.status).to.equal(200)
+            expect(buscarCamposSensiveis(response.body)).to.be.empty;
+            response.body.forEach((usuario) => expect(usuario.cpf).to.match(cpfMascarado));
+        });
+
+        cy.api({
+            method: 'GET',
+            url: `${baseUrl}/users/${Cypress.env('librarianId')}`,
+            headers: {
+                Authorization: `Bearer ${librarianToken}`
+            }
+        }).then((response) => {
+            expect(response.status).to.equal(200)
+            expect(response.body.cpf).to.equal('***.982.247-**');
+        });
+    });
+
+    it('shows own cpf in profile', () => {
+        cy.api({
+            method: 'GET',
+            url: `${baseUrl}/user/me`,
+            headers: {
+                Authorization: `Bearer ${librarianToken}`
+            }
+        }).then((response) => {
+            expect(response.status).to.equal(200)
+            expect(response.body.cpf).to.equal('52998224725');
+        });
+    });
+
+    it('shows full cpf with permission', () => {
+        cy.api({
+            method: 'GET',
+            url: `${baseUrl}/users/${Cypress.env('librarianId')}`,
+            headers: {
+                Authorization: `Bearer ${authToken}`
+            }
+        }).then((response) => {
+            expect(response.status).to.equal(200)
+            expect(response.body.cpf).to.equal('52998224725');
+        });
+    });
+
+    it('delete librarian', () => {
+        cy.api({
+            method: 'DELETE',
+            url: `${baseUrl}/users/delete/${Cypress.env('librarianId')}`,
+            headers: {
+                Authorization: `Bearer ${authToken}`
+            }
+        }).then((response) => {
+            expect(response.status).to.equal(200)
+        });
+    });
+});
@@ -14,7 +14,7 @@ describe('API tests', () => {
         }).then((response) => {
 
             expect(response.status).to.equal(200)
-            authToken = response.body.replace(/^"|"$/g, '');
+            authToken = response.body.access_token;
             cy.log(`Auth Token: ${authToken}`);
 
         });
@@ -0,0 +1,32 @@
+/**
+ * Campos que nunca devem aparecer em uma resposta da API.
+ */
+export const camposSensiveis = [
+    'password',
+    'password_hash',
+    'refresh_token_hash',
+    'previous_token_hash',
+    'token_hash',
+    'code_hash',
+    'secret',
+];
+
+/**
+ * Percorre o corpo de uma resposta e retorna os caminhos dos campos sensíveis encontrados.
+ *
+ * @param {*} corpo - O corpo da resposta retornada pela API.
+ * @param {String} caminho - O caminho até o valor atual, usado nas mensagens de erro.
+ * @returns {Array} - A lista de caminhos com campos sensíveis; vazia se nenhum for encontrado.
+ */
+export function buscarCamposSensiveis(corpo, caminho = '$') {
+    if (Array.isArray(corpo)) {
+        return corpo.flatMap((item, i) => buscarCamposSensiveis(item, `${caminho}[${i}]`));
+    }
+    if (corpo === null || typeof corpo !== 'object') {
+        return [];
+    }
+    return Object.entries(corpo).flatMap(([campo, valor]) => {
+        const encontrados = camposSensiveis.includes(campo) ? [`${caminho}.${campo}`] : [];
+        return encontrados.concat(buscarCamposSensiveis(valor, `${caminho}.${campo}`));
+    });
+}
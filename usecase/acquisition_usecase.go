@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model"
+	"go-api/repository"
+)
+
+type AcquisitionUseCase interface {
+	CreateSuggestion(title, authorName, notes string, userId int) (*model.PurchaseSuggestion, error)
+	GetSuggestions(status model.PurchaseSuggestionStatus, userId *int) (*[]model.PurchaseSuggestion, error)
+	RejectSuggestion(id int) error
+	CreateOrder(bookId int, vendor string, quantity int, unitCost float64, suggestionId *int, adminId int) (*model.PurchaseOrder, error)
+	GetOrders(status model.PurchaseOrderStatus) (*[]model.PurchaseOrder, error)
+	GetOrderById(id int) (*model.PurchaseOrder, error)
+	CancelOrder(id int) error
+	ReceiveOrder(id int, codes []int, branch, section string, adminId int) (*[]model.BookStock, error)
+	GetBudget(period, from, to string) (*[]model.AcquisitionBudget, error)
+}
+
+type acquisitionUseCase struct {
+	acquisitionRepo repository.AcquisitionRepository
+	bookRepo        repository.BookRepository
+}
+
+func NewAcquisitionUseCase(acquisitionRepo repository.AcquisitionRepository, bookRepo repository.BookRepository) AcquisitionUseCase {
+	return &acquisitionUseCase{acquisitionRepo: acquisitionRepo, bookRepo: bookRepo}
+}
+
+func (au *acquisitionUseCase) CreateSuggestion(title, authorName, notes string, userId int) (*model.PurchaseSuggestion, error) {
+	return au.acquisitionRepo.CreateSuggestion(title, authorName, notes, userId)
+}
+
+func (au *acquisitionUseCase) GetSuggestions(status model.PurchaseSuggestionStatus, userId *int) (*[]model.PurchaseSuggestion, error) {
+	return au.acquisitionRepo.GetSuggestions(status, userId)
+}
+
+func (au *acquisitionUseCase) RejectSuggestion(id int) error {
+	suggestion, err := au.acquisitionRepo.GetSuggestionById(id)
+	if err != nil {
+		return err
+	}
+
+	if suggestion.Status != model.PurchaseSuggestionPending {
+		return fmt.Errorf("cannot reject purchase suggestion unless its status is 'pending'. Current status: '%s'", suggestion.Status)
+	}
+
+	return au.acquisitionRepo.UpdateSuggestionStatus(id, model.PurchaseSuggestionRejected, nil)
+}
+
+// CreateOrder cria um pedido de compra para um livro já cadastrado. Se o pedido atender a uma sugestão pendente,
+// a sugestão é marcada como pedida e associada ao livro.
+func (au *acquisitionUseCase) CreateOrder(bookId int, vendor string, quantity int, unitCost float64, suggestionId *int, adminId int) (*model.PurchaseOrder, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be greater than zero")
+	}
+
+	if unitCost < 0 {
+		return nil, fmt.Errorf("unit cost cannot be negative")
+	}
+
+	if _, err := au.bookRepo.GetBookById(bookId); err != nil {
+		return nil, err
+	}
+
+	if suggestionId != nil {
+		suggestion, err := au.acquisitionRepo.GetSuggestionById(*suggestionId)
+		if err != nil {
+			return nil, err
+		}
+		if suggestion.Status != model.PurchaseSuggestionPending {
+			return nil, fmt.Errorf("purchase suggestion is not pending")
+		}
+	}
+
+	order, err := au.acquisitionRepo.CreateOrder(bookId, vendor, quantity, unitCost, suggestionId, adminId)
+	if err != nil {
+		return nil, err
+	}
+
+	if suggestionId != nil {
+		err = au.acquisitionRepo.UpdateSuggestionStatus(*suggestionId, model.PurchaseSuggestionOrdered, &bookId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+func (au *acquisitionUseCase) GetOrders(status model.PurchaseOrderStatus) (*[]model.PurchaseOrder, error) {
+	return au.acquisitionRepo.GetOrders(status)
+}
+
+func (au *acquisitionUseCase) GetOrderById(id int) (*model.PurchaseOrder, error) {
+	return au.acquisitionRepo.GetOrderById(id)
+}
+
+func (au *acquisitionUseCase) CancelOrder(id int) error {
+	return au.acquisitionRepo.CancelOrder(id)
+}
+
+// ReceiveOrder registra a chegada de exemplares de um pedido, criando um estoque para cada código informado.
+func (au *acquisitionUseCase) ReceiveOrder(id int, codes []int, branch, section string, adminId int) (*[]model.BookStock, error) {
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("at least one copy code must be received")
+	}
+
+	seen := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		if seen[code] {
+			return nil, fmt.Errorf("copy code '%d' is duplicated", code)
+		}
+		seen[code] = true
+	}
+
+	return au.acquisitionRepo.ReceiveOrder(id, codes, branch, section, adminId)
+}
+
+func (au *acquisitionUseCase) GetBudget(period, from, to string) (*[]model.AcquisitionBudget, error) {
+	if period != "" && period != "month" && period != "year" {
+		return nil, fmt.Errorf("period must be 'month' or 'year'")
+	}
+	return au.acquisitionRepo.GetBudget(period, from, to)
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model"
+	"go-api/repository"
+	"time"
+)
+
+type AuditUseCase interface {
+	GetEntries(filter model.AuditFilter) (*[]model.AuditEntry, error)
+	VerifyChain() (*model.AuditVerification, error)
+}
+
+type auditUseCase struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditUseCase(auditRepo repository.AuditRepository) AuditUseCase {
+	return &auditUseCase{auditRepo: auditRepo}
+}
+
+func (au *auditUseCase) GetEntries(filter model.AuditFilter) (*[]model.AuditEntry, error) {
+	for _, date := range []string{filter.From, filter.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	return au.auditRepo.GetEntries(filter)
+}
+
+// VerifyChain percorre a trilha de auditoria na ordem de gravação, recalculando o hash de cada registro e conferindo
+// se ele aponta para o hash do anterior. Retorna o primeiro registro em que a cadeia foi quebrada.
+func (au *auditUseCase) VerifyChain() (*model.AuditVerification, error) {
+	entries, err := au.auditRepo.GetChain()
+	if err != nil {
+		return nil, err
+	}
+
+	verification := model.AuditVerification{Valid: true}
+	prevHash := model.AuditGenesisHash
+	for _, entry := range *entries {
+		if entry.PrevHash != prevHash || entry.Hash != entry.ComputeHash() {
+			id := entry.Id
+			verification.Valid = false
+			verification.BrokenAtId = &id
+			break
+		}
+		prevHash = entry.Hash
+		verification.Checked++
+	}
+	return &verification, nil
+}
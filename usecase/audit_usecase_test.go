@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"encoding/json"
+	"go-api/model"
+	"testing"
+	"time"
+)
+
+// chainAuditRepository é um AuditRepository em memória que devolve uma cadeia fixa.
+type chainAuditRepository struct {
+	entries []model.AuditEntry
+}
+
+func (r *chainAuditRepository) CreateEntry(entry model.AuditEntry) (*model.AuditEntry, error) {
+	return &entry, nil
+}
+
+func (r *chainAuditRepository) GetEntries(filter model.AuditFilter) (*[]model.AuditEntry, error) {
+	return &r.entries, nil
+}
+
+func (r *chainAuditRepository) GetChain() (*[]model.AuditEntry, error) {
+	return &r.entries, nil
+}
+
+// auditChain monta uma cadeia válida de n registros.
+func auditChain(n int) []model.AuditEntry {
+	entries := make([]model.AuditEntry, n)
+	prevHash := model.AuditGenesisHash
+	createdAt := time.Date(2026, 10, 19, 14, 0, 0, 0, time.UTC)
+	for i := range entries {
+		entries[i] = model.AuditEntry{
+			Id:         int64(i + 1),
+			ActorId:    1,
+			Action:     "book.update",
+			TargetType: "book",
+			TargetId:   "10",
+			After:      json.RawMessage(`{"title":"Dom Casmurro"}`),
+			Ip:         "10.0.0.1",
+			RequestId:  "req",
+			CreatedAt:  createdAt.Add(time.Duration(i) * time.Minute),
+			PrevHash:   prevHash,
+		}
+		entries[i].Hash = entries[i].ComputeHash()
+		prevHash = entries[i].Hash
+	}
+	return entries
+}
+
+func TestVerifyChain(t *testing.T) {
+	tests := []struct {
+		name        string
+		tamper      func(entries []model.AuditEntry) []model.AuditEntry
+		wantValid   bool
+		wantChecked int
+		wantBrokeAt int64
+	}{
+		{
+			name:        "empty chain",
+			tamper:      func(entries []model.AuditEntry) []model.AuditEntry { return entries[:0] },
+			wantValid:   true,
+			wantChecked: 0,
+		},
+		{
+			name:        "intact chain",
+			tamper:      func(entries []model.AuditEntry) []model.AuditEntry { return entries },
+			wantValid:   true,
+			wantChecked: 4,
+		},
+		{
+			name: "edited entry",
+			tamper: func(entries []model.AuditEntry) []model.AuditEntry {
+				entries[2].After = json.RawMessage(`{"title":"Memórias Póstumas"}`)
+				return entries
+			},
+			wantChecked: 2,
+			wantBrokeAt: 3,
+		},
+		{
+			name: "edited entry with recomputed hash",
+			tamper: func(entries []model.AuditEntry) []model.AuditEntry {
+				entries[1].ActorId = 2
+				entries[1].Hash = entries[1].ComputeHash()
+				return entries
+			},
+			wantChecked: 2,
+			wantBrokeAt: 3,
+		},
+		{
+			name: "removed entry",
+			tamper: func(entries []model.AuditEntry) []model.AuditEntry {
+				return append(entries[:1], entries[2:]...)
+			},
+			wantChecked: 1,
+			wantBrokeAt: 3,
+		},
+		{
+			name: "reordered entries",
+			tamper: func(entries []model.AuditEntry) []model.AuditEntry {
+				entries[0], entries[1] = entries[1], entries[0]
+				return entries
+			},
+			wantChecked: 0,
+			wantBrokeAt: 2,
+		},
+		{
+			name: "first entry not linked to genesis",
+			tamper: func(entries []model.AuditEntry) []model.AuditEntry {
+				entries[0].PrevHash = entries[3].Hash
+				entries[0].Hash = entries[0].ComputeHash()
+				return entries
+			},
+			wantChecked: 0,
+			wantBrokeAt: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &chainAuditRepository{entries: tt.tamper(auditChain(4))}
+			verification, err := NewAuditUseCase(repo).VerifyChain()
+			if err != nil {
+				t.Fatalf("VerifyChain() error = %v", err)
+			}
+
+			if verification.Valid != tt.wantValid || verification.Checked != tt.wantChecked {
+				t.Errorf("VerifyChain() = {Valid: %v, Checked: %d}, want {Valid: %v, Checked: %d}",
+					verification.Valid, verification.Checked, tt.wantValid, tt.wantChecked)
+			}
+			switch {
+			case tt.wantValid && verification.BrokenAtId != nil:
+				t.Errorf("BrokenAtId = %d, want nil", *verification.BrokenAtId)
+			case !tt.wantValid && (verification.BrokenAtId == nil || *verification.BrokenAtId != tt.wantBrokeAt):
+				t.Errorf("BrokenAtId = %v, want %d", verification.BrokenAtId, tt.wantBrokeAt)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"fmt"
 	"go-api/model"
 	"go-api/repository"
 )
@@ -11,11 +12,15 @@ type BookUseCase interface {
 	GetBookById(id int) (*model.Book, error)
 	UpdateBook(id int, title, synopsis string, authorId int) error
 	DeleteBook(id int) error
-	AddStock(code, bookId int) (*model.BookStock, error)
+	AddStock(code, bookId int, branch, section string, actorId *int) (*model.BookStock, error)
 	GetStock(code *int, bookId int) (*[]model.BookStock, error)
-	UpdateStockStatus(id int, status model.BookStockStatus, bookId *int) error
+	UpdateStockStatus(id int, status model.BookStockStatus, bookId *int, actorId int, reason string) error
 	RemoveStock(id int, bookId *int) error
 	CountAvailableBookStockById(bookId int) (int, error)
+	UpdateStockCondition(id int, condition model.BookStockCondition) error
+	UpdateStockLocation(id int, branch, section string) error
+	GetDamageReports(bookStockId int) (*[]model.DamageReport, error)
+	GetStockHistory(bookStockId int) (*[]model.BookStockHistory, error)
 }
 
 type bookUseCase struct {
@@ -71,22 +76,41 @@ func (uc *bookUseCase) DeleteBook(id int) error {
 	return uc.repository.DeleteBook(id)
 }
 
-func (uc *bookUseCase) AddStock(code, bookId int) (*model.BookStock, error) {
-	return uc.repository.AddStock(code, bookId)
+func (uc *bookUseCase) AddStock(code, bookId int, branch, section string, actorId *int) (*model.BookStock, error) {
+	return uc.repository.AddStock(code, bookId, branch, section, actorId)
 }
 
 func (uc *bookUseCase) GetStock(code *int, bookId int) (*[]model.BookStock, error) {
 	return uc.repository.GetStock(code, bookId)
 }
 
-func (uc *bookUseCase) UpdateStockStatus(id int, status model.BookStockStatus, bookId *int) error {
-	return uc.repository.UpdateStockStatus(id, string(status))
+func (uc *bookUseCase) UpdateStockStatus(id int, status model.BookStockStatus, bookId *int, actorId int, reason string) error {
+	return uc.repository.UpdateStockStatus(id, string(status), &actorId, reason, nil)
 }
 
 func (uc *bookUseCase) RemoveStock(id int, bookId *int) error {
 	return uc.repository.RemoveStock(id, bookId)
 }
 
+func (uc *bookUseCase) UpdateStockCondition(id int, condition model.BookStockCondition) error {
+	if !condition.IsValid() {
+		return fmt.Errorf("invalid book stock condition '%s'", condition)
+	}
+	return uc.repository.UpdateStockCondition(id, string(condition))
+}
+
+func (uc *bookUseCase) UpdateStockLocation(id int, branch, section string) error {
+	return uc.repository.UpdateStockLocation(id, branch, section)
+}
+
+func (uc *bookUseCase) GetDamageReports(bookStockId int) (*[]model.DamageReport, error) {
+	return uc.repository.GetDamageReports(bookStockId)
+}
+
+func (uc *bookUseCase) GetStockHistory(bookStockId int) (*[]model.BookStockHistory, error) {
+	return uc.repository.GetStockHistory(bookStockId)
+}
+
 func (uc *bookUseCase) CountAvailableBookStockById(bookId int) (int, error) {
 	availableBookStockCount, err := uc.CountBookStock(bookId, model.BookStockAvailable)
 	if err != nil {
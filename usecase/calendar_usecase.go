@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/utils"
+	"strings"
+	"time"
+)
+
+type CalendarUseCase interface {
+	GetOpeningHours() (*[]model.OpeningHours, error)
+	SetOpeningHours(hours []model.OpeningHours) error
+	GetClosedDates(from, to string) (*[]model.ClosedDate, error)
+	CreateClosedDate(date, reason string) error
+	DeleteClosedDate(date string) error
+	ImportNationalHolidays(year int) (int, error)
+}
+
+type calendarUseCase struct {
+	calendarRepo repository.CalendarRepository
+}
+
+func NewCalendarUseCase(calendarRepo repository.CalendarRepository) CalendarUseCase {
+	return &calendarUseCase{calendarRepo: calendarRepo}
+}
+
+func (cu *calendarUseCase) GetOpeningHours() (*[]model.OpeningHours, error) {
+	return cu.calendarRepo.GetOpeningHours()
+}
+
+// SetOpeningHours substitui o horário semanal da biblioteca. Cada dia da semana pode aparecer uma vez e os dias
+// ausentes ficam fechados.
+func (cu *calendarUseCase) SetOpeningHours(hours []model.OpeningHours) error {
+	seen := make(map[int]bool)
+	for _, h := range hours {
+		if h.Weekday < 0 || h.Weekday > 6 {
+			return fmt.Errorf("weekday must be between 0 (sunday) and 6 (saturday)")
+		}
+		if seen[h.Weekday] {
+			return fmt.Errorf("opening hours for %s informed more than once", time.Weekday(h.Weekday))
+		}
+		seen[h.Weekday] = true
+
+		opensAt, err := time.Parse("15:04", h.OpensAt)
+		if err != nil {
+			return fmt.Errorf("invalid opening time '%s', expected HH:MM", h.OpensAt)
+		}
+		closesAt, err := time.Parse("15:04", h.ClosesAt)
+		if err != nil {
+			return fmt.Errorf("invalid closing time '%s', expected HH:MM", h.ClosesAt)
+		}
+		if !closesAt.After(opensAt) {
+			return fmt.Errorf("closing time must be after opening time on %s", time.Weekday(h.Weekday))
+		}
+	}
+	return cu.calendarRepo.SetOpeningHours(hours)
+}
+
+func (cu *calendarUseCase) GetClosedDates(from, to string) (*[]model.ClosedDate, error) {
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", date)
+		}
+	}
+	return cu.calendarRepo.GetClosedDates(from, to)
+}
+
+func (cu *calendarUseCase) CreateClosedDate(date, reason string) error {
+	closedDate, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", date)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("closed date reason cannot be empty")
+	}
+	return cu.calendarRepo.CreateClosedDate(model.ClosedDate{Date: closedDate, Reason: reason})
+}
+
+func (cu *calendarUseCase) DeleteClosedDate(date string) error {
+	closedDate, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", date)
+	}
+	return cu.calendarRepo.DeleteClosedDate(closedDate)
+}
+
+// ImportNationalHolidays cadastra como dias fechados os feriados nacionais do ano informado (ou de todos os anos
+// disponíveis, quando 0) e retorna quantas datas foram adicionadas. Datas já cadastradas são mantidas.
+func (cu *calendarUseCase) ImportNationalHolidays(year int) (int, error) {
+	holidays, err := utils.BrazilianNationalHolidays(year)
+	if err != nil {
+		return 0, err
+	}
+	if len(holidays) == 0 {
+		return 0, fmt.Errorf("no national holidays available for %d", year)
+	}
+
+	closedDates := make([]model.ClosedDate, 0, len(holidays))
+	for _, holiday := range holidays {
+		closedDates = append(closedDates, model.ClosedDate{Date: holiday.Date, Reason: holiday.Name})
+	}
+	return cu.calendarRepo.ImportClosedDates(closedDates)
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model"
+	"go-api/model/user"
+	"go-api/repository"
+	"time"
+)
+
+// circulationPolicy avalia as regras de circulação usadas nas reservas, empréstimos e renovações. As regras gerais
+// vêm de circulation_policy e os limites de cada leitor da sua categoria. Uma recusa é retornada como
+// *model.PolicyDenial, explicando qual regra impediu a operação.
+type circulationPolicy struct {
+	policyRepo      repository.CirculationPolicyRepository
+	userRepo        repository.UserRepository
+	reservationRepo repository.ReservationRepository
+}
+
+func newCirculationPolicy(policyRepo repository.CirculationPolicyRepository, userRepo repository.UserRepository,
+	reservationRepo repository.ReservationRepository) *circulationPolicy {
+	return &circulationPolicy{policyRepo: policyRepo, userRepo: userRepo, reservationRepo: reservationRepo}
+}
+
+func deny(code model.PolicyDenialCode, details map[string]any, format string, args ...any) error {
+	return &model.PolicyDenial{Code: code, Message: fmt.Sprintf(format, args...), Details: details}
+}
+
+// CheckReservation verifica se o usuário pode reservar um livro pelo prazo informado.
+func (cp *circulationPolicy) CheckReservation(userId, borrowedDays int) error {
+	policy, err := cp.policyRepo.GetCirculationPolicy()
+	if err != nil {
+		return err
+	}
+	account, err := cp.userRepo.GetUserById(userId)
+	if err != nil {
+		return fmt.Errorf("error when searching for user: %w", err)
+	}
+	if err := cp.checkPatronStanding(policy, account); err != nil {
+		return err
+	}
+
+	category := account.PatronCategory
+	if !category.AllowsLoanDays(borrowedDays) {
+		return deny(model.DenialLoanDaysNotAllowed,
+			map[string]any{"patron_category": category.Name, "loan_days": category.LoanDays},
+			"borrowed days for patron category '%s' must be one of %v", category.Name, category.LoanDays)
+	}
+
+	activeItems, err := cp.countActiveItems(userId)
+	if err != nil {
+		return err
+	}
+	if activeItems >= category.MaxItems {
+		return deny(model.DenialItemLimitReached,
+			map[string]any{"patron_category": category.Name, "max_items": category.MaxItems, "active_items": activeItems},
+			"user already has %d or more active reservations/loans", category.MaxItems)
+	}
+	return nil
+}
+
+// CheckCheckout verifica se o usuário pode retirar o livro de uma reserva.
+func (cp *circulationPolicy) CheckCheckout(userId int) error {
+	policy, err := cp.policyRepo.GetCirculationPolicy()
+	if err != nil {
+		return err
+	}
+	account, err := cp.userRepo.GetUserById(userId)
+	if err != nil {
+		return fmt.Errorf("error when searching for user: %w", err)
+	}
+	return cp.checkPatronStanding(policy, account)
+}
+
+// CheckRenewal verifica se o empréstimo pode ser renovado e retorna o limite de renovações da categoria do leitor.
+func (cp *circulationPolicy) CheckRenewal(loan *model.Loan) (int, error) {
+	policy, err := cp.policyRepo.GetCirculationPolicy()
+	if err != nil {
+		return 0, err
+	}
+
+	if loan.Status != model.LoanBorrowed {
+		return 0, deny(model.DenialLoanNotBorrowed, nil, "loan is not borrowed")
+	}
+	if loan.ReturnBy.Before(time.Now()) {
+		return 0, deny(model.DenialLoanOverdue, map[string]any{"return_by": loan.ReturnBy},
+			"overdue loans cannot be renewed")
+	}
+
+	account, err := cp.userRepo.GetUserById(loan.UserAccount.Id)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching loan user: %w", err)
+	}
+	category := account.PatronCategory
+	if loan.RenewalCount >= category.MaxRenewals {
+		return 0, deny(model.DenialRenewalLimitReached,
+			map[string]any{"patron_category": category.Name, "max_renewals": category.MaxRenewals,
+				"renewal_count": loan.RenewalCount},
+			"loan has reached the limit of %d renewals for patron category '%s'", category.MaxRenewals, category.Name)
+	}
+
+	if policy.RenewalBlockedByReservations {
+		reservations, err := cp.reservationRepo.GetReservationsByBookId(loan.BookStock.BookId,
+			string(model.ReservationPending))
+		if err != nil {
+			return 0, err
+		}
+		waiting := 0
+		for _, reservation := range *reservations {
+			if reservation.Status == model.ReservationPending && reservation.UserAccount.Id != account.Id {
+				waiting++
+			}
+		}
+		if waiting > 0 {
+			return 0, deny(model.DenialBookReserved, map[string]any{"pending_reservations": waiting},
+				"book has pending reservations from other patrons")
+		}
+	}
+
+	return category.MaxRenewals, nil
+}
+
+// checkPatronStanding aplica as regras que impedem um leitor de reservar ou retirar livros: conta inativa,
+// carteirinha vencida, empréstimos atrasados e multas em aberto.
+func (cp *circulationPolicy) checkPatronStanding(policy *model.CirculationPolicy, account *user.Account) error {
+	if !account.IsActive {
+		return deny(model.DenialUserInactive, nil, "user is not active")
+	}
+	if policy.RequireValidMembership && account.Membership.Expired {
+		return deny(model.DenialMembershipExpired, map[string]any{"expires_on": account.Membership.ExpiresOn},
+			"user membership expired on %s and must be renewed", account.Membership.ExpiresOn.Format(time.DateOnly))
+	}
+
+	if policy.BlockOnOverdueLoans {
+		loans, err := cp.userRepo.GetUserLoans(account.Id)
+		if err != nil {
+			return fmt.Errorf("error when searching for user loans: %w", err)
+		}
+		overdue := 0
+		for _, loan := range *loans {
+			if loan.Status == model.LoanBorrowed && loan.ReturnBy.Before(time.Now()) {
+				overdue++
+			}
+		}
+		if overdue > 0 {
+			return deny(model.DenialOverdueLoans, map[string]any{"overdue_loans": overdue}, "user has overdue loans")
+		}
+	}
+
+	if policy.MaxUnpaidFines != nil {
+		fines, err := cp.userRepo.GetUserFines(account.Id)
+		if err != nil {
+			return err
+		}
+		unpaid := 0.0
+		for _, fine := range *fines {
+			if fine.PaidAt == nil {
+				unpaid += fine.Amount
+			}
+		}
+		if unpaid > *policy.MaxUnpaidFines {
+			return deny(model.DenialUnpaidFines,
+				map[string]any{"unpaid_fines": unpaid, "max_unpaid_fines": *policy.MaxUnpaidFines},
+				"user has %.2f in unpaid fines, above the limit of %.2f", unpaid, *policy.MaxUnpaidFines)
+		}
+	}
+	return nil
+}
+
+// countActiveItems soma as reservas pendentes e os empréstimos ativos do usuário.
+func (cp *circulationPolicy) countActiveItems(userId int) (int, error) {
+	loans, err := cp.userRepo.GetUserLoans(userId)
+	if err != nil {
+		return 0, fmt.Errorf("error when searching for user loans: %w", err)
+	}
+	reservations, err := cp.userRepo.GetUserReservations(userId)
+	if err != nil {
+		return 0, fmt.Errorf("error when searching for user reservations: %w", err)
+	}
+
+	active := 0
+	for _, loan := range *loans {
+		if loan.Status == model.LoanBorrowed {
+			active++
+		}
+	}
+	for _, reservation := range *reservations {
+		if reservation.Status == model.ReservationPending {
+			active++
+		}
+	}
+	return active, nil
+}
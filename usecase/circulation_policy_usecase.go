@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model"
+	"go-api/repository"
+)
+
+type CirculationPolicyUseCase interface {
+	GetCirculationPolicy() (*model.CirculationPolicy, error)
+	UpdateCirculationPolicy(policy model.CirculationPolicy, adminId int) (*model.CirculationPolicy, error)
+}
+
+type circulationPolicyUseCase struct {
+	policyRepo repository.CirculationPolicyRepository
+}
+
+func NewCirculationPolicyUseCase(policyRepo repository.CirculationPolicyRepository) CirculationPolicyUseCase {
+	return &circulationPolicyUseCase{policyRepo: policyRepo}
+}
+
+func (cu *circulationPolicyUseCase) GetCirculationPolicy() (*model.CirculationPolicy, error) {
+	return cu.policyRepo.GetCirculationPolicy()
+}
+
+// UpdateCirculationPolicy substitui as regras de circulação. Um novo prazo de reserva vale apenas para as reservas
+// criadas depois da alteração.
+func (cu *circulationPolicyUseCase) UpdateCirculationPolicy(policy model.CirculationPolicy, adminId int) (*model.CirculationPolicy, error) {
+	if policy.ReservationWindowHours <= 0 {
+		return nil, fmt.Errorf("reservation window must be greater than zero")
+	}
+	if policy.MaxUnpaidFines != nil && *policy.MaxUnpaidFines < 0 {
+		return nil, fmt.Errorf("max unpaid fines cannot be negative")
+	}
+
+	if err := cu.policyRepo.UpdateCirculationPolicy(policy, adminId); err != nil {
+		return nil, err
+	}
+	return cu.policyRepo.GetCirculationPolicy()
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model"
+	"go-api/repository"
+)
+
+type InventoryUseCase interface {
+	OpenSession(branch, section string, adminId int) (*model.InventorySession, error)
+	GetSessions(status model.InventorySessionStatus) (*[]model.InventorySession, error)
+	GetSessionById(id int) (*model.InventorySession, error)
+	AddScans(sessionId int, codes []int) (int, error)
+	CloseSession(id, adminId int) (*model.InventoryReport, error)
+	GetReport(id int) (*model.InventoryReport, error)
+	ApplyReport(id, adminId int, markMissing, markAvailable bool) (*model.InventoryApplyResult, error)
+}
+
+type inventoryUseCase struct {
+	inventoryRepo repository.InventoryRepository
+	bookRepo      repository.BookRepository
+}
+
+func NewInventoryUseCase(inventoryRepo repository.InventoryRepository, bookRepo repository.BookRepository) InventoryUseCase {
+	return &inventoryUseCase{inventoryRepo: inventoryRepo, bookRepo: bookRepo}
+}
+
+func (iu *inventoryUseCase) OpenSession(branch, section string, adminId int) (*model.InventorySession, error) {
+	return iu.inventoryRepo.CreateSession(branch, section, adminId)
+}
+
+func (iu *inventoryUseCase) GetSessions(status model.InventorySessionStatus) (*[]model.InventorySession, error) {
+	return iu.inventoryRepo.GetSessions(status)
+}
+
+func (iu *inventoryUseCase) GetSessionById(id int) (*model.InventorySession, error) {
+	return iu.inventoryRepo.GetSessionById(id)
+}
+
+// AddScans registra um lote de códigos lidos pelo scanner em uma sessão aberta.
+func (iu *inventoryUseCase) AddScans(sessionId int, codes []int) (int, error) {
+	session, err := iu.inventoryRepo.GetSessionById(sessionId)
+	if err != nil {
+		return 0, err
+	}
+
+	if session.Status != model.InventorySessionOpen {
+		return 0, fmt.Errorf("inventory session is not open")
+	}
+
+	return iu.inventoryRepo.AddScans(sessionId, codes)
+}
+
+// CloseSession encerra uma sessão aberta e retorna sua reconciliação.
+func (iu *inventoryUseCase) CloseSession(id, adminId int) (*model.InventoryReport, error) {
+	session, err := iu.inventoryRepo.GetSessionById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != model.InventorySessionOpen {
+		return nil, fmt.Errorf("inventory session is not open")
+	}
+
+	if err := iu.inventoryRepo.CloseSession(id, adminId); err != nil {
+		return nil, err
+	}
+
+	return iu.GetReport(id)
+}
+
+// GetReport compara os exemplares esperados na estante com os códigos lidos na sessão. Sessões abertas são comparadas
+// com o acervo atual; sessões encerradas retornam a reconciliação gravada no encerramento.
+func (iu *inventoryUseCase) GetReport(id int) (*model.InventoryReport, error) {
+	session, err := iu.inventoryRepo.GetSessionById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.InventoryReport{Session: *session}
+	if session.Status == model.InventorySessionClosed {
+		return iu.getClosedReport(report)
+	}
+
+	report.ExpectedNotScanned, err = iu.inventoryRepo.GetExpectedNotScanned(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report.ScannedMissing, err = iu.inventoryRepo.GetScannedByStatus(id, model.BookStockMissing)
+	if err != nil {
+		return nil, err
+	}
+
+	report.ScannedBorrowed, err = iu.inventoryRepo.GetScannedByStatus(id, model.BookStockBorrowed)
+	if err != nil {
+		return nil, err
+	}
+
+	report.UnknownCodes, err = iu.inventoryRepo.GetUnknownCodes(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// getClosedReport preenche o relatório com a reconciliação gravada no encerramento da sessão.
+func (iu *inventoryUseCase) getClosedReport(report *model.InventoryReport) (*model.InventoryReport, error) {
+	id := report.Session.Id
+	var err error
+
+	report.ExpectedNotScanned, err = iu.inventoryRepo.GetDiscrepancies(id, model.InventoryNotScanned)
+	if err != nil {
+		return nil, err
+	}
+
+	report.ScannedMissing, err = iu.inventoryRepo.GetDiscrepancies(id, model.InventoryScannedMissing)
+	if err != nil {
+		return nil, err
+	}
+
+	report.ScannedBorrowed, err = iu.inventoryRepo.GetDiscrepancies(id, model.InventoryScannedBorrowed)
+	if err != nil {
+		return nil, err
+	}
+
+	report.UnknownCodes, err = iu.inventoryRepo.GetUnknownCodeDiscrepancies(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ApplyReport aplica em lote a reconciliação gravada no encerramento da sessão: exemplares esperados e não lidos
+// passam a 'missing' e exemplares 'missing' que foram lidos voltam a 'available'. Exemplares cujo status mudou desde o
+// encerramento são mantidos. Falhas em um exemplar não interrompem os demais e são retornadas no resultado.
+func (iu *inventoryUseCase) ApplyReport(id, adminId int, markMissing, markAvailable bool) (*model.InventoryApplyResult, error) {
+	session, err := iu.inventoryRepo.GetSessionById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != model.InventorySessionClosed {
+		return nil, fmt.Errorf("inventory session must be closed before applying its result")
+	}
+
+	// A sessão é marcada antes de qualquer exemplar ser alterado, para que duas aplicações simultâneas não
+	// alterem os exemplares duas vezes
+	if err := iu.inventoryRepo.MarkSessionApplied(id); err != nil {
+		return nil, err
+	}
+
+	report, err := iu.GetReport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.InventoryApplyResult{
+		MarkedMissing:   make([]int, 0),
+		MarkedAvailable: make([]int, 0),
+		Failed:          make([]model.InventoryError, 0),
+	}
+
+	if markMissing {
+		reason := fmt.Sprintf("inventory session %d: not found on shelf", id)
+		for _, item := range report.ExpectedNotScanned {
+			err := iu.applyStockStatus(item, model.BookStockMissing, adminId, reason)
+			if err != nil {
+				result.Failed = append(result.Failed, model.InventoryError{BookStockId: item.BookStockId, Error: err.Error()})
+				continue
+			}
+			result.MarkedMissing = append(result.MarkedMissing, item.BookStockId)
+		}
+	}
+
+	if markAvailable {
+		reason := fmt.Sprintf("inventory session %d: found on shelf", id)
+		for _, item := range report.ScannedMissing {
+			err := iu.applyStockStatus(item, model.BookStockAvailable, adminId, reason)
+			if err != nil {
+				result.Failed = append(result.Failed, model.InventoryError{BookStockId: item.BookStockId, Error: err.Error()})
+				continue
+			}
+			result.MarkedAvailable = append(result.MarkedAvailable, item.BookStockId)
+		}
+	}
+
+	return result, nil
+}
+
+// applyStockStatus muda o status de um exemplar da reconciliação, desde que ele ainda esteja com o status que tinha
+// quando a sessão foi encerrada.
+func (iu *inventoryUseCase) applyStockStatus(item model.InventoryItem, status model.BookStockStatus, adminId int, reason string) error {
+	stock, err := iu.bookRepo.GetStockById(item.BookStockId)
+	if err != nil {
+		return err
+	}
+	if stock.Status != item.Status {
+		return fmt.Errorf("book stock status changed from '%s' to '%s' since the session was closed", item.Status, stock.Status)
+	}
+	return iu.bookRepo.UpdateStockStatus(item.BookStockId, string(status), &adminId, reason, nil)
+}
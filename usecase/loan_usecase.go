@@ -3,30 +3,55 @@ package usecase
 import (
 	"fmt"
 	"go-api/model"
+	"go-api/notification"
 	"go-api/repository"
+	"log"
+	"strconv"
+	"time"
 )
 
 type LoanUseCase interface {
 	CreateLoanAndUpdateReservation(reservationId, bookStockId, adminId int) (*model.Loan, error)
+	CreateWalkInLoan(userId, bookStockCode, borrowedDays, adminId int) (*model.Loan, error)
 	GetLoansByFilters(userName string, status model.LoanStatus, loanedAt string) (*[]model.Loan, error)
 	GetLoanById(id int) (*model.Loan, error)
-	FinishLoan(loanId, adminId int) error
+	FinishLoan(loanId, adminId int, report *model.LoanReturnRequest) error
+	CheckIn(request model.CheckInRequest, adminId int) (*model.CheckInResult, error)
+	RenewLoan(loanId int, userId *int) (*model.Loan, error)
+}
+
+// InvalidReturnReportError indica que o relatório de devolução foi recusado por ser inválido.
+type InvalidReturnReportError struct {
+	Message string
+}
+
+func (e *InvalidReturnReportError) Error() string {
+	return e.Message
 }
 
 type loanUseCase struct {
 	loanRepo        repository.LoanRepository
 	bookRepo        repository.BookRepository
 	reservationRepo repository.ReservationRepository
+	userRepo        repository.UserRepository
+	policy          *circulationPolicy
+	notifier        NotificationUseCase
 }
 
 func NewLoanUseCase(
 	loanRepo repository.LoanRepository,
 	reservationRepo repository.ReservationRepository,
-	bookStockRepo repository.BookRepository) LoanUseCase {
+	bookStockRepo repository.BookRepository,
+	userRepo repository.UserRepository,
+	policyRepo repository.CirculationPolicyRepository,
+	notifier NotificationUseCase) LoanUseCase {
 	return &loanUseCase{
 		loanRepo:        loanRepo,
 		bookRepo:        bookStockRepo,
 		reservationRepo: reservationRepo,
+		userRepo:        userRepo,
+		policy:          newCirculationPolicy(policyRepo, userRepo, reservationRepo),
+		notifier:        notifier,
 	}
 }
 
@@ -44,6 +69,10 @@ func (lu *loanUseCase) CreateLoanAndUpdateReservation(reservationId, bookStockId
 		return nil, fmt.Errorf("reservation is not pending")
 	}
 
+	if err := lu.policy.CheckCheckout(reservation.UserAccount.Id); err != nil {
+		return nil, err
+	}
+
 	bookStock, err := lu.bookRepo.GetStockById(bookStockId)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching book stock: %w", err)
@@ -58,19 +87,59 @@ func (lu *loanUseCase) CreateLoanAndUpdateReservation(reservationId, bookStockId
 		return nil, fmt.Errorf("failed to update reservation status: %w", err)
 	}
 
-	err = lu.bookRepo.UpdateStockStatus(bookStockId, "borrowed")
+	createdLoan, err := lu.loanRepo.CreateLoan(reservationId, bookStockId, reservation.BorrowedDays)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update book stock status: %w", err)
+		return nil, fmt.Errorf("error creating loan: %w", err)
 	}
 
-	createdLoan, err := lu.loanRepo.CreateLoan(reservationId, bookStockId, reservation.BorrowedDays)
+	// O status do exemplar é atualizado após a criação do empréstimo para que o histórico o referencie
+	err = lu.bookRepo.UpdateStockStatus(bookStockId, string(model.BookStockBorrowed), &adminId, "loan created", &createdLoan.Id)
 	if err != nil {
-		return nil, fmt.Errorf("error creating loan: %w", err)
+		return nil, fmt.Errorf("failed to update book stock status: %w", err)
+	}
+
+	// A reserva saiu da fila, então as reservas seguintes do livro avançam uma posição
+	if err := lu.notifier.NotifyHoldPositions(reservation.Book.Id, reservation.ReservedAt); err != nil {
+		log.Printf("error notifying hold positions of book %d: %v", reservation.Book.Id, err)
 	}
 
 	return createdLoan, nil
 }
 
+// CreateWalkInLoan empresta um exemplar, identificado pelo código da etiqueta, a um leitor no balcão sem que ele
+// precise reservar antes. As mesmas regras de circulação de uma reserva são aplicadas, e o exemplar não pode ser
+// emprestado se todos os disponíveis estiverem aguardando reservas pendentes.
+func (lu *loanUseCase) CreateWalkInLoan(userId, bookStockCode, borrowedDays, adminId int) (*model.Loan, error) {
+	bookStock, err := lu.bookRepo.GetStockByCode(bookStockCode)
+	if err != nil {
+		return nil, err
+	}
+	if bookStock.Status != model.BookStockAvailable {
+		return nil, fmt.Errorf("book stock is not available")
+	}
+
+	if err := lu.policy.CheckReservation(userId, borrowedDays); err != nil {
+		return nil, err
+	}
+
+	bUseCase := NewBookUseCase(lu.bookRepo, lu.reservationRepo)
+	amount, err := bUseCase.CountAvailableBookStockById(bookStock.BookId)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting book stock amount: %w", err)
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("all available copies are held for pending reservations")
+	}
+
+	loan, err := lu.loanRepo.CreateWalkInLoan(userId, bookStock.Id, borrowedDays, adminId)
+	if err != nil {
+		return nil, err
+	}
+	bookStock.Status = model.BookStockBorrowed
+	loan.BookStock = bookStock
+	return loan, nil
+}
+
 func (lu *loanUseCase) GetLoansByFilters(userName string, status model.LoanStatus, loanedAt string) (*[]model.Loan, error) {
 	return lu.loanRepo.GetLoansByFilters(userName, status, loanedAt)
 }
@@ -79,20 +148,159 @@ func (lu *loanUseCase) GetLoanById(id int) (*model.Loan, error) {
 	return lu.loanRepo.GetLoanById(id)
 }
 
-func (lu *loanUseCase) FinishLoan(loanId, adminId int) error {
+// FinishLoan finaliza um empréstimo ativo, cobrando multa por atraso conforme a categoria de leitor do usuário. Se
+// um relatório de devolução for informado, atualiza a condição do exemplar e, havendo dano, registra o dano, retira
+// o exemplar de circulação e cobra o usuário quando houver valor.
+func (lu *loanUseCase) FinishLoan(loanId, adminId int, report *model.LoanReturnRequest) error {
 	loan, err := lu.loanRepo.GetLoanById(loanId)
 	if err != nil {
 		return err
 	}
 
+	_, err = lu.finishLoan(loan, adminId, report)
+	return err
+}
+
+// CheckIn registra a devolução de um exemplar pelo código da etiqueta, finalizando o seu empréstimo em andamento, e
+// indica o que fazer com ele: enviá-lo para reparo se voltou danificado, separá-lo para a reserva pendente mais antiga
+// do livro, transferi-lo para a unidade de origem ou devolvê-lo à estante.
+func (lu *loanUseCase) CheckIn(request model.CheckInRequest, adminId int) (*model.CheckInResult, error) {
+	bookStock, err := lu.bookRepo.GetStockByCode(request.Code)
+	if err != nil {
+		return nil, err
+	}
+	loan, err := lu.loanRepo.GetActiveLoanByStockId(bookStock.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	fines, err := lu.finishLoan(loan, adminId, &request.LoanReturnRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	loan, err = lu.loanRepo.GetLoanById(loan.Id)
+	if err != nil {
+		return nil, err
+	}
+	result := &model.CheckInResult{Loan: loan, Fines: fines}
+
+	if request.DamageNotes != "" {
+		result.Action = model.CheckInRepair
+		return result, nil
+	}
+
+	// A reserva pendente mais antiga ainda dentro do prazo recebe o exemplar; as vencidas são ignoradas
+	reservations, err := lu.reservationRepo.GetReservationsByBookId(bookStock.BookId, string(model.ReservationPending))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, reservation := range *reservations {
+		if reservation.Status != model.ReservationPending || !reservation.ExpiresAt.After(now) {
+			continue
+		}
+		if result.Reservation == nil || reservation.ReservedAt.Before(result.Reservation.ReservedAt) {
+			next := reservation
+			result.Reservation = &next
+		}
+	}
+	if result.Reservation != nil {
+		result.Action = model.CheckInHold
+		return result, nil
+	}
+
+	result.Branch = bookStock.Branch
+	if request.Branch != "" && bookStock.Branch != "" && request.Branch != bookStock.Branch {
+		result.Action = model.CheckInTransfer
+		return result, nil
+	}
+
+	result.Action = model.CheckInReshelve
+	result.Section = bookStock.Section
+	return result, nil
+}
+
+// finishLoan finaliza o empréstimo e retorna as multas cobradas na devolução.
+func (lu *loanUseCase) finishLoan(loan *model.Loan, adminId int, report *model.LoanReturnRequest) ([]model.Fine, error) {
 	if loan.Status != model.LoanBorrowed {
-		return fmt.Errorf("loan is not borrowed")
+		return nil, repository.ErrLoanNotBorrowed
+	}
+
+	if report != nil {
+		if report.Condition != "" && !report.Condition.IsValid() {
+			return nil, &InvalidReturnReportError{Message: fmt.Sprintf("invalid book stock condition '%s'", report.Condition)}
+		}
+		if report.DamageCharge < 0 {
+			return nil, &InvalidReturnReportError{Message: "damage charge cannot be negative"}
+		}
+		if report.DamageCharge > 0 && report.DamageNotes == "" {
+			return nil, &InvalidReturnReportError{Message: "damage charge requires damage notes"}
+		}
+	}
+
+	borrower, err := lu.userRepo.GetUserById(loan.UserAccount.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching loan user: %w", err)
 	}
 
-	err = lu.loanRepo.FinishLoan(loanId, adminId)
+	fines, err := lu.loanRepo.ReturnLoan(loan.Id, adminId, borrower.PatronCategory.DailyFineRate, report)
+	if err != nil {
+		return nil, err
+	}
+
+	// A devolução já foi registrada; uma falha ao avisar o leitor não deve desfazê-la
+	if err := lu.notifyFines(loan, fines); err != nil {
+		log.Printf("error notifying fines of loan %d: %v", loan.Id, err)
+	}
+	return fines, nil
+}
+
+// notifyFines avisa o leitor de cada multa cobrada na devolução.
+func (lu *loanUseCase) notifyFines(loan *model.Loan, fines []model.Fine) error {
+	if len(fines) == 0 {
+		return nil
+	}
+
+	book, err := lu.bookRepo.GetBookById(loan.BookStock.BookId)
 	if err != nil {
 		return err
 	}
 
+	for _, fine := range fines {
+		data := notification.Data{BookTitle: book.Title, Amount: fine.Amount}
+		err := lu.notifier.Notify(fine.UserId, model.EventFineIssued, data, "fine_issued:"+strconv.Itoa(fine.Id))
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// RenewLoan renova um empréstimo ativo pela mesma duração da reserva original, se as regras de circulação
+// permitirem. Quando userId é informado, o empréstimo precisa pertencer a esse usuário.
+func (lu *loanUseCase) RenewLoan(loanId int, userId *int) (*model.Loan, error) {
+	loan, err := lu.loanRepo.GetLoanById(loanId)
+	if err != nil {
+		return nil, err
+	}
+
+	// O empréstimo de outro leitor é tratado como inexistente, para não revelar quais ids existem
+	if userId != nil && loan.UserAccount.Id != *userId {
+		return nil, &repository.NotFoundError{Message: fmt.Sprintf("loan with id %d not found", loanId)}
+	}
+
+	maxRenewals, err := lu.policy.CheckRenewal(loan)
+	if err != nil {
+		return nil, err
+	}
+
+	renewed, err := lu.loanRepo.RenewLoan(loanId, maxRenewals)
+	if err != nil {
+		return nil, err
+	}
+
+	loan.ReturnBy = renewed.ReturnBy
+	loan.RenewalCount = renewed.RenewalCount
+	return loan, nil
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"go-api/model"
+	"go-api/model/user"
+	"go-api/repository"
+	"testing"
+	"time"
+)
+
+// loanTestRepository guarda os empréstimos em memória pelo id.
+type loanTestRepository struct {
+	repository.LoanRepository
+	loans map[int]*model.Loan
+}
+
+func (r *loanTestRepository) GetLoanById(id int) (*model.Loan, error) {
+	loan, ok := r.loans[id]
+	if !ok {
+		return nil, &repository.NotFoundError{Message: fmt.Sprintf("loan with id %d not found", id)}
+	}
+	copied := *loan
+	return &copied, nil
+}
+
+func (r *loanTestRepository) GetActiveLoanByStockId(bookStockId int) (*model.Loan, error) {
+	for _, loan := range r.loans {
+		if loan.BookStock != nil && loan.BookStock.Id == bookStockId && loan.Status == model.LoanBorrowed {
+			copied := *loan
+			return &copied, nil
+		}
+	}
+	return nil, &repository.NotFoundError{Message: fmt.Sprintf("no active loan for book stock %d", bookStockId)}
+}
+
+func (r *loanTestRepository) ReturnLoan(id, adminId int, dailyFineRate float64, report *model.LoanReturnRequest) ([]model.Fine, error) {
+	r.loans[id].Status = model.LoanReturned
+	return nil, nil
+}
+
+type checkInBookRepository struct {
+	repository.BookRepository
+	stock model.BookStock
+}
+
+func (r *checkInBookRepository) GetStockByCode(code int) (*model.BookStock, error) {
+	return &r.stock, nil
+}
+
+type bookReservationRepository struct {
+	repository.ReservationRepository
+	reservations []model.Reservation
+}
+
+func (r *bookReservationRepository) GetReservationsByBookId(id int, status string) (*[]model.Reservation, error) {
+	return &r.reservations, nil
+}
+
+type patronUserRepository struct {
+	repository.UserRepository
+}
+
+func (patronUserRepository) GetUserById(id int) (*user.Account, error) {
+	return &user.Account{Id: id, PatronCategory: &user.PatronCategory{}}, nil
+}
+
+type defaultPolicyRepository struct {
+	repository.CirculationPolicyRepository
+}
+
+func (defaultPolicyRepository) GetCirculationPolicy() (*model.CirculationPolicy, error) {
+	return &model.CirculationPolicy{}, nil
+}
+
+func TestRenewLoanErrors(t *testing.T) {
+	ownerId, otherId := 1, 2
+	loanRepo := &loanTestRepository{loans: map[int]*model.Loan{
+		10: {Id: 10, Status: model.LoanBorrowed, ReturnBy: time.Now().Add(48 * time.Hour),
+			UserAccount: &user.Account{Id: ownerId}},
+		11: {Id: 11, Status: model.LoanReturned, ReturnBy: time.Now().Add(48 * time.Hour),
+			UserAccount: &user.Account{Id: ownerId}},
+	}}
+	loanUseCase := NewLoanUseCase(loanRepo, nil, nil, nil, defaultPolicyRepository{}, nil)
+
+	tests := []struct {
+		name       string
+		loanId     int
+		userId     *int
+		wantDenial model.PolicyDenialCode
+	}{
+		{"missing loan", 99, nil, ""},
+		{"loan of another patron", 10, &otherId, ""},
+		{"returned loan", 11, nil, model.DenialLoanNotBorrowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loanUseCase.RenewLoan(tt.loanId, tt.userId)
+
+			var notFoundErr *repository.NotFoundError
+			var denial *model.PolicyDenial
+			switch {
+			case tt.wantDenial == "" && !errors.As(err, &notFoundErr):
+				t.Errorf("RenewLoan() error = %v, want a NotFoundError", err)
+			case tt.wantDenial != "" && (!errors.As(err, &denial) || denial.Code != tt.wantDenial):
+				t.Errorf("RenewLoan() error = %v, want the denial %s", err, tt.wantDenial)
+			}
+		})
+	}
+}
+
+func TestCheckInSkipsExpiredReservations(t *testing.T) {
+	now := time.Now()
+	pending := func(id int, reservedAgo, expiresIn time.Duration) model.Reservation {
+		return model.Reservation{Id: id, Status: model.ReservationPending, ReservedAt: now.Add(-reservedAgo),
+			ExpiresAt: now.Add(expiresIn)}
+	}
+
+	tests := []struct {
+		name            string
+		reservations    []model.Reservation
+		wantAction      model.CheckInAction
+		wantReservation int
+	}{
+		{"oldest live reservation", []model.Reservation{pending(1, 48*time.Hour, 24*time.Hour),
+			pending(2, 72*time.Hour, 24*time.Hour)}, model.CheckInHold, 2},
+		{"older reservation expired", []model.Reservation{pending(1, 48*time.Hour, 24*time.Hour),
+			pending(2, 72*time.Hour, -time.Hour)}, model.CheckInHold, 1},
+		{"every reservation expired", []model.Reservation{pending(1, 48*time.Hour, -time.Minute)},
+			model.CheckInReshelve, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stock := model.BookStock{Id: 5, BookId: 7, Code: 1005}
+			loanRepo := &loanTestRepository{loans: map[int]*model.Loan{
+				20: {Id: 20, Status: model.LoanBorrowed, BookStock: &stock, UserAccount: &user.Account{Id: 1}},
+			}}
+			loanUseCase := NewLoanUseCase(loanRepo, &bookReservationRepository{reservations: tt.reservations},
+				&checkInBookRepository{stock: stock}, patronUserRepository{}, defaultPolicyRepository{}, nil)
+
+			result, err := loanUseCase.CheckIn(model.CheckInRequest{Code: stock.Code}, 99)
+			if err != nil {
+				t.Fatalf("CheckIn() error = %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Errorf("CheckIn() action = %s, want %s", result.Action, tt.wantAction)
+			}
+			reservationId := 0
+			if result.Reservation != nil {
+				reservationId = result.Reservation.Id
+			}
+			if reservationId != tt.wantReservation {
+				t.Errorf("CheckIn() reservation = %d, want %d", reservationId, tt.wantReservation)
+			}
+		})
+	}
+}
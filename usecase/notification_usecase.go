@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-api/model"
+	"go-api/notification"
+	"go-api/repository"
+	"go-api/webhook"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxDeliveryAttempts é o número de tentativas de entrega antes de a notificação ser dada como falha.
+const maxDeliveryAttempts = 5
+
+// deliveryLease é por quanto tempo uma entrega fica reservada a quem vai enviá-la. Deve ser maior que o tempo máximo
+// de um envio (os timeouts do webhook e do SMTP), para que o reenvio não a pegue enquanto ela ainda está em curso.
+const deliveryLease = 2 * time.Minute
+
+// reservationExpiringHours é a antecedência do lembrete de reserva perto de expirar.
+const reservationExpiringHours = 3
+
+type NotificationUseCase interface {
+	Notify(userId int, event model.NotificationEvent, data notification.Data, dedupeKey string) error
+	SendReminders() error
+	RetryDeliveries() error
+	GetPreferences(userId int) (*model.NotificationPreferences, error)
+	UpdatePreferences(userId int, preferences model.NotificationPreferences) error
+	GetDeliveries(status model.NotificationDeliveryStatus, userId int) (*[]model.NotificationDelivery, error)
+	NotifyHoldPositions(bookId int, after time.Time) error
+	GetInbox(userId int, unreadOnly bool) (*[]model.Notification, error)
+	CountUnread(userId int) (int, error)
+	MarkRead(id, userId int) error
+	MarkAllRead(userId int) (int, error)
+	Subscribe(userId int) (<-chan model.Notification, func())
+}
+
+type notificationUseCase struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	channels         map[model.NotificationChannel]notification.Channel
+	hub              *notification.Hub
+	reminderDays     int
+}
+
+// NewNotificationUseCase cria e retorna uma nova instância de NotificationUseCase. Canais ausentes do mapa nunca
+// são usados; hub é o mesmo usado pelo canal da caixa de entrada, e reminderDays é a antecedência, em dias, do
+// lembrete de devolução.
+func NewNotificationUseCase(notificationRepo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	channels map[model.NotificationChannel]notification.Channel,
+	hub *notification.Hub,
+	reminderDays int) NotificationUseCase {
+	return &notificationUseCase{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		channels:         channels,
+		hub:              hub,
+		reminderDays:     reminderDays,
+	}
+}
+
+// Notify renderiza a mensagem do evento no idioma do usuário e a entrega por cada canal habilitado. Cada entrega é
+// registrada e enviada em segundo plano, para que um canal lento não atrase a requisição que gerou a notificação; as
+// que falham ou não chegam a ser enviadas ficam pendentes para o reenvio. dedupeKey identifica a notificação, e uma
+// chave já registrada para o canal não é enviada de novo.
+func (nu *notificationUseCase) Notify(userId int, event model.NotificationEvent, data notification.Data, dedupeKey string) error {
+	account, err := nu.userRepo.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	if !account.IsActive {
+		return nil
+	}
+
+	preferences, err := nu.notificationRepo.GetPreferences(userId)
+	if err != nil {
+		return err
+	}
+
+	data.Name = account.Name
+	subject, body, err := notification.Render(event, preferences.Locale, data)
+	if err != nil {
+		return err
+	}
+
+	recipients := make(map[model.NotificationChannel]string)
+	if preferences.InboxEnabled {
+		recipients[model.ChannelInbox] = ""
+	}
+	if preferences.EmailEnabled && account.Email != "" {
+		recipients[model.ChannelEmail] = account.Email
+	}
+	if preferences.WebhookUrl != nil {
+		recipients[model.ChannelWebhook] = *preferences.WebhookUrl
+	}
+
+	for channel, recipient := range recipients {
+		if _, ok := nu.channels[channel]; !ok {
+			continue
+		}
+
+		delivery := model.NotificationDelivery{
+			Event:     event,
+			Channel:   channel,
+			UserId:    userId,
+			Recipient: recipient,
+			Subject:   subject,
+			Body:      body,
+		}
+		id, err := nu.notificationRepo.CreateDelivery(delivery, dedupeKey, deliveryLease)
+		if err != nil {
+			return err
+		}
+		if id == nil {
+			continue
+		}
+		delivery.Id = *id
+
+		go func(delivery model.NotificationDelivery) {
+			if err := nu.deliver(delivery); err != nil {
+				log.Printf("error delivering notification %d: %v", delivery.Id, err)
+			}
+		}(delivery)
+	}
+	return nil
+}
+
+// deliver envia uma entrega registrada e grava o resultado. Só retorna erro se não conseguir gravar o resultado.
+func (nu *notificationUseCase) deliver(delivery model.NotificationDelivery) error {
+	channel, ok := nu.channels[delivery.Channel]
+	if !ok {
+		return nu.notificationRepo.MarkDeliveryFailed(delivery.Id,
+			fmt.Sprintf("channel '%s' is not configured", delivery.Channel), maxDeliveryAttempts)
+	}
+
+	err := channel.Send(notification.Message{
+		UserId:    delivery.UserId,
+		Event:     delivery.Event,
+		Recipient: delivery.Recipient,
+		Subject:   delivery.Subject,
+		Body:      delivery.Body,
+	})
+	if err != nil {
+		return nu.notificationRepo.MarkDeliveryFailed(delivery.Id, err.Error(), maxDeliveryAttempts)
+	}
+	return nu.notificationRepo.MarkDeliverySent(delivery.Id)
+}
+
+// SendReminders envia os lembretes de empréstimos perto do vencimento e de reservas perto de expirar que ainda não
+// foram enviados. Uma falha em um lembrete não impede os demais; os erros são retornados juntos no final.
+func (nu *notificationUseCase) SendReminders() error {
+	loans, err := nu.notificationRepo.GetLoanDueReminders(nu.reminderDays)
+	if err != nil {
+		return err
+	}
+	reservations, err := nu.notificationRepo.GetReservationExpiringReminders(reservationExpiringHours)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, reminder := range append(*loans, *reservations...) {
+		data := notification.Data{BookTitle: reminder.BookTitle}
+		if reminder.Event == model.EventLoanDue {
+			data.ReturnBy = reminder.Due
+		} else {
+			data.ExpiresAt = reminder.Due
+		}
+
+		if err := nu.Notify(reminder.UserId, reminder.Event, data, reminder.DedupeKey); err != nil {
+			log.Printf("error sending %s reminder to user %d: %v", reminder.Event, reminder.UserId, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RetryDeliveries envia as entregas pendentes cuja próxima tentativa já pode ser feita: as que falharam e as que não
+// foram enviadas logo após o registro. Uma falha em uma entrega não impede as demais.
+func (nu *notificationUseCase) RetryDeliveries() error {
+	deliveries, err := nu.notificationRepo.ClaimDeliveriesToRetry(deliveryLease)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, delivery := range *deliveries {
+		if err := nu.deliver(delivery); err != nil {
+			log.Printf("error delivering notification %d: %v", delivery.Id, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (nu *notificationUseCase) GetPreferences(userId int) (*model.NotificationPreferences, error) {
+	return nu.notificationRepo.GetPreferences(userId)
+}
+
+func (nu *notificationUseCase) UpdatePreferences(userId int, preferences model.NotificationPreferences) error {
+	if !notification.IsSupportedLocale(preferences.Locale) {
+		return fmt.Errorf("unsupported locale '%s'", preferences.Locale)
+	}
+
+	if preferences.WebhookUrl != nil {
+		if *preferences.WebhookUrl == "" {
+			preferences.WebhookUrl = nil
+		} else if err := validatePatronWebhookUrl(*preferences.WebhookUrl); err != nil {
+			return err
+		}
+	}
+
+	return nu.notificationRepo.UpdatePreferences(userId, preferences)
+}
+
+// validatePatronWebhookUrl confere a URL de webhook cadastrada por um leitor: além de absoluta, ela precisa usar https
+// e apontar para um host público, para que o servidor não seja usado para alcançar a rede interna. O envio confere o
+// endereço de novo ao conectar (webhook.NewPublicClient), já que o DNS pode mudar depois do cadastro.
+func validatePatronWebhookUrl(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Scheme != "https" {
+		return fmt.Errorf("webhook url must be an absolute https url")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return webhook.CheckPublicHost(ctx, u.Hostname())
+}
+
+func (nu *notificationUseCase) GetDeliveries(status model.NotificationDeliveryStatus, userId int) (*[]model.NotificationDelivery, error) {
+	switch status {
+	case "", model.DeliveryPending, model.DeliverySent, model.DeliveryFailed:
+	default:
+		return nil, fmt.Errorf("invalid delivery status '%s'", status)
+	}
+	return nu.notificationRepo.GetDeliveries(status, userId)
+}
+
+// NotifyHoldPositions avisa os leitores com reservas pendentes do livro feitas depois de after da sua nova posição na
+// fila. Deve ser chamado quando uma reserva feita em after sai da fila. Uma falha ao avisar um leitor não impede os
+// demais avisos.
+func (nu *notificationUseCase) NotifyHoldPositions(bookId int, after time.Time) error {
+	positions, err := nu.notificationRepo.GetHoldPositions(bookId, after)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range *positions {
+		data := notification.Data{BookTitle: p.BookTitle, Position: p.Position}
+		dedupeKey := "hold_position:" + strconv.Itoa(p.ReservationId) + ":" + strconv.Itoa(p.Position)
+		if err := nu.Notify(p.UserId, model.EventHoldPositionChanged, data, dedupeKey); err != nil {
+			log.Printf("error notifying hold position to user %d: %v", p.UserId, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (nu *notificationUseCase) GetInbox(userId int, unreadOnly bool) (*[]model.Notification, error) {
+	return nu.notificationRepo.GetNotifications(userId, unreadOnly)
+}
+
+func (nu *notificationUseCase) CountUnread(userId int) (int, error) {
+	return nu.notificationRepo.CountUnreadNotifications(userId)
+}
+
+func (nu *notificationUseCase) MarkRead(id, userId int) error {
+	return nu.notificationRepo.MarkNotificationRead(id, userId)
+}
+
+func (nu *notificationUseCase) MarkAllRead(userId int) (int, error) {
+	return nu.notificationRepo.MarkAllNotificationsRead(userId)
+}
+
+// Subscribe assina as novas mensagens da caixa de entrada do usuário. A função retornada deve ser chamada quando a
+// conexão for encerrada.
+func (nu *notificationUseCase) Subscribe(userId int) (<-chan model.Notification, func()) {
+	return nu.hub.Subscribe(userId)
+}
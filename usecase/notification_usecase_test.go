@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model"
+	"go-api/model/user"
+	"go-api/notification"
+	"go-api/repository"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reminderNotificationRepository é um NotificationRepository em memória com os lembretes e as entregas da fila de
+// envio. Os métodos não usados pelos testes vêm da interface embutida e entram em pânico se chamados.
+type reminderNotificationRepository struct {
+	repository.NotificationRepository
+	mu          sync.Mutex
+	reminders   []model.NotificationReminder
+	toRetry     []model.NotificationDelivery
+	created     []int
+	sent        []int
+	failedSends map[int]bool
+}
+
+func (r *reminderNotificationRepository) GetPreferences(userId int) (*model.NotificationPreferences, error) {
+	return &model.NotificationPreferences{Locale: "pt-BR", InboxEnabled: true}, nil
+}
+
+func (r *reminderNotificationRepository) GetLoanDueReminders(days int) (*[]model.NotificationReminder, error) {
+	return &r.reminders, nil
+}
+
+func (r *reminderNotificationRepository) GetReservationExpiringReminders(hours int) (*[]model.NotificationReminder, error) {
+	return &[]model.NotificationReminder{}, nil
+}
+
+func (r *reminderNotificationRepository) CreateDelivery(delivery model.NotificationDelivery, dedupeKey string, lease time.Duration) (*int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, delivery.UserId)
+	id := len(r.created)
+	return &id, nil
+}
+
+func (r *reminderNotificationRepository) ClaimDeliveriesToRetry(lease time.Duration) (*[]model.NotificationDelivery, error) {
+	return &r.toRetry, nil
+}
+
+func (r *reminderNotificationRepository) MarkDeliverySent(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failedSends[id] {
+		return fmt.Errorf("error updating notification delivery %d", id)
+	}
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+func (r *reminderNotificationRepository) MarkDeliveryFailed(id int, deliveryErr string, maxAttempts int) error {
+	return nil
+}
+
+// brokenUserRepository falha ao buscar os usuários em broken.
+type brokenUserRepository struct {
+	repository.UserRepository
+	broken map[int]bool
+}
+
+func (r *brokenUserRepository) GetUserById(id int) (*user.Account, error) {
+	if r.broken[id] {
+		return nil, fmt.Errorf("error getting user %d", id)
+	}
+	return &user.Account{Id: id, Name: "Leitor", IsActive: true}, nil
+}
+
+type discardChannel struct{}
+
+func (discardChannel) Send(msg notification.Message) error { return nil }
+
+func TestSendRemindersContinuesAfterUserError(t *testing.T) {
+	notificationRepo := &reminderNotificationRepository{}
+	for _, userId := range []int{1, 2, 3, 4} {
+		notificationRepo.reminders = append(notificationRepo.reminders, model.NotificationReminder{
+			Event:     model.EventLoanDue,
+			UserId:    userId,
+			BookTitle: "Dom Casmurro",
+			Due:       time.Now().Add(24 * time.Hour),
+			DedupeKey: fmt.Sprintf("loan_due:%d", userId),
+		})
+	}
+	userRepo := &brokenUserRepository{broken: map[int]bool{2: true, 3: true}}
+	channels := map[model.NotificationChannel]notification.Channel{model.ChannelInbox: discardChannel{}}
+
+	err := NewNotificationUseCase(notificationRepo, userRepo, channels, nil, 3).SendReminders()
+	if err == nil {
+		t.Fatalf("SendReminders() error = nil, want the errors of users 2 and 3")
+	}
+	for _, want := range []string{"error getting user 2", "error getting user 3"} {
+		if !containsLine(err.Error(), want) {
+			t.Errorf("SendReminders() error = %q, want it to include %q", err, want)
+		}
+	}
+
+	notificationRepo.mu.Lock()
+	defer notificationRepo.mu.Unlock()
+	if fmt.Sprint(notificationRepo.created) != "[1 4]" {
+		t.Errorf("deliveries created for users %v, want [1 4]", notificationRepo.created)
+	}
+}
+
+func TestRetryDeliveriesContinuesAfterError(t *testing.T) {
+	notificationRepo := &reminderNotificationRepository{failedSends: map[int]bool{1: true}}
+	for _, id := range []int{1, 2, 3} {
+		notificationRepo.toRetry = append(notificationRepo.toRetry, model.NotificationDelivery{
+			Id: id, Channel: model.ChannelInbox, UserId: id, Status: model.DeliveryPending,
+		})
+	}
+	channels := map[model.NotificationChannel]notification.Channel{model.ChannelInbox: discardChannel{}}
+
+	err := NewNotificationUseCase(notificationRepo, &brokenUserRepository{}, channels, nil, 3).RetryDeliveries()
+	if err == nil || !containsLine(err.Error(), "error updating notification delivery 1") {
+		t.Fatalf("RetryDeliveries() error = %v, want the error of delivery 1", err)
+	}
+	if fmt.Sprint(notificationRepo.sent) != "[2 3]" {
+		t.Errorf("deliveries sent = %v, want [2 3]", notificationRepo.sent)
+	}
+}
+
+// containsLine informa se o erro combinado por errors.Join tem uma linha igual a want.
+func containsLine(joined, want string) bool {
+	return slices.Contains(strings.Split(joined, "\n"), want)
+}
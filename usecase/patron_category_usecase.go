@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model/user"
+	"go-api/repository"
+	"strings"
+)
+
+type PatronCategoryUseCase interface {
+	GetPatronCategories() (*[]user.PatronCategory, error)
+	CreatePatronCategory(category user.PatronCategory) (*user.PatronCategory, error)
+	UpdatePatronCategory(category user.PatronCategory) error
+	DeletePatronCategory(id int) error
+}
+
+type patronCategoryUseCase struct {
+	patronCategoryRepo repository.PatronCategoryRepository
+}
+
+func NewPatronCategoryUseCase(patronCategoryRepo repository.PatronCategoryRepository) PatronCategoryUseCase {
+	return &patronCategoryUseCase{patronCategoryRepo: patronCategoryRepo}
+}
+
+func (pu *patronCategoryUseCase) GetPatronCategories() (*[]user.PatronCategory, error) {
+	return pu.patronCategoryRepo.GetPatronCategories()
+}
+
+func (pu *patronCategoryUseCase) CreatePatronCategory(category user.PatronCategory) (*user.PatronCategory, error) {
+	if err := validatePatronCategory(&category); err != nil {
+		return nil, err
+	}
+	return pu.patronCategoryRepo.CreatePatronCategory(category)
+}
+
+// UpdatePatronCategory altera a política de uma categoria. A categoria padrão não pode ser renomeada, pois é usada
+// no cadastro de contas sem categoria informada.
+func (pu *patronCategoryUseCase) UpdatePatronCategory(category user.PatronCategory) error {
+	current, err := pu.patronCategoryRepo.GetPatronCategoryById(category.Id)
+	if err != nil {
+		return err
+	}
+
+	if err := validatePatronCategory(&category); err != nil {
+		return err
+	}
+	if current.Name == user.DefaultPatronCategory && category.Name != current.Name {
+		return fmt.Errorf("cannot rename default patron category '%s'", current.Name)
+	}
+
+	return pu.patronCategoryRepo.UpdatePatronCategory(category)
+}
+
+func (pu *patronCategoryUseCase) DeletePatronCategory(id int) error {
+	category, err := pu.patronCategoryRepo.GetPatronCategoryById(id)
+	if err != nil {
+		return err
+	}
+	if category.Name == user.DefaultPatronCategory {
+		return fmt.Errorf("cannot delete default patron category '%s'", category.Name)
+	}
+	return pu.patronCategoryRepo.DeletePatronCategory(id)
+}
+
+func validatePatronCategory(category *user.PatronCategory) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return fmt.Errorf("patron category name cannot be empty")
+	}
+	if category.MaxItems <= 0 {
+		return fmt.Errorf("max items must be greater than zero")
+	}
+	if len(category.LoanDays) == 0 {
+		return fmt.Errorf("patron category must allow at least one loan length")
+	}
+	for _, days := range category.LoanDays {
+		if days <= 0 {
+			return fmt.Errorf("loan days must be greater than zero")
+		}
+	}
+	if category.MaxRenewals < 0 {
+		return fmt.Errorf("max renewals cannot be negative")
+	}
+	if category.DailyFineRate < 0 {
+		return fmt.Errorf("daily fine rate cannot be negative")
+	}
+	return nil
+}
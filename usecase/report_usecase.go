@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model"
+	"go-api/repository"
+)
+
+type ReportUseCase interface {
+	GetUnusedCopies(months int) (*[]model.UnusedCopyReport, error)
+	GetUnusedTitles(months int) (*[]model.UnusedTitleReport, error)
+	GetTitleCirculation(months, limit int, order string) (*[]model.TitleCirculationReport, error)
+	GetGenreCirculation(months, limit int, order string) (*[]model.GenreCirculationReport, error)
+	GetDamagedCopies(minReports int) (*[]model.DamagedCopyReport, error)
+	GetWaitlistPressure(minRatio float64) (*[]model.WaitlistReport, error)
+	GetExpiringMemberships(days int) (*[]model.ExpiringMembershipReport, error)
+}
+
+type reportUseCase struct {
+	reportRepo repository.ReportRepository
+}
+
+func NewReportUseCase(reportRepo repository.ReportRepository) ReportUseCase {
+	return &reportUseCase{reportRepo: reportRepo}
+}
+
+func (ru *reportUseCase) GetUnusedCopies(months int) (*[]model.UnusedCopyReport, error) {
+	if months <= 0 {
+		return nil, fmt.Errorf("months must be greater than zero")
+	}
+	return ru.reportRepo.GetUnusedCopies(months)
+}
+
+func (ru *reportUseCase) GetUnusedTitles(months int) (*[]model.UnusedTitleReport, error) {
+	if months <= 0 {
+		return nil, fmt.Errorf("months must be greater than zero")
+	}
+	return ru.reportRepo.GetUnusedTitles(months)
+}
+
+// GetTitleCirculation lista os livros mais ("most") ou menos ("least") emprestados. Com months = 0 todo o
+// histórico é considerado.
+func (ru *reportUseCase) GetTitleCirculation(months, limit int, order string) (*[]model.TitleCirculationReport, error) {
+	ascending, err := validateCirculationParams(months, limit, order)
+	if err != nil {
+		return nil, err
+	}
+	return ru.reportRepo.GetTitleCirculation(months, limit, ascending)
+}
+
+func (ru *reportUseCase) GetGenreCirculation(months, limit int, order string) (*[]model.GenreCirculationReport, error) {
+	ascending, err := validateCirculationParams(months, limit, order)
+	if err != nil {
+		return nil, err
+	}
+	return ru.reportRepo.GetGenreCirculation(months, limit, ascending)
+}
+
+func (ru *reportUseCase) GetDamagedCopies(minReports int) (*[]model.DamagedCopyReport, error) {
+	if minReports <= 0 {
+		return nil, fmt.Errorf("min_reports must be greater than zero")
+	}
+	return ru.reportRepo.GetDamagedCopies(minReports)
+}
+
+func (ru *reportUseCase) GetWaitlistPressure(minRatio float64) (*[]model.WaitlistReport, error) {
+	if minRatio < 0 {
+		return nil, fmt.Errorf("min_ratio cannot be negative")
+	}
+	return ru.reportRepo.GetWaitlistPressure(minRatio)
+}
+
+// GetExpiringMemberships lista os usuários cuja carteirinha vence nos próximos days dias, incluindo hoje.
+func (ru *reportUseCase) GetExpiringMemberships(days int) (*[]model.ExpiringMembershipReport, error) {
+	if days < 0 {
+		return nil, fmt.Errorf("days cannot be negative")
+	}
+	return ru.reportRepo.GetExpiringMemberships(days)
+}
+
+func validateCirculationParams(months, limit int, order string) (bool, error) {
+	if months < 0 {
+		return false, fmt.Errorf("months cannot be negative")
+	}
+	if limit <= 0 {
+		return false, fmt.Errorf("limit must be greater than zero")
+	}
+	switch order {
+	case "most":
+		return false, nil
+	case "least":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid order '%s'. Valid values: 'most', 'least'", order)
+	}
+}
@@ -3,12 +3,14 @@ package usecase
 import (
 	"fmt"
 	"go-api/model"
+	"go-api/notification"
 	"go-api/repository"
-	"time"
+	"log"
+	"strconv"
 )
 
 type ReservationUseCase interface {
-	CreateReservation(borrowedDays, userId, bookId int) (*model.Reservation, error)
+	CreateReservation(borrowedDays, userId, bookId int, adminId *int) (*model.Reservation, error)
 	GetReservationsByFilters(userName string, status model.ReservationStatus, reservedAt string) (*[]model.Reservation, error)
 	GetReservationById(id int) (*model.Reservation, error)
 }
@@ -17,78 +19,66 @@ type reservationUseCase struct {
 	reservationRepo repository.ReservationRepository
 	userRepo        repository.UserRepository
 	bookRepo        repository.BookRepository
+	policy          *circulationPolicy
+	notifier        NotificationUseCase
 }
 
 // NewReservationUseCase cria e retorna uma nova instância de ReservationUseCase
 func NewReservationUseCase(reservationRepo repository.ReservationRepository,
 	userRepo repository.UserRepository,
-	bookRepo repository.BookRepository) ReservationUseCase {
+	bookRepo repository.BookRepository,
+	policyRepo repository.CirculationPolicyRepository,
+	notifier NotificationUseCase) ReservationUseCase {
 	return &reservationUseCase{
 		reservationRepo: reservationRepo,
 		userRepo:        userRepo,
-		bookRepo:        bookRepo}
+		bookRepo:        bookRepo,
+		policy:          newCirculationPolicy(policyRepo, userRepo, reservationRepo),
+		notifier:        notifier}
 }
 
 func (ru *reservationUseCase) GetReservationsByFilters(userName string, status model.ReservationStatus, reservedAt string) (*[]model.Reservation, error) {
 	return ru.reservationRepo.GetReservationsByFilters(userName, status, reservedAt)
 }
 
-func (ru *reservationUseCase) CreateReservation(borrowedDays, userId, bookId int) (*model.Reservation, error) {
-	user, err := ru.userRepo.GetUserById(userId)
-	if err != nil {
-		return nil, fmt.Errorf("error when searching for user: %w", err)
-	}
-	if user.IsActive != true {
-		return nil, fmt.Errorf("user is not active")
+// CreateReservation reserva um livro para o usuário se as regras de circulação permitirem e houver exemplar
+// disponível. adminId é informado quando um funcionário reserva em nome do leitor.
+func (ru *reservationUseCase) CreateReservation(borrowedDays, userId, bookId int, adminId *int) (*model.Reservation, error) {
+	if err := ru.policy.CheckReservation(userId, borrowedDays); err != nil {
+		return nil, err
 	}
 
-	if borrowedDays != 30 && borrowedDays != 60 && borrowedDays != 90 {
-		return nil, fmt.Errorf("borrowed days must be 30, 60, or 90")
-	}
-
-	activeLoans, err := ru.userRepo.GetUserLoans(userId)
+	bUseCase := NewBookUseCase(ru.bookRepo, ru.reservationRepo)
+	amount, err := bUseCase.CountAvailableBookStockById(bookId)
 	if err != nil {
-		return nil, fmt.Errorf("error when searching for user loans: %w", err)
+		return nil, fmt.Errorf("error when getting book stock amount: %w", err)
 	}
-
-	borrowedLoansCount := 0
-	for _, loan := range *activeLoans {
-		if loan.Status == "borrowed" {
-			borrowedLoansCount++
-			// Verificar se o empréstimo está em atraso
-			if loan.ReturnBy.Before(time.Now()) {
-				return nil, fmt.Errorf("user has overdue loans")
-			}
-		}
+	if amount <= 0 {
+		return nil, fmt.Errorf("book out of stock")
 	}
 
-	activeReservations, err := ru.userRepo.GetUserReservations(userId)
+	reservation, err := ru.reservationRepo.CreateReservation(borrowedDays, userId, bookId, adminId)
 	if err != nil {
-		return nil, fmt.Errorf("error when searching for user reservations: %w", err)
+		return nil, err
 	}
 
-	pendingReservationsCount := 0
-	for _, res := range *activeReservations {
-		if res.Status == model.ReservationPending {
-			pendingReservationsCount++
-		}
-	}
-
-	totalActive := pendingReservationsCount + borrowedLoansCount
-	if totalActive >= 5 {
-		return nil, fmt.Errorf("user already has 5 or more active reservations/loans")
+	// A reserva já foi criada; uma falha ao avisar o leitor não deve desfazê-la
+	if err := ru.notifyReservationReady(userId, bookId, reservation); err != nil {
+		log.Printf("error notifying reservation %d: %v", reservation.Id, err)
 	}
+	return reservation, nil
+}
 
-	bUseCase := NewBookUseCase(ru.bookRepo, ru.reservationRepo)
-	amount, err := bUseCase.CountAvailableBookStockById(bookId)
+// notifyReservationReady avisa o leitor que o exemplar está separado e até quando pode ser retirado.
+func (ru *reservationUseCase) notifyReservationReady(userId, bookId int, reservation *model.Reservation) error {
+	book, err := ru.bookRepo.GetBookById(bookId)
 	if err != nil {
-		return nil, fmt.Errorf("error when getting book stock amount: %w", err)
-	}
-	if amount <= 0 {
-		return nil, fmt.Errorf("book out of stock")
+		return err
 	}
 
-	return ru.reservationRepo.CreateReservation(borrowedDays, userId, bookId)
+	data := notification.Data{BookTitle: book.Title, ExpiresAt: reservation.ExpiresAt}
+	return ru.notifier.Notify(userId, model.EventReservationReady, data,
+		"reservation_ready:"+strconv.Itoa(reservation.Id))
 }
 
 func (ru *reservationUseCase) GetReservationById(id int) (*model.Reservation, error) {
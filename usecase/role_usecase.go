@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"fmt"
+	"go-api/model/user"
+	"go-api/repository"
+	"slices"
+	"strings"
+)
+
+type RoleUseCase interface {
+	GetRoles() (*[]user.AccountRole, error)
+	GetPermissions() (*[]user.Permission, error)
+	CreateRole(name string, requiresTwoFactor bool, permissions []string) (*user.AccountRole, error)
+	UpdateRole(id int, name string, requiresTwoFactor bool) error
+	SetRolePermissions(id int, permissions []string) error
+	DeleteRole(id int) error
+}
+
+type roleUseCase struct {
+	roleRepo repository.RoleRepository
+}
+
+func NewRoleUseCase(roleRepo repository.RoleRepository) RoleUseCase {
+	return &roleUseCase{roleRepo: roleRepo}
+}
+
+func (ru *roleUseCase) GetRoles() (*[]user.AccountRole, error) {
+	return ru.roleRepo.GetRoles()
+}
+
+func (ru *roleUseCase) GetPermissions() (*[]user.Permission, error) {
+	return ru.roleRepo.GetPermissions()
+}
+
+func (ru *roleUseCase) CreateRole(name string, requiresTwoFactor bool, permissions []string) (*user.AccountRole, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("role name cannot be empty")
+	}
+	return ru.roleRepo.CreateRole(name, requiresTwoFactor, permissions)
+}
+
+// UpdateRole altera o nome e a exigência de 2FA da role. As roles 'admin' e 'user' não podem ser renomeadas, pois
+// são usadas pelo sistema (ex.: o cadastro de leitores usa a role 'user').
+func (ru *roleUseCase) UpdateRole(id int, name string, requiresTwoFactor bool) error {
+	role, err := ru.roleRepo.GetRoleById(id)
+	if err != nil {
+		return err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("role name cannot be empty")
+	}
+	if isBuiltInRole(role.Name) && name != role.Name {
+		return fmt.Errorf("cannot rename built-in role '%s'", role.Name)
+	}
+
+	return ru.roleRepo.UpdateRole(id, name, requiresTwoFactor)
+}
+
+// SetRolePermissions substitui as permissões da role. A role 'admin' sempre mantém a permissão de gerenciar roles,
+// para que ninguém perca o acesso a esta configuração.
+func (ru *roleUseCase) SetRolePermissions(id int, permissions []string) error {
+	role, err := ru.roleRepo.GetRoleById(id)
+	if err != nil {
+		return err
+	}
+
+	if role.Name == user.RoleAdmin && !slices.Contains(permissions, user.PermRolesManage) {
+		return fmt.Errorf("the '%s' role must keep the '%s' permission", user.RoleAdmin, user.PermRolesManage)
+	}
+
+	return ru.roleRepo.SetRolePermissions(id, permissions)
+}
+
+func (ru *roleUseCase) DeleteRole(id int) error {
+	role, err := ru.roleRepo.GetRoleById(id)
+	if err != nil {
+		return err
+	}
+	if isBuiltInRole(role.Name) {
+		return fmt.Errorf("cannot delete built-in role '%s'", role.Name)
+	}
+	return ru.roleRepo.DeleteRole(id)
+}
+
+func isBuiltInRole(name string) bool {
+	return name == user.RoleAdmin || name == user.RoleUser
+}
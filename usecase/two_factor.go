@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"go-api/model/user"
+	"go-api/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// totpIssuer é o nome exibido nos aplicativos autenticadores.
+	totpIssuer = "Biblioteca"
+	// loginChallengeTTL é o tempo que o usuário tem para informar o segundo fator após a senha.
+	loginChallengeTTL = 5 * time.Minute
+	// recoveryCodeCount é a quantidade de códigos de recuperação gerados ao ativar o 2FA.
+	recoveryCodeCount = 10
+)
+
+// ErrInvalidTwoFactorCode é retornado quando o código TOTP ou de recuperação não confere.
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+// twoFactorChallenge retorna o desafio de segundo fator se o usuário tiver 2FA ativo ou se a sua role exigir, ou
+// nil se o login pode prosseguir apenas com a senha.
+func (uu *userUseCase) twoFactorChallenge(userAccount *user.Account) (*user.LoginResult, error) {
+	totp, err := uu.twoFactorRepo.GetTOTP(userAccount.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolled := totp != nil && totp.ConfirmedAt != nil
+	if !enrolled && !userAccount.AccountRole.RequiresTwoFactor {
+		return nil, nil
+	}
+
+	token, err := utils.GeneratePurposeJWT(utils.PurposeLoginTwoFactor, userAccount.Id, userAccount.Email, loginChallengeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("error generating two-factor challenge: %v", err)
+	}
+	return &user.LoginResult{TwoFactorRequired: true, EnrollmentRequired: !enrolled, ChallengeToken: token}, nil
+}
+
+// challengeAccount valida o desafio emitido no login e retorna o usuário a que ele pertence.
+func (uu *userUseCase) challengeAccount(challengeToken string) (*user.Account, error) {
+	userId, email, err := utils.ValidatePurposeJWT(utils.PurposeLoginTwoFactor, challengeToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired two-factor challenge")
+	}
+
+	userAccount, err := uu.userRepo.GetUserById(userId)
+	if err != nil || userAccount.Email != email || !userAccount.IsActive {
+		return nil, errors.New("invalid or expired two-factor challenge")
+	}
+	return userAccount, nil
+}
+
+// VerifyLoginTwoFactor conclui o login com o código TOTP ou com um código de recuperação. Se o autenticador foi
+// cadastrado durante este login (EnrollLoginTwoFactor), o código o confirma e os códigos de recuperação são
+// retornados junto com os tokens. Códigos incorretos contam como falhas de login.
+func (uu *userUseCase) VerifyLoginTwoFactor(challengeToken, code, recoveryCode, ipAddress, userAgent string) (*user.LoginResult, error) {
+	userAccount, err := uu.challengeAccount(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uu.checkLoginLock(userAccount.Email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	totp, err := uu.twoFactorRepo.GetTOTP(userAccount.Id)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil {
+		return nil, errors.New("two-factor enrollment has not been started")
+	}
+
+	var recoveryCodes []string
+	var valid bool
+	switch {
+	case totp.ConfirmedAt == nil:
+		step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+		if ok {
+			valid = true
+			if recoveryCodes, err = uu.confirmTOTP(userAccount.Id, step); err != nil {
+				return nil, err
+			}
+		}
+	case recoveryCode != "":
+		valid, err = uu.twoFactorRepo.UseRecoveryCode(userAccount.Id, hashRecoveryCode(recoveryCode))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+		if ok {
+			// Impede que o mesmo código seja usado duas vezes
+			if valid, err = uu.twoFactorRepo.UseTOTPStep(userAccount.Id, step); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !valid {
+		err := uu.recordLoginFailure(userAccount.Email, ipAddress)
+		if errors.Is(err, ErrInvalidCredentials) {
+			return nil, ErrInvalidTwoFactorCode
+		}
+		return nil, err
+	}
+
+	if err := uu.lockoutRepo.Clear(user.LockoutAccount, normalizeEmail(userAccount.Email)); err != nil {
+		return nil, err
+	}
+	tokens, err := uu.startSession(userAccount, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	return &user.LoginResult{AuthTokens: tokens, RecoveryCodes: recoveryCodes}, nil
+}
+
+// EnrollLoginTwoFactor inicia o cadastro do autenticador durante o login, para usuários cuja role exige 2FA e que
+// ainda não o ativaram.
+func (uu *userUseCase) EnrollLoginTwoFactor(challengeToken string) (*user.TOTPEnrollment, error) {
+	userAccount, err := uu.challengeAccount(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return uu.enrollTOTP(userAccount)
+}
+
+// EnrollTwoFactor inicia o cadastro do autenticador do usuário logado. O 2FA só é ativado após ConfirmTwoFactor.
+func (uu *userUseCase) EnrollTwoFactor(id int) (*user.TOTPEnrollment, error) {
+	userAccount, err := uu.userRepo.GetUserById(id)
+	if err != nil {
+		return nil, err
+	}
+	return uu.enrollTOTP(userAccount)
+}
+
+func (uu *userUseCase) enrollTOTP(userAccount *user.Account) (*user.TOTPEnrollment, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("error generating totp secret: %v", err)
+	}
+	if err := uu.twoFactorRepo.SaveTOTPSecret(userAccount.Id, secret); err != nil {
+		return nil, err
+	}
+	return &user.TOTPEnrollment{Secret: secret, OtpauthURI: utils.TOTPURI(totpIssuer, userAccount.Email, secret)}, nil
+}
+
+// ConfirmTwoFactor ativa o 2FA do usuário logado com o primeiro código do autenticador e retorna os códigos de
+// recuperação, que não podem ser consultados novamente.
+func (uu *userUseCase) ConfirmTwoFactor(id int, code string) ([]string, error) {
+	totp, err := uu.twoFactorRepo.GetTOTP(id)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil || totp.ConfirmedAt != nil {
+		return nil, errors.New("no pending two-factor enrollment found")
+	}
+
+	step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	return uu.confirmTOTP(id, step)
+}
+
+func (uu *userUseCase) confirmTOTP(userId int, step int64) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, fmt.Errorf("error generating recovery codes: %v", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	if err := uu.twoFactorRepo.ConfirmTOTP(userId, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor desativa o 2FA do usuário logado após conferir a senha. Não é permitido se a role exigir 2FA.
+func (uu *userUseCase) DisableTwoFactor(id int, password string) error {
+	userAccount, err := uu.userRepo.GetUserById(id)
+	if err != nil {
+		return err
+	}
+	if userAccount.AccountRole.RequiresTwoFactor {
+		return fmt.Errorf("two-factor authentication is required for role '%s'", userAccount.AccountRole.Name)
+	}
+
+	passwordHash, err := uu.userRepo.GetUserPasswordHash(id)
+	if err != nil {
+		return err
+	}
+	if !utils.CheckPasswordHash(password, passwordHash) {
+		return errors.New("password is incorrect")
+	}
+
+	return uu.twoFactorRepo.DeleteTOTP(id)
+}
+
+// ResetTwoFactor remove o autenticador de um usuário (ex.: aparelho perdido) e encerra as suas sessões. Se a role
+// exigir 2FA, o usuário cadastra um novo autenticador no próximo login.
+func (uu *userUseCase) ResetTwoFactor(id int) error {
+	if _, err := uu.userRepo.GetUserById(id); err != nil {
+		return err
+	}
+	if err := uu.twoFactorRepo.DeleteTOTP(id); err != nil {
+		return err
+	}
+	return uu.sessionRepo.RevokeUserSessions(id)
+}
+
+func hashRecoveryCode(code string) string {
+	return utils.HashToken(strings.ToLower(strings.TrimSpace(code)))
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"errors"
+	"go-api/model"
+	"go-api/model/user"
+	"time"
+)
+
+// ExportUserData reúne tudo o que é mantido sobre o usuário: conta, reservas, empréstimos, multas, sessões,
+// sugestões de compra e notificações. O cpf é exportado completo, já que o pacote é entregue ao próprio titular.
+func (uu *userUseCase) ExportUserData(id int) (*model.UserDataExport, error) {
+	userAccount, err := uu.userRepo.GetUserById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := uu.userRepo.GetUserReservations(id)
+	if err != nil {
+		return nil, err
+	}
+
+	loans, err := uu.userRepo.GetUserLoans(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fines, err := uu.userRepo.GetUserFines(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := uu.sessionRepo.GetUserSessions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, err := uu.acquisitionRepo.GetSuggestions("", &id)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := uu.notifier.GetPreferences(id)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := uu.notifier.GetInbox(id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := uu.notifier.GetDeliveries("", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserDataExport{
+		GeneratedAt:             time.Now(),
+		Account:                 user.NewAccountResponse(userAccount, true),
+		Reservations:            *reservations,
+		Loans:                   *loans,
+		Fines:                   *fines,
+		Sessions:                *sessions,
+		PurchaseSuggestions:     *suggestions,
+		NotificationPreferences: preferences,
+		Notifications:           *notifications,
+		NotificationDeliveries:  *deliveries,
+	}, nil
+}
+
+// AnonymizeUser apaga os dados pessoais do usuário e encerra o acesso à conta, preservando o histórico de
+// circulação de forma anônima.
+func (uu *userUseCase) AnonymizeUser(id int) error {
+	userAccount, err := uu.userRepo.GetUserById(id)
+	if err != nil {
+		return err
+	}
+	if userAccount.AnonymizedAt != nil {
+		return errors.New("user has already been anonymized")
+	}
+
+	return uu.userRepo.AnonymizeUser(id)
+}
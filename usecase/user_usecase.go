@@ -3,50 +3,312 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"go-api/initializers"
+	"go-api/mailer"
 	"go-api/model"
 	"go-api/model/user"
 	"go-api/repository"
 	"go-api/utils"
+	"log"
+	"net/url"
+	"time"
 )
 
 type UserUseCase interface {
-	Login(email, password string) (string, error)
-	Register(name, cpf, phone, email, passwordHash string, fkAccountRole int) (*int, error)
+	Login(email, password, ipAddress, userAgent string) (*user.LoginResult, error)
+	VerifyLoginTwoFactor(challengeToken, code, recoveryCode, ipAddress, userAgent string) (*user.LoginResult, error)
+	EnrollLoginTwoFactor(challengeToken string) (*user.TOTPEnrollment, error)
+	EnrollTwoFactor(id int) (*user.TOTPEnrollment, error)
+	ConfirmTwoFactor(id int, code string) ([]string, error)
+	DisableTwoFactor(id int, password string) error
+	ResetTwoFactor(id int) error
+	RefreshSession(refreshToken string) (*user.AuthTokens, error)
+	Logout(id, sessionId int) error
+	LogoutAll(id int) error
+	Register(name, cpf, phone, email, passwordHash string, fkAccountRole int, patronCategoryId *int) (*int, error)
+	SignUp(name, cpf, phone, email, passwordHash string) (*int, error)
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+	ChangePassword(id, sessionId int, currentPassword, newPassword string) error
+	ForgotPassword(email string) error
+	ResetPassword(token, newPassword string) error
 	GetUsersByFilters(name, email string) (*[]user.Account, error)
 	GetUserById(id int) (*user.Account, error)
+	UpdateProfile(id int, name, phone, email string) (*user.Account, error)
 	GetUserLoans(id int) (*[]model.Loan, error)
 	ActivateUser(id int) error
 	DeactivateUser(id int) error
 	DeleteUser(id int) error
 	GetUserReservations(id int) (*[]model.Reservation, error)
 	CancelUserReservation(id, reservationId int, adminId *int) error
+	GetUserFines(id int) (*[]model.Fine, error)
+	GetLoginLockouts(scope user.LoginLockoutScope, lockedOnly bool) (*[]user.LoginLockout, error)
+	ClearLoginLockout(id int) error
+	ExportUserData(id int) (*model.UserDataExport, error)
+	AnonymizeUser(id int) error
+	RenewMembership(id int) (*user.Account, error)
+	SetPatronCategory(id, patronCategoryId int) (*user.Account, error)
 }
 
+const (
+	// emailVerificationTTL é o tempo de validade do link de verificação de email.
+	emailVerificationTTL = 24 * time.Hour
+	// passwordResetTTL é o tempo de validade do token de redefinição de senha.
+	passwordResetTTL = time.Hour
+	// refreshTokenTTL é o tempo máximo de uma sessão, contado a partir do login.
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// membershipRenewalMonths é quanto a validade da carteirinha é estendida a cada renovação.
+	membershipRenewalMonths = 12
+)
+
 type userUseCase struct {
-	userRepo repository.UserRepository
+	userRepo        repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	lockoutRepo     repository.LoginLockoutRepository
+	twoFactorRepo   repository.TwoFactorRepository
+	acquisitionRepo repository.AcquisitionRepository
+	mailer          mailer.Mailer
+	notifier        NotificationUseCase
 }
 
-func NewUserUseCase(repo repository.UserRepository) UserUseCase {
-	return &userUseCase{userRepo: repo}
+func NewUserUseCase(repo repository.UserRepository, sessionRepo repository.SessionRepository,
+	lockoutRepo repository.LoginLockoutRepository, twoFactorRepo repository.TwoFactorRepository,
+	acquisitionRepo repository.AcquisitionRepository, userMailer mailer.Mailer,
+	notifier NotificationUseCase) UserUseCase {
+	return &userUseCase{
+		userRepo:        repo,
+		sessionRepo:     sessionRepo,
+		lockoutRepo:     lockoutRepo,
+		twoFactorRepo:   twoFactorRepo,
+		acquisitionRepo: acquisitionRepo,
+		mailer:          userMailer,
+		notifier:        notifier,
+	}
 }
 
-// Login busca o usuário pelo email, verifica a senha hashed e retorna um token JWT.
-func (uu *userUseCase) Login(email, password string) (string, error) {
-	// Busca o usuário pelo seu email
+// Login busca o usuário pelo email, verifica a senha hashed, abre uma nova sessão e retorna os tokens de acesso e
+// de atualização. Falhas seguidas bloqueiam temporariamente a conta e o IP (ver recordLoginFailure). Se o usuário
+// tiver 2FA ativo, ou se a sua role exigir, os tokens só são emitidos em VerifyLoginTwoFactor.
+func (uu *userUseCase) Login(email, password, ipAddress, userAgent string) (*user.LoginResult, error) {
+	if err := uu.checkLoginLock(email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// Busca o usuário pelo seu email. Emails desconhecidos e senhas incorretas têm a mesma resposta
 	userAccount, err := uu.userRepo.GetUserByEmail(email)
 	if err != nil {
-		return "", err
+		utils.CheckPasswordHash(password, dummyPasswordHash)
+		return nil, uu.recordLoginFailure(email, ipAddress)
 	}
 	if !utils.CheckPasswordHash(password, userAccount.PasswordHash) {
-		return "", errors.New("invalid credentials")
+		return nil, uu.recordLoginFailure(email, ipAddress)
+	}
+	if userAccount.Status != user.AccountVerified {
+		return nil, errors.New("email address has not been verified")
+	}
+	if !userAccount.IsActive {
+		return nil, errors.New("user account is deactivated")
+	}
+
+	challenge, err := uu.twoFactorChallenge(userAccount)
+	if err != nil || challenge != nil {
+		return challenge, err
 	}
-	// Gera um token JWT com ID e Role se atendido todas as condições
-	return utils.GenerateJWT(userAccount.Id, userAccount.AccountRole.Name)
+
+	if err := uu.lockoutRepo.Clear(user.LockoutAccount, normalizeEmail(email)); err != nil {
+		return nil, err
+	}
+	tokens, err := uu.startSession(userAccount, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	return &user.LoginResult{AuthTokens: tokens}, nil
+}
+
+// startSession abre uma nova sessão para o usuário e emite os tokens.
+func (uu *userUseCase) startSession(userAccount *user.Account, ipAddress, userAgent string) (*user.AuthTokens, error) {
+	refreshToken, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, fmt.Errorf("error generating refresh token: %v", err)
+	}
+	sessionId, err := uu.sessionRepo.CreateSession(userAccount.Id, utils.HashToken(refreshToken), ipAddress, userAgent, refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gera um token JWT com ID, Role e sessão se atendido todas as condições
+	return issueTokens(userAccount.Id, userAccount.AccountRole.Name, sessionId, refreshToken)
+}
+
+// RefreshSession troca o token de atualização por um novo par de tokens. Cada token de atualização só pode ser
+// usado uma vez; reutilizar um token já trocado revoga a sessão.
+func (uu *userUseCase) RefreshSession(refreshToken string) (*user.AuthTokens, error) {
+	newRefreshToken, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, fmt.Errorf("error generating refresh token: %v", err)
+	}
+
+	session, err := uu.sessionRepo.RotateRefreshToken(utils.HashToken(refreshToken), utils.HashToken(newRefreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	userAccount, err := uu.userRepo.GetUserById(session.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return issueTokens(userAccount.Id, userAccount.AccountRole.Name, session.Id, newRefreshToken)
+}
+
+func (uu *userUseCase) Logout(id, sessionId int) error {
+	return uu.sessionRepo.RevokeSession(sessionId, id)
+}
+
+func (uu *userUseCase) LogoutAll(id int) error {
+	return uu.sessionRepo.RevokeUserSessions(id)
+}
+
+func issueTokens(userId int, role string, sessionId int, refreshToken string) (*user.AuthTokens, error) {
+	accessToken, err := utils.GenerateJWT(userId, role, sessionId)
+	if err != nil {
+		return nil, fmt.Errorf("error generating access token: %v", err)
+	}
+	return &user.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+	}, nil
 }
 
 // Register cria um novo usuário no banco de dados.
-func (uu *userUseCase) Register(name, cpf, phone, email, passwordHash string, fkAccountRole int) (*int, error) {
-	return uu.userRepo.CreateUser(name, cpf, phone, email, passwordHash, fkAccountRole)
+// Quando a categoria de leitor não é informada, o usuário é criado na categoria padrão.
+func (uu *userUseCase) Register(name, cpf, phone, email, passwordHash string, fkAccountRole int, patronCategoryId *int) (*int, error) {
+	return uu.userRepo.CreateUser(name, cpf, phone, email, passwordHash, fkAccountRole, patronCategoryId, user.AccountVerified)
+}
+
+// SignUp cria uma conta de leitor pendente de verificação e envia o link de verificação para o email informado. Se o
+// email não puder ser enviado, a conta é removida para que o cadastro possa ser refeito com o mesmo email e CPF.
+func (uu *userUseCase) SignUp(name, cpf, phone, email, passwordHash string) (*int, error) {
+	role, err := uu.userRepo.GetRoleByName(user.RoleUser)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := uu.userRepo.CreateUser(name, cpf, phone, email, passwordHash, role.Id, nil, user.AccountPendingVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uu.sendVerificationEmail(*userId, name, email); err != nil {
+		if deleteErr := uu.userRepo.DeleteUser(*userId); deleteErr != nil {
+			log.Printf("error removing user %d after the verification email failed: %v", *userId, deleteErr)
+		}
+		return nil, err
+	}
+	return userId, nil
+}
+
+// VerifyEmail valida o token enviado por email e marca a conta como verificada. O token deixa de valer se o
+// email da conta mudar depois de emitido.
+func (uu *userUseCase) VerifyEmail(token string) error {
+	userId, email, err := utils.ValidatePurposeJWT(utils.PurposeEmailVerification, token)
+	if err != nil {
+		return errors.New("invalid or expired verification token")
+	}
+
+	userAccount, err := uu.userRepo.GetUserById(userId)
+	if err != nil {
+		return errors.New("invalid or expired verification token")
+	}
+	if userAccount.Email != email {
+		// O link pode ser de uma troca de email solicitada no perfil
+		if userAccount.PendingEmail != nil && *userAccount.PendingEmail == email {
+			return uu.userRepo.ConfirmEmailChange(userId, email)
+		}
+		return errors.New("invalid or expired verification token")
+	}
+	if userAccount.Status == user.AccountVerified {
+		return nil
+	}
+
+	return uu.userRepo.VerifyUser(userId)
+}
+
+// ResendVerification envia um novo link de verificação. Emails desconhecidos ou já verificados são ignorados
+// silenciosamente para não revelar quais contas existem.
+func (uu *userUseCase) ResendVerification(email string) error {
+	userAccount, err := uu.userRepo.GetUserByEmail(email)
+	if err != nil || userAccount.Status != user.AccountPendingVerification {
+		return nil
+	}
+	return uu.sendVerificationEmail(userAccount.Id, userAccount.Name, userAccount.Email)
+}
+
+func (uu *userUseCase) sendVerificationEmail(userId int, name, email string) error {
+	link, err := verificationLink(userId, email)
+	if err != nil {
+		return err
+	}
+
+	return uu.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirme seu email",
+		Body: fmt.Sprintf("Olá, %s!\n\nPara ativar sua conta na biblioteca, acesse o link abaixo em até 24 horas:\n\n%s\n\n"+
+			"Se você não criou esta conta, ignore este email.\n", name, link),
+	})
+}
+
+func (uu *userUseCase) sendEmailChangeEmail(userId int, name, email string) error {
+	link, err := verificationLink(userId, email)
+	if err != nil {
+		return err
+	}
+
+	return uu.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirme seu novo email",
+		Body: fmt.Sprintf("Olá, %s!\n\nPara usar este endereço na sua conta da biblioteca, acesse o link abaixo em até "+
+			"24 horas:\n\n%s\n\nSe você não solicitou esta alteração, ignore este email.\n", name, link),
+	})
+}
+
+// verificationLink gera o link de confirmação de um email, válido apenas enquanto o email for o informado.
+func verificationLink(userId int, email string) (string, error) {
+	token, err := utils.GeneratePurposeJWT(utils.PurposeEmailVerification, userId, email, emailVerificationTTL)
+	if err != nil {
+		return "", fmt.Errorf("error generating verification token: %v", err)
+	}
+	return initializers.AppBaseURL + "/api/v1/verify-email?token=" + url.QueryEscape(token), nil
+}
+
+// UpdateProfile atualiza nome, telefone e email do próprio usuário. O novo email só substitui o atual depois de
+// confirmado pelo link enviado a ele; até lá, o login continua sendo feito com o email atual.
+func (uu *userUseCase) UpdateProfile(id int, name, phone, email string) (*user.Account, error) {
+	userAccount, err := uu.userRepo.GetUserById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var pendingEmail *string
+	if email != userAccount.Email {
+		// Verifica o conflito já na solicitação, sem esperar a confirmação do novo email
+		if other, err := uu.userRepo.GetUserByEmail(email); err == nil && other.Id != id {
+			return nil, &repository.UserConflictError{Field: "email"}
+		}
+		pendingEmail = &email
+	}
+
+	if err := uu.userRepo.UpdateProfile(id, name, phone, pendingEmail); err != nil {
+		return nil, err
+	}
+
+	if pendingEmail != nil {
+		if err := uu.sendEmailChangeEmail(id, name, email); err != nil {
+			return nil, err
+		}
+	}
+
+	return uu.userRepo.GetUserById(id)
 }
 
 func (uu *userUseCase) GetUsersByFilters(name, email string) (*[]user.Account, error) {
@@ -61,6 +323,10 @@ func (uu *userUseCase) GetUserLoans(id int) (*[]model.Loan, error) {
 	return uu.userRepo.GetUserLoans(id)
 }
 
+func (uu *userUseCase) GetUserFines(id int) (*[]model.Fine, error) {
+	return uu.userRepo.GetUserFines(id)
+}
+
 func (uu *userUseCase) GetUserReservations(id int) (*[]model.Reservation, error) {
 	return uu.userRepo.GetUserReservations(id)
 }
@@ -73,7 +339,32 @@ func (uu *userUseCase) DeactivateUser(id int) error {
 	return uu.userRepo.DeactivateUser(id)
 }
 
+// RenewMembership renova a carteirinha do usuário por mais um ano e retorna a conta com a nova validade.
+func (uu *userUseCase) RenewMembership(id int) (*user.Account, error) {
+	if err := uu.userRepo.RenewMembership(id, membershipRenewalMonths); err != nil {
+		return nil, err
+	}
+	return uu.userRepo.GetUserById(id)
+}
+
+// SetPatronCategory altera a categoria de leitor do usuário e retorna a conta com a nova política de empréstimo.
+func (uu *userUseCase) SetPatronCategory(id, patronCategoryId int) (*user.Account, error) {
+	if err := uu.userRepo.SetPatronCategory(id, patronCategoryId); err != nil {
+		return nil, err
+	}
+	return uu.userRepo.GetUserById(id)
+}
+
+// DeleteUser remove a conta do usuário. Contas com histórico de circulação devem ser anonimizadas, para que as
+// reservas, empréstimos e multas não sejam apagados junto.
 func (uu *userUseCase) DeleteUser(id int) error {
+	hasHistory, err := uu.userRepo.HasCirculationHistory(id)
+	if err != nil {
+		return err
+	}
+	if hasHistory {
+		return fmt.Errorf("user with id %d has circulation history and cannot be deleted, anonymize it instead", id)
+	}
 	return uu.userRepo.DeleteUser(id)
 }
 
@@ -87,5 +378,65 @@ func (uu *userUseCase) CancelUserReservation(id, reservationId int, adminId *int
 		return fmt.Errorf("cannot cancel user reservation unless its status is 'pending'. Current status: '%s'", res.Status)
 	}
 
-	return uu.userRepo.CancelUserReservation(id, reservationId, adminId)
+	if err := uu.userRepo.CancelUserReservation(id, reservationId, adminId); err != nil {
+		return err
+	}
+
+	// A reserva saiu da fila, então as reservas seguintes do livro avançam uma posição
+	if err := uu.notifier.NotifyHoldPositions(res.Book.Id, res.ReservedAt); err != nil {
+		log.Printf("error notifying hold positions of book %d: %v", res.Book.Id, err)
+	}
+	return nil
+}
+
+// ChangePassword altera a senha do usuário logado após conferir a senha atual. Todas as outras sessões do usuário
+// são revogadas; a sessão atual continua válida.
+func (uu *userUseCase) ChangePassword(id, sessionId int, currentPassword, newPassword string) error {
+	passwordHash, err := uu.userRepo.GetUserPasswordHash(id)
+	if err != nil {
+		return err
+	}
+	if !utils.CheckPasswordHash(currentPassword, passwordHash) {
+		return errors.New("current password is incorrect")
+	}
+
+	newPasswordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %v", err)
+	}
+	return uu.userRepo.UpdatePassword(id, newPasswordHash, &sessionId)
+}
+
+// ForgotPassword envia um token de redefinição de senha de uso único para o email informado. Emails desconhecidos
+// ou de contas desativadas são ignorados silenciosamente para não revelar quais contas existem.
+func (uu *userUseCase) ForgotPassword(email string) error {
+	userAccount, err := uu.userRepo.GetUserByEmail(email)
+	if err != nil || !userAccount.IsActive {
+		return nil
+	}
+
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		return fmt.Errorf("error generating password reset token: %v", err)
+	}
+	if err := uu.userRepo.CreatePasswordResetToken(userAccount.Id, utils.HashToken(token), passwordResetTTL); err != nil {
+		return err
+	}
+
+	return uu.mailer.Send(mailer.Message{
+		To:      userAccount.Email,
+		Subject: "Redefinição de senha",
+		Body: fmt.Sprintf("Olá, %s!\n\nRecebemos um pedido para redefinir a senha da sua conta na biblioteca. "+
+			"Use o código abaixo em até 1 hora:\n\n%s\n\nSe você não fez este pedido, ignore este email.\n",
+			userAccount.Name, token),
+	})
+}
+
+// ResetPassword redefine a senha usando o token enviado por email. O token só pode ser usado uma vez.
+func (uu *userUseCase) ResetPassword(token, newPassword string) error {
+	passwordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %v", err)
+	}
+	return uu.userRepo.ResetPassword(utils.HashToken(token), passwordHash)
 }
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"errors"
+	"go-api/mailer"
+	"go-api/model/user"
+	"go-api/repository"
+	"testing"
+)
+
+// signUpUserRepository guarda em memória as contas criadas e removidas pelo cadastro.
+type signUpUserRepository struct {
+	repository.UserRepository
+	created []int
+	deleted []int
+}
+
+func (r *signUpUserRepository) GetRoleByName(name string) (*user.AccountRole, error) {
+	return &user.AccountRole{Id: 2, Name: name}, nil
+}
+
+func (r *signUpUserRepository) CreateUser(name, cpf, phone, email, passwordHash string, fkAccountRole int,
+	patronCategoryId *int, status user.AccountStatus) (*int, error) {
+	id := len(r.created) + 1
+	r.created = append(r.created, id)
+	return &id, nil
+}
+
+func (r *signUpUserRepository) DeleteUser(id int) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+type stubMailer struct {
+	err  error
+	sent []mailer.Message
+}
+
+func (m *stubMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return m.err
+}
+
+func TestSignUpRemovesAccountWhenEmailFails(t *testing.T) {
+	tests := []struct {
+		name        string
+		mailErr     error
+		wantErr     bool
+		wantDeleted int
+	}{
+		{"email sent", nil, false, 0},
+		{"email failed", errors.New("smtp: connection refused"), true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &signUpUserRepository{}
+			userMailer := &stubMailer{err: tt.mailErr}
+			userUseCase := NewUserUseCase(userRepo, nil, nil, nil, nil, userMailer, nil)
+
+			userId, err := userUseCase.SignUp("Leitor", "12345678909", "11999999999", "leitor@example.com", "hash")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SignUp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(userMailer.sent) != 1 {
+				t.Fatalf("emails sent = %d, want 1", len(userMailer.sent))
+			}
+			if len(userRepo.deleted) != tt.wantDeleted {
+				t.Errorf("users deleted = %v, want %d", userRepo.deleted, tt.wantDeleted)
+			}
+			if !tt.wantErr && (userId == nil || *userId != 1) {
+				t.Errorf("SignUp() = %v, want user 1", userId)
+			}
+		})
+	}
+}
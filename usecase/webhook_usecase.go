@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/utils"
+	"go-api/webhook"
+	"log"
+	"net/url"
+	"time"
+)
+
+const (
+	// maxWebhookAttempts é o número de tentativas de entrega a um webhook antes de ela ser dada como falha. Com a
+	// espera exponencial, a última tentativa acontece cerca de 4 horas após a primeira.
+	maxWebhookAttempts = 9
+	// webhookLease é por quanto tempo uma tentativa de entrega fica reservada a quem a iniciou.
+	webhookLease = 2 * time.Minute
+)
+
+type WebhookUseCase interface {
+	Publish(event model.OutboxEvent) error
+	GetEndpoints() (*[]model.WebhookEndpoint, error)
+	CreateEndpoint(endpoint model.WebhookEndpoint, adminId int) (*model.WebhookEndpoint, error)
+	UpdateEndpoint(endpoint model.WebhookEndpoint) error
+	DeleteEndpoint(id int) error
+	GetDeliveries(endpointId int, status model.WebhookDeliveryStatus, event model.WebhookEvent) (*[]model.WebhookDelivery, error)
+	ReplayDelivery(id int) (*model.WebhookDelivery, error)
+	RetryDeliveries() error
+}
+
+type webhookUseCase struct {
+	webhookRepo repository.WebhookRepository
+	sender      *webhook.Sender
+}
+
+func NewWebhookUseCase(webhookRepo repository.WebhookRepository, sender *webhook.Sender) WebhookUseCase {
+	return &webhookUseCase{webhookRepo: webhookRepo, sender: sender}
+}
+
+func (wu *webhookUseCase) GetEndpoints() (*[]model.WebhookEndpoint, error) {
+	endpoints, err := wu.webhookRepo.GetEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	for i := range *endpoints {
+		(*endpoints)[i].Secret = ""
+	}
+	return endpoints, nil
+}
+
+// CreateEndpoint cadastra um webhook com um segredo gerado para assinar os envios. O segredo só é retornado aqui.
+func (wu *webhookUseCase) CreateEndpoint(endpoint model.WebhookEndpoint, adminId int) (*model.WebhookEndpoint, error) {
+	if err := validateWebhookEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, fmt.Errorf("error generating webhook secret: %v", err)
+	}
+	endpoint.Secret = secret
+	endpoint.IsActive = true
+
+	return wu.webhookRepo.CreateEndpoint(endpoint, adminId)
+}
+
+func (wu *webhookUseCase) UpdateEndpoint(endpoint model.WebhookEndpoint) error {
+	if err := validateWebhookEndpoint(endpoint); err != nil {
+		return err
+	}
+	return wu.webhookRepo.UpdateEndpoint(endpoint)
+}
+
+func (wu *webhookUseCase) DeleteEndpoint(id int) error {
+	return wu.webhookRepo.DeleteEndpoint(id)
+}
+
+func validateWebhookEndpoint(endpoint model.WebhookEndpoint) error {
+	if err := validateWebhookUrl(endpoint.Url); err != nil {
+		return err
+	}
+	if len(endpoint.Events) == 0 {
+		return fmt.Errorf("webhook endpoint must subscribe to at least one event")
+	}
+	for _, event := range endpoint.Events {
+		if !event.IsValid() {
+			return fmt.Errorf("invalid webhook event '%s'", event)
+		}
+	}
+	return nil
+}
+
+// validateWebhookUrl confere se a URL é absoluta e usa http ou https.
+func validateWebhookUrl(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("webhook url must be an absolute http or https url")
+	}
+	return nil
+}
+
+// Publish registra uma entrega do evento do outbox para cada webhook ativo inscrito nele e as envia em segundo
+// plano. Como o registro é único por evento e webhook, publicar o mesmo evento de novo não duplica as entregas.
+func (wu *webhookUseCase) Publish(event model.OutboxEvent) error {
+	endpoints, err := wu.webhookRepo.GetEndpointsByEvent(event.Event)
+	if err != nil {
+		return err
+	}
+	if len(*endpoints) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(event.Envelope())
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %v", err)
+	}
+
+	for _, endpoint := range *endpoints {
+		delivery, err := wu.webhookRepo.CreateDelivery(endpoint.Id, event, body, webhookLease)
+		if err != nil {
+			return err
+		}
+		if delivery == nil {
+			continue
+		}
+
+		go func(endpoint model.WebhookEndpoint, delivery model.WebhookDelivery) {
+			if err := wu.deliver(&endpoint, &delivery, maxWebhookAttempts); err != nil {
+				log.Printf("error delivering webhook %d: %v", delivery.Id, err)
+			}
+		}(endpoint, *delivery)
+	}
+	return nil
+}
+
+// deliver envia uma entrega registrada e grava o resultado. Só retorna erro se não conseguir gravar o resultado.
+func (wu *webhookUseCase) deliver(endpoint *model.WebhookEndpoint, delivery *model.WebhookDelivery, maxAttempts int) error {
+	if !endpoint.IsActive {
+		return wu.webhookRepo.MarkDeliveryFailed(delivery.Id, nil, "webhook endpoint is inactive", 0)
+	}
+
+	status, err := wu.sender.Send(webhook.Request{
+		Url:     endpoint.Url,
+		Secret:  endpoint.Secret,
+		EventId: delivery.EventId,
+		Event:   string(delivery.Event),
+		Body:    delivery.Payload,
+	})
+	if err != nil {
+		return wu.webhookRepo.MarkDeliveryFailed(delivery.Id, status, err.Error(), maxAttempts)
+	}
+	return wu.webhookRepo.MarkDeliveryDelivered(delivery.Id, status)
+}
+
+// RetryDeliveries reenvia as entregas pendentes cuja próxima tentativa já pode ser feita.
+func (wu *webhookUseCase) RetryDeliveries() error {
+	deliveries, err := wu.webhookRepo.ClaimDeliveriesToRetry(webhookLease)
+	if err != nil {
+		return err
+	}
+
+	endpoints := make(map[int]*model.WebhookEndpoint)
+	for _, delivery := range *deliveries {
+		endpoint, ok := endpoints[delivery.EndpointId]
+		if !ok {
+			if endpoint, err = wu.webhookRepo.GetEndpointById(delivery.EndpointId); err != nil {
+				return err
+			}
+			endpoints[delivery.EndpointId] = endpoint
+		}
+
+		if err := wu.deliver(endpoint, &delivery, maxWebhookAttempts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wu *webhookUseCase) GetDeliveries(endpointId int, status model.WebhookDeliveryStatus, event model.WebhookEvent) (*[]model.WebhookDelivery, error) {
+	switch status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryFailed:
+	default:
+		return nil, fmt.Errorf("invalid delivery status '%s'", status)
+	}
+	if event != "" && !event.IsValid() {
+		return nil, fmt.Errorf("invalid webhook event '%s'", event)
+	}
+	return wu.webhookRepo.GetDeliveries(endpointId, status, event)
+}
+
+// ReplayDelivery reenvia imediatamente uma entrega que falhou, com o mesmo conteúdo e id de evento, e retorna o seu
+// novo estado. Se falhar de novo, a entrega continua como falha, sem novos reenvios automáticos.
+func (wu *webhookUseCase) ReplayDelivery(id int) (*model.WebhookDelivery, error) {
+	delivery, err := wu.webhookRepo.GetDeliveryById(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status != model.WebhookDeliveryFailed {
+		return nil, fmt.Errorf("only failed deliveries can be replayed")
+	}
+
+	endpoint, err := wu.webhookRepo.GetEndpointById(delivery.EndpointId)
+	if err != nil {
+		return nil, err
+	}
+	if err := wu.deliver(endpoint, delivery, 0); err != nil {
+		return nil, err
+	}
+	return wu.webhookRepo.GetDeliveryById(id)
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// holidaysBR lista os feriados nacionais brasileiros, incluindo os pontos facultativos de Carnaval e Corpus Christi.
+// Novos anos devem ser acrescentados ao arquivo.
+//
+//go:embed holidays_br.json
+var holidaysBR []byte
+
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// BrazilianNationalHolidays retorna os feriados nacionais do ano informado, ou de todos os anos disponíveis quando o
+// ano é 0.
+func BrazilianNationalHolidays(year int) ([]Holiday, error) {
+	var entries []struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(holidaysBR, &entries); err != nil {
+		return nil, fmt.Errorf("error reading holidays file: %v", err)
+	}
+
+	holidays := make([]Holiday, 0, len(entries))
+	for _, entry := range entries {
+		date, err := time.Parse(time.DateOnly, entry.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday date '%s': %v", entry.Date, err)
+		}
+		if year != 0 && date.Year() != year {
+			continue
+		}
+		holidays = append(holidays, Holiday{Date: date, Name: entry.Name})
+	}
+	return holidays, nil
+}
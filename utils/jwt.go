@@ -1,18 +1,27 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
 	"go-api/initializers"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateJWT cria um novo token JWT utilizando o Id do usuário
-// e sua Role e retorna o token gerado ou um erro.
-func GenerateJWT(userId int, role string) (string, error) {
+// AccessTokenTTL é o tempo de validade dos tokens de acesso. Sessões mais longas são mantidas com o token de
+// atualização.
+const AccessTokenTTL = 15 * time.Minute
+
+// GenerateJWT cria um novo token JWT utilizando o Id do usuário, sua Role
+// e o Id da sessão e retorna o token gerado ou um erro.
+func GenerateJWT(userId int, role string, sessionId int) (string, error) {
 	claims := &jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        fmt.Sprint(sessionId),
 		Issuer:    fmt.Sprint(userId),
 		Subject:   role, // Armazena a role na claim subject
 	}
@@ -31,3 +40,47 @@ func ValidateJWT(tokenStr string) (*jwt.RegisteredClaims, error) {
 		return nil, err
 	}
 }
+
+// Finalidades dos tokens de uso único enviados por email.
+const (
+	PurposeEmailVerification = "email_verification"
+	PurposeLoginTwoFactor    = "login_2fa"
+)
+
+// purposeKey deriva uma chave de assinatura a partir de JwtKey para cada finalidade, de modo que um token de
+// verificação nunca seja aceito como token de acesso (e vice-versa).
+func purposeKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, initializers.JwtKey)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// GeneratePurposeJWT cria um token assinado para uma finalidade específica, vinculado ao usuário e ao seu email.
+func GeneratePurposeJWT(purpose string, userId int, email string, ttl time.Duration) (string, error) {
+	claims := &jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Issuer:    fmt.Sprint(userId),
+		Subject:   email,
+		Audience:  jwt.ClaimStrings{purpose},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(purposeKey(purpose))
+}
+
+// ValidatePurposeJWT valida um token gerado por GeneratePurposeJWT e retorna o Id e o email do usuário.
+func ValidatePurposeJWT(purpose, tokenStr string) (int, string, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return purposeKey(purpose), nil
+	}, jwt.WithAudience(purpose), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return 0, "", err
+	}
+
+	userId, err := strconv.Atoi(claims.Issuer)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid token issuer")
+	}
+	return userId, claims.Subject, nil
+}
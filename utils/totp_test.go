@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret é a chave SHA1 dos vetores de teste da RFC 6238 ("12345678901234567890") em base32.
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// Os vetores da RFC têm 8 dígitos; com 6 dígitos o código são os seus 6 últimos
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	for _, tt := range tests {
+		if got := totpCode(key, tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("totpCode(T=%d) = %q, want %q", tt.unix, got, tt.want)
+		}
+		step, ok := ValidateTOTP(rfc6238Secret, tt.want, time.Unix(tt.unix, 0))
+		if !ok || step != tt.unix/totpPeriod {
+			t.Errorf("ValidateTOTP(T=%d) = (%d, %v), want (%d, true)", tt.unix, step, ok, tt.unix/totpPeriod)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+	key, _ := totpEncoding.DecodeString(rfc6238Secret)
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOk   bool
+	}{
+		{"current step", rfc6238Secret, totpCode(key, current), current, true},
+		{"previous step within skew", rfc6238Secret, totpCode(key, current-1), current - 1, true},
+		{"next step within skew", rfc6238Secret, totpCode(key, current+1), current + 1, true},
+		{"two steps behind", rfc6238Secret, totpCode(key, current-2), 0, false},
+		{"two steps ahead", rfc6238Secret, totpCode(key, current+2), 0, false},
+		{"lowercase secret", strings.ToLower(rfc6238Secret), totpCode(key, current), current, true},
+		{"wrong code", rfc6238Secret, "000000", 0, false},
+		{"short code", rfc6238Secret, totpCode(key, current)[:5], 0, false},
+		{"invalid secret", "not base32!", totpCode(key, current), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, now)
+			if step != tt.wantStep || ok != tt.wantOk {
+				t.Errorf("ValidateTOTP() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+}
+
+// TestValidateTOTPStepReplay confere que um código reenviado enquanto ainda está na janela de tolerância volta com o
+// mesmo step, para que o repositório o recuse (last_used_step >= step), enquanto o código seguinte tem step maior.
+func TestValidateTOTPStepReplay(t *testing.T) {
+	key, _ := totpEncoding.DecodeString(rfc6238Secret)
+	first := time.Unix(1234567890, 0)
+	code := totpCode(key, first.Unix()/totpPeriod)
+
+	usedStep, ok := ValidateTOTP(rfc6238Secret, code, first)
+	if !ok {
+		t.Fatalf("ValidateTOTP() rejected a current code")
+	}
+
+	tests := []struct {
+		name    string
+		code    string
+		at      time.Time
+		wantNew bool
+	}{
+		{"same code, same step", code, first.Add(5 * time.Second), false},
+		{"same code, next step", code, first.Add(totpPeriod * time.Second), false},
+		{"next code", totpCode(key, usedStep+1), first.Add(totpPeriod * time.Second), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, tt.code, tt.at)
+			if !ok {
+				t.Fatalf("ValidateTOTP() rejected a code within the skew window")
+			}
+			if isNew := step > usedStep; isNew != tt.wantNew {
+				t.Errorf("step %d after used step %d: accepted = %v, want %v", step, usedStep, isNew, tt.wantNew)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"net/mail"
 	"regexp"
 	"strconv"
 	"strings"
@@ -51,3 +52,28 @@ func validateDigit(cpf string, position int) bool {
 	actualDigit, _ := strconv.Atoi(string(cpf[position]))
 	return actualDigit == expectedDigit
 }
+
+// IsValidEmail validates a plain email address (without display name).
+func IsValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+// phoneFormat matches Brazilian phone numbers such as "(48)98484-6666", "48 3333-4444" or "+55 48 98484-6666".
+var phoneFormat = regexp.MustCompile(`^(\+55\s?)?(\(\d{2}\)|\d{2})\s?\d{4,5}[-\s]?\d{4}$`)
+
+// IsValidPhone validates a Brazilian landline or mobile phone number with area code.
+func IsValidPhone(phone string) bool {
+	if !phoneFormat.MatchString(phone) {
+		return false
+	}
+
+	// Keep only the area code and the subscriber number
+	digits := regexp.MustCompile(`\D`).ReplaceAllString(strings.TrimPrefix(phone, "+55"), "")
+	if digits[0] == '0' || digits[1] == '0' {
+		return false
+	}
+
+	// Mobile numbers have 9 digits and always start with 9
+	return len(digits) == 10 || digits[2] == '9'
+}
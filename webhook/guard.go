@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// sharedAddressSpace é a faixa 100.64.0.0/10 (RFC 6598), usada por NAT de operadoras e por redes internas de nuvem.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddr informa se o endereço pode ser alcançado pelos envios para URLs informadas pelos leitores. Endereços
+// de loopback, privados, link-local (incluindo o serviço de metadados das nuvens, 169.254.169.254), multicast e não
+// especificados são recusados.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// CheckPublicHost resolve o host e falha se algum dos seus endereços não for público.
+func CheckPublicHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("could not resolve host '%s'", host)
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return fmt.Errorf("host '%s' resolves to a non-public address", host)
+		}
+	}
+	return nil
+}
+
+// publicOnlyControl recusa a conexão quando o endereço já resolvido não é público. Por rodar no dialer, a checagem
+// vale para o endereço efetivamente conectado, inclusive após redirecionamentos ou uma troca de DNS (DNS rebinding).
+func publicOnlyControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %v", address, err)
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("connection to non-public address %s is not allowed", addrPort.Addr())
+	}
+	return nil
+}
+
+// NewPublicClient cria um cliente HTTP que só se conecta a endereços públicos, para os envios a URLs informadas pelos
+// leitores. O proxy do ambiente é ignorado, já que a checagem seria feita no endereço do proxy e não no do destino.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: publicOnlyControl}
+	transport := &http.Transport{
+		Proxy:               nil,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers enviados em cada entrega. A assinatura é o HMAC-SHA256, em hexadecimal, de "<timestamp>.<corpo>" com o
+// segredo do webhook, prefixado por "sha256=".
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventId   = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign calcula a assinatura de um corpo enviado no timestamp informado, em segundos desde a época Unix.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Request é uma entrega a ser enviada a um webhook.
+type Request struct {
+	Url     string
+	Secret  string
+	EventId string
+	Event   string
+	Body    []byte
+}
+
+// Sender envia entregas assinadas por POST.
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{client: &http.Client{Timeout: timeout}}
+}
+
+// Send envia a entrega e retorna o status HTTP da resposta, quando houver. Respostas fora da faixa 2xx são erros.
+func (s *Sender) Send(r Request) (*int, error) {
+	req, err := http.NewRequest(http.MethodPost, r.Url, bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook request: %v", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, r.Event)
+	req.Header.Set(HeaderEventId, r.EventId)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(r.Secret, timestamp, r.Body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending webhook to %s: %v", r.Url, err)
+	}
+	defer resp.Body.Close()
+	// Lê um trecho da resposta para que a conexão possa ser reaproveitada
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	status := resp.StatusCode
+	if status < 200 || status >= 300 {
+		return &status, fmt.Errorf("webhook %s responded with status %d", r.Url, status)
+	}
+	return &status, nil
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"loan.created","id":42}`)
+
+	// Assinaturas calculadas de forma independente: HMAC-SHA256("<timestamp>.<corpo>")
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		want      string
+	}{
+		{"known signature", "whsec_test", 1700000000, body,
+			"sha256=52802f0747b23775ed2b43c3d158905388f8cd178020786cfd71f677ca182053"},
+		{"timestamp is signed", "whsec_test", 1700000001, body,
+			"sha256=7e663b754d83f14fc0841690eace9e817e83b87c4fd24be537376b9f6d20f5d8"},
+		{"secret is the key", "other-secret", 1700000000, body,
+			"sha256=fb7cac7827dc79f74798371bd6567114bff3d96fc5f59cee04c54c5b3af5faff"},
+		{"empty body", "whsec_test", 1700000000, nil,
+			"sha256=5967f3c560522fa40cf2876ebc3c3a08551dd6959aaade3b413460591895bdcc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("Sign() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSenderSend(t *testing.T) {
+	body := []byte(`{"event":"loan.created","id":42}`)
+
+	tests := []struct {
+		name       string
+		status     int
+		wantErr    bool
+		wantStatus int
+	}{
+		{"accepted", http.StatusNoContent, false, http.StatusNoContent},
+		{"rejected", http.StatusInternalServerError, true, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *http.Request
+			var receivedBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				receivedBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			status, err := NewSender(5 * time.Second).Send(Request{
+				Url: server.URL, Secret: "whsec_test", EventId: "evt-1", Event: "loan.created", Body: body,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status == nil || *status != tt.wantStatus {
+				t.Fatalf("Send() status = %v, want %d", status, tt.wantStatus)
+			}
+
+			if string(receivedBody) != string(body) {
+				t.Errorf("body = %q, want %q", receivedBody, body)
+			}
+			if got := received.Header.Get(HeaderEvent); got != "loan.created" {
+				t.Errorf("%s = %q, want %q", HeaderEvent, got, "loan.created")
+			}
+			if got := received.Header.Get(HeaderEventId); got != "evt-1" {
+				t.Errorf("%s = %q, want %q", HeaderEventId, got, "evt-1")
+			}
+			// A assinatura precisa conferir com o timestamp enviado no header
+			timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+			if err != nil {
+				t.Fatalf("invalid %s: %v", HeaderTimestamp, err)
+			}
+			if got, want := received.Header.Get(HeaderSignature), Sign("whsec_test", timestamp, body); got != want {
+				t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+			}
+		})
+	}
+}